@@ -0,0 +1,38 @@
+// Command fakeoidc runs the oidctest issuer so Google sign in can be used
+// locally. Point GOOGLE_ISSUER, GOOGLE_TOKEN_URL and GOOGLE_JWKS_URL at it and
+// open /authorize with login_hint set to the email to sign in.
+package main
+
+import (
+	"flag"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc/oidctest"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	zerolog.CallerMarshalFunc = func(_ uintptr, file string, line int) string {
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	logger := log.With().Caller().Logger()
+
+	addr := flag.String("addr", "localhost:8081", "address to listen on")
+	clientID := flag.String("client-id", "fake-client-id", "accepted OAuth client id")
+	clientSecret := flag.String("client-secret", "fake-client-secret", "accepted OAuth client secret")
+	flag.Parse()
+
+	issuer, err := oidctest.NewIssuer("http://"+*addr, *clientID, *clientSecret)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	logger.Info().Str("issuer", issuer.URL).Msg("fake OpenID provider listening")
+	if err := http.ListenAndServe(*addr, issuer.Handler()); err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+}
@@ -0,0 +1,43 @@
+// Command jwtkey generates a private key for the key ring of JWT_KEYS_FILE
+// and prints it as a PKCS #8 PEM block.
+//
+// To rotate, generate a key and add it to the key file with an active_from at
+// least an hour ahead, so verifiers fetch it before it signs. Set expires_at of
+// the current key to at least 30 days after that, the lifetime of refresh
+// tokens, and remove it from the file once it expired.
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mdayat/demi-masa-backend-service/internal/jwtkeys"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	zerolog.CallerMarshalFunc = func(_ uintptr, file string, line int) string {
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	logger := log.With().Caller().Logger()
+
+	alg := flag.String("alg", "EdDSA", "signing algorithm, EdDSA or RS256")
+	flag.Parse()
+
+	privateKey, err := jwtkeys.GenerateKey(*alg)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	privateKeyPEM, err := jwtkeys.MarshalPrivateKey(privateKey)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	if _, err := os.Stdout.Write(privateKeyPEM); err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+}
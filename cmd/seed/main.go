@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/alexedwards/argon2id"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -38,18 +39,29 @@ func main() {
 		logger.Fatal().Err(err).Send()
 	}
 
+	keyRing, err := configs.NewKeyRing(env)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
 	config := configs.Configs{
-		Env: env,
-		Db:  db,
+		Env:     env,
+		Db:      db,
+		KeyRing: keyRing,
 	}
 
 	// Seed "user" table
+	hashedPassword, err := argon2id.CreateHash("example", argon2id.DefaultParams)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to hash seeded user password")
+	}
+
 	user, err := retryutil.RetryWithData(func() (repository.User, error) {
 		return db.Queries.InsertUser(ctx, repository.InsertUserParams{
 			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
 			Email:       "example@gmail.com",
 			Name:        "example",
-			Password:    "example",
+			Password:    hashedPassword,
 			Coordinates: pgtype.Point{P: pgtype.Vec2{X: 106.865036, Y: -6.175110}, Valid: true},
 			City:        "Jakarta",
 			Timezone:    "Asia/Jakarta",
@@ -60,6 +72,29 @@ func main() {
 		logger.Fatal().Err(err).Msg("failed to seed user table")
 	}
 
+	user, err = retryutil.RetryWithData(func() (repository.User, error) {
+		return db.Queries.VerifyUserEmail(ctx, repository.VerifyUserEmailParams{
+			ID:    user.ID,
+			Email: user.Email,
+		})
+	})
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to verify seeded user email")
+	}
+
+	// The seeded user is an admin, so the admin endpoints can be tested.
+	user, err = retryutil.RetryWithData(func() (repository.User, error) {
+		return db.Queries.UpdateUserRole(ctx, repository.UpdateUserRoleParams{
+			ID:   user.ID,
+			Role: services.RoleAdmin,
+		})
+	})
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to make seeded user an admin")
+	}
+
 	// Seed "refresh_token" table
 	authService := services.NewAuthService(config)
 	now := time.Now()
@@ -81,10 +116,14 @@ func main() {
 			return repository.RefreshToken{}, fmt.Errorf("failed to create refresh token: %w", err)
 		}
 
+		refreshTokenUUID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
 		return db.Queries.InsertUserRefreshToken(ctx, repository.InsertUserRefreshTokenParams{
-			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
-			UserID:    user.ID,
-			ExpiresAt: pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
+			ID:         refreshTokenUUID,
+			UserID:     user.ID,
+			ExpiresAt:  pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
+			FamilyID:   refreshTokenUUID,
+			CreatedAt:  pgtype.Timestamptz{Time: now, Valid: true},
+			LastUsedAt: pgtype.Timestamptz{Time: now, Valid: true},
 		})
 	})
 
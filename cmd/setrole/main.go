@@ -0,0 +1,72 @@
+// Command setrole changes the role of a user by email. It is how the first
+// admin is made, later admins can change roles through the API.
+package main
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	zerolog.CallerMarshalFunc = func(_ uintptr, file string, line int) string {
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	logger := log.With().Caller().Logger()
+
+	email := flag.String("email", "", "email of the user")
+	role := flag.String("role", services.RoleAdmin, "role of the user, one of user, admin, support or influencer")
+	flag.Parse()
+
+	roles := []string{services.RoleUser, services.RoleAdmin, services.RoleSupport, services.RoleInfluencer}
+	if *email == "" || !slices.Contains(roles, *role) {
+		flag.Usage()
+		return
+	}
+
+	env, err := configs.LoadEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	db, err := configs.NewDb(ctx, env.DatabaseURL)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+	defer db.Conn.Close()
+
+	user, err := retryutil.RetryWithData(func() (repository.User, error) {
+		return db.Queries.SelectUserByEmail(ctx, *email)
+	})
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to select user by email")
+	}
+
+	user, err = retryutil.RetryWithData(func() (repository.User, error) {
+		return db.Queries.UpdateUserRole(ctx, repository.UpdateUserRoleParams{
+			ID:   user.ID,
+			Role: *role,
+		})
+	})
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to update user role")
+	}
+
+	logger.Info().Str("user_id", user.ID.String()).Str("role", user.Role).Msg("successfully updated user role")
+}
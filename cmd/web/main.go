@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/mdayat/demi-masa-backend-service/configs"
 	"github.com/mdayat/demi-masa-backend-service/internal/handlers"
@@ -31,13 +33,67 @@ func main() {
 		logger.Fatal().Err(err).Send()
 	}
 
-	configs := configs.NewConfigs(env, db)
+	keyRing, err := configs.NewKeyRing(env)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	configs := configs.NewConfigs(env, db, keyRing)
 	authService := services.NewAuthService(configs)
-	authenticator := handlers.NewProdAuthenticator(authService)
+	personalAccessTokenService := services.NewPersonalAccessTokenService(configs)
+	authenticator := handlers.NewProdAuthenticator(authService, personalAccessTokenService)
 	customMiddleware := handlers.NewMiddlewareHandler(configs, authenticator)
 	router := handlers.NewRestHandler(configs, customMiddleware)
 
+	taskService := services.NewTaskService(configs)
+	go purgeTrashedTasks(ctx, taskService)
+	go purgeLoginThrottles(ctx, authService)
+
 	if err := http.ListenAndServe(":8080", router); err != nil {
 		logger.Fatal().Err(err).Send()
 	}
 }
+
+const purgeTrashedTasksInterval = time.Hour
+
+func purgeTrashedTasks(ctx context.Context, taskService services.TaskServicer) {
+	ticker := time.NewTicker(purgeTrashedTasksInterval)
+	defer ticker.Stop()
+
+	for {
+		purgedTasks, err := taskService.PurgeTrashedTasks(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Caller().Msg("failed to purge trashed tasks")
+		} else {
+			log.Info().Int64("purged_tasks", purgedTasks).Msg("successfully purged trashed tasks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+const purgeLoginThrottlesInterval = time.Hour
+
+func purgeLoginThrottles(ctx context.Context, authService services.AuthServicer) {
+	ticker := time.NewTicker(purgeLoginThrottlesInterval)
+	defer ticker.Stop()
+
+	for {
+		purgedThrottles, err := authService.PurgeLoginThrottles(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Caller().Msg("failed to purge login throttles")
+		} else {
+			log.Info().Int64("purged_throttles", purgedThrottles).Msg("successfully purged login throttles")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
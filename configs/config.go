@@ -1,17 +1,26 @@
 package configs
 
-import "github.com/go-playground/validator/v10"
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/mdayat/demi-masa-backend-service/internal/jwtkeys"
+	"github.com/mdayat/demi-masa-backend-service/internal/mailer"
+)
 
 type Configs struct {
 	Env      Env
 	Db       Db
 	Validate *validator.Validate
+	Mailer   mailer.Mailer
+	// KeyRing signs access and refresh tokens.
+	KeyRing *jwtkeys.KeyRing
 }
 
-func NewConfigs(env Env, db Db) Configs {
+func NewConfigs(env Env, db Db, keyRing *jwtkeys.KeyRing) Configs {
 	return Configs{
 		Env:      env,
 		Db:       db,
 		Validate: NewValidate(),
+		Mailer:   NewMailer(env),
+		KeyRing:  keyRing,
 	}
 }
@@ -1,22 +1,63 @@
 package configs
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
 type Env struct {
-	DatabaseURL        string
-	AllowedOrigins     string
+	DatabaseURL    string
+	AllowedOrigins string
+	// SecretKey signs the tokens that only this service reads, like email
+	// verification tokens, and access and refresh tokens without JWTKeysFile.
 	SecretKey          string
 	OriginURL          string
 	TripayMerchantCode string
 	TripayAPIKey       string
 	TripayPrivateKey   string
 	GeoapifyAPIKey     string
+	// WebAppURL is where links in mails point to.
+	WebAppURL    string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	// Google sign in is disabled without GoogleClientID. The endpoints default
+	// to Google's and can point to a local issuer instead.
+	GoogleClientID     string
+	GoogleClientSecret string
+	GoogleIssuer       string
+	GoogleTokenURL     string
+	GoogleJWKSURL      string
+	// TOTPEncryptionKey encrypts TOTP secrets at rest. It is 32 bytes encoded
+	// in base64, two-factor authentication can't be enabled without it.
+	TOTPEncryptionKey string
+	// JWTKeysFile is the JSON file of the keys that sign access and refresh
+	// tokens, see jwtkeys.Load.
+	JWTKeysFile string
+	// CookieDomain is the domain of the CSRF cookie of the refresh cookie
+	// mode, so a web app on another subdomain can read it. Empty means the
+	// cookie is only sent to this host.
+	CookieDomain string
+	// TaskTrashRetentionDays is how long trashed tasks are kept before they are
+	// purged permanently.
+	TaskTrashRetentionDays int
 }
 
+const (
+	defaultTaskTrashRetentionDays = 30
+	defaultSMTPPort               = 587
+	defaultGoogleIssuer           = "https://accounts.google.com"
+	defaultGoogleTokenURL         = "https://oauth2.googleapis.com/token"
+	defaultGoogleJWKSURL          = "https://www.googleapis.com/oauth2/v3/certs"
+)
+
 func LoadEnv(filenames ...string) (Env, error) {
 	if err := godotenv.Load(filenames...); err != nil {
 		return Env{}, err
@@ -31,7 +72,53 @@ func LoadEnv(filenames ...string) (Env, error) {
 		TripayAPIKey:       os.Getenv("TRIPAY_API_KEY"),
 		TripayPrivateKey:   os.Getenv("TRIPAY_PRIVATE_KEY"),
 		GeoapifyAPIKey:     os.Getenv("GEOAPIFY_API_KEY"),
+		WebAppURL:          os.Getenv("WEB_APP_URL"),
+		SMTPHost:           os.Getenv("SMTP_HOST"),
+		SMTPPort:           defaultSMTPPort,
+		SMTPUsername:       os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:       os.Getenv("SMTP_PASSWORD"),
+		MailFrom:           os.Getenv("MAIL_FROM"),
+		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+		GoogleIssuer:       getenvOrDefault("GOOGLE_ISSUER", defaultGoogleIssuer),
+		GoogleTokenURL:     getenvOrDefault("GOOGLE_TOKEN_URL", defaultGoogleTokenURL),
+		GoogleJWKSURL:      getenvOrDefault("GOOGLE_JWKS_URL", defaultGoogleJWKSURL),
+		TOTPEncryptionKey:  os.Getenv("TOTP_ENCRYPTION_KEY"),
+		JWTKeysFile:        os.Getenv("JWT_KEYS_FILE"),
+		CookieDomain:       os.Getenv("COOKIE_DOMAIN"),
+
+		TaskTrashRetentionDays: defaultTaskTrashRetentionDays,
+	}
+
+	if retention := os.Getenv("TASK_TRASH_RETENTION_DAYS"); retention != "" {
+		retentionDays, err := strconv.Atoi(retention)
+		if err != nil || retentionDays < 1 {
+			return Env{}, fmt.Errorf("invalid TASK_TRASH_RETENTION_DAYS: %s", retention)
+		}
+		env.TaskTrashRetentionDays = retentionDays
+	}
+
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		smtpPort, err := strconv.Atoi(port)
+		if err != nil || smtpPort < 1 {
+			return Env{}, fmt.Errorf("invalid SMTP_PORT: %s", port)
+		}
+		env.SMTPPort = smtpPort
+	}
+
+	if env.TOTPEncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(env.TOTPEncryptionKey)
+		if err != nil || len(key) != 32 {
+			return Env{}, errors.New("invalid TOTP_ENCRYPTION_KEY: must be 32 bytes encoded in base64")
+		}
 	}
 
 	return env, nil
 }
+
+func getenvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
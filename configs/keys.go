@@ -0,0 +1,13 @@
+package configs
+
+import "github.com/mdayat/demi-masa-backend-service/internal/jwtkeys"
+
+// NewKeyRing loads the keys of JWT_KEYS_FILE. Without it, tokens are signed
+// with SECRET_KEY.
+func NewKeyRing(env Env) (*jwtkeys.KeyRing, error) {
+	if env.JWTKeysFile == "" {
+		return jwtkeys.NewKeyRing([]byte(env.SecretKey))
+	}
+
+	return jwtkeys.Load(env.JWTKeysFile, []byte(env.SecretKey))
+}
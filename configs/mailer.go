@@ -0,0 +1,19 @@
+package configs
+
+import "github.com/mdayat/demi-masa-backend-service/internal/mailer"
+
+// NewMailer sends mails over SMTP when SMTP_HOST is set and logs them
+// otherwise.
+func NewMailer(env Env) mailer.Mailer {
+	if env.SMTPHost == "" {
+		return mailer.NewLogMailer()
+	}
+
+	return mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host:     env.SMTPHost,
+		Port:     env.SMTPPort,
+		Username: env.SMTPUsername,
+		Password: env.SMTPPassword,
+		From:     env.MailFrom,
+	})
+}
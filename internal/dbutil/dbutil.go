@@ -45,9 +45,9 @@ func RetryableTxWithoutData(
 	queries *repository.Queries,
 	f func(qtx *repository.Queries) error,
 ) error {
-	retryableFunc := func() error {
+	retryableFunc := func() (err error) {
 		var tx pgx.Tx
-		tx, err := conn.Begin(ctx)
+		tx, err = conn.Begin(ctx)
 		if err != nil {
 			return err
 		}
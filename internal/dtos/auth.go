@@ -1,23 +1,69 @@
 package dtos
 
 type RegisterRequest struct {
-	Username string `json:"username" validate:"omitempty,min=2"`
-	Email    string `json:"email" validate:"omitempty,email"`
-	Password string `json:"password" validate:"omitempty,min=8"`
+	Username   string `json:"username" validate:"omitempty,min=2"`
+	Email      string `json:"email" validate:"omitempty,email"`
+	Password   string `json:"password" validate:"omitempty,min=8"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=255"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"omitempty,email"`
-	Password string `json:"password" validate:"omitempty,min=8"`
+	Email      string `json:"email" validate:"omitempty,email"`
+	Password   string `json:"password" validate:"omitempty,min=8"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=255"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// GoogleLoginRequest carries the authorization code Google redirected back
+// with. CodeVerifier is required when the code was requested with PKCE.
+type GoogleLoginRequest struct {
+	Code         string `json:"code" validate:"required"`
+	RedirectURI  string `json:"redirect_uri" validate:"required,url"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	DeviceName   string `json:"device_name" validate:"omitempty,max=255"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// AuthResponse carries either the refresh token, or in the refresh cookie mode
+// the CSRF token that goes with the refresh cookie.
 type AuthResponse struct {
-	RefreshToken string       `json:"refresh_token"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	CSRFToken    string       `json:"csrf_token,omitempty"`
 	AccessToken  string       `json:"access_token"`
 	User         UserResponse `json:"user"`
 }
 
 type RefreshResponse struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	CSRFToken    string `json:"csrf_token,omitempty"`
 	AccessToken  string `json:"access_token"`
 }
+
+type SessionResponse struct {
+	Id         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IpAddress  string `json:"ip_address"`
+	Current    bool   `json:"current"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+// ErrorResponse is sent when the status code alone doesn't tell the client
+// what to do.
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
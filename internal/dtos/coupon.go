@@ -6,3 +6,14 @@ type CouponResponse struct {
 	Quota              int16  `json:"quota"`
 	CreatedAt          string `json:"created_at"`
 }
+
+type CouponRequest struct {
+	Code               string `json:"code" validate:"required,max=255"`
+	InfluencerUsername string `json:"influencer_username" validate:"required,max=255"`
+	Quota              int16  `json:"quota" validate:"gte=0"`
+}
+
+type UpdateCouponRequest struct {
+	InfluencerUsername string `json:"influencer_username" validate:"omitempty,max=255"`
+	Quota              *int16 `json:"quota" validate:"omitempty,gte=0"`
+}
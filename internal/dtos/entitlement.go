@@ -0,0 +1,8 @@
+package dtos
+
+type EntitlementErrorResponse struct {
+	Error       string `json:"error"`
+	Entitlement string `json:"entitlement"`
+	Limit       int64  `json:"limit"`
+	Message     string `json:"message"`
+}
@@ -0,0 +1,46 @@
+package dtos
+
+type StartFocusSessionRequest struct {
+	TaskId                   string `json:"task_id" validate:"omitempty,uuid"`
+	PlannedDurationInMinutes int16  `json:"planned_duration_in_minutes" validate:"required,gte=1,lte=240"`
+}
+
+type FocusPrayerWarning struct {
+	Prayer string `json:"prayer"`
+	Time   string `json:"time"`
+}
+
+type FocusSessionResponse struct {
+	Id                       string              `json:"id"`
+	TaskId                   string              `json:"task_id"`
+	PlannedDurationInMinutes int16               `json:"planned_duration_in_minutes"`
+	Status                   string              `json:"status"`
+	FocusedSeconds           int64               `json:"focused_seconds"`
+	PausedForPrayer          string              `json:"paused_for_prayer"`
+	StartedAt                string              `json:"started_at"`
+	EndedAt                  string              `json:"ended_at"`
+	UpcomingPrayer           *FocusPrayerWarning `json:"upcoming_prayer"`
+}
+
+type FocusTotalsResponse struct {
+	Date          string `json:"date"`
+	DailySeconds  int64  `json:"daily_seconds"`
+	WeekStartsOn  string `json:"week_starts_on"`
+	WeeklySeconds int64  `json:"weekly_seconds"`
+}
+
+type FocusPreferenceResponse struct {
+	DayStartsAt                  string `json:"day_starts_at"`
+	DayEndsAt                    string `json:"day_ends_at"`
+	PrayerDurationInMinutes      int16  `json:"prayer_duration_in_minutes"`
+	BreakInMinutes               int16  `json:"break_in_minutes"`
+	DefaultTaskDurationInMinutes int16  `json:"default_task_duration_in_minutes"`
+}
+
+type UpdateFocusPreferenceRequest struct {
+	DayStartsAt                  string `json:"day_starts_at" validate:"omitempty,datetime=15:04"`
+	DayEndsAt                    string `json:"day_ends_at" validate:"omitempty,datetime=15:04"`
+	PrayerDurationInMinutes      *int16 `json:"prayer_duration_in_minutes" validate:"omitempty,gte=0,lte=120"`
+	BreakInMinutes               *int16 `json:"break_in_minutes" validate:"omitempty,gte=0,lte=120"`
+	DefaultTaskDurationInMinutes *int16 `json:"default_task_duration_in_minutes" validate:"omitempty,gte=5,lte=480"`
+}
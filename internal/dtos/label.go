@@ -0,0 +1,18 @@
+package dtos
+
+type CreateLabelRequest struct {
+	Name  string `json:"name" validate:"required,max=64"`
+	Color string `json:"color" validate:"required,hexcolor,len=7"`
+}
+
+type UpdateLabelRequest struct {
+	Name  string `json:"name" validate:"max=64"`
+	Color string `json:"color" validate:"omitempty,hexcolor,len=7"`
+}
+
+type LabelResponse struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package dtos
+
+type PersonalAccessTokenRequest struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=user:read prayers:read prayers:write tasks:read tasks:write lists:read lists:write labels:read labels:write focus:read focus:write planner:read"`
+	// ExpiresInDays is 0 for tokens that don't expire.
+	ExpiresInDays int `json:"expires_in_days" validate:"gte=0,lte=365"`
+}
+
+type PersonalAccessTokenResponse struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	TokenPrefix string   `json:"token_prefix"`
+	Scopes      []string `json:"scopes"`
+	ExpiresAt   string   `json:"expires_at"`
+	LastUsedAt  string   `json:"last_used_at"`
+	CreatedAt   string   `json:"created_at"`
+	// Token is only sent when the token is created.
+	Token string `json:"token,omitempty"`
+}
@@ -8,3 +8,18 @@ type PlanResponse struct {
 	DurationInMonths int16  `json:"duration_in_months"`
 	CreatedAt        string `json:"created_at"`
 }
+
+type PlanRequest struct {
+	Type             string `json:"type" validate:"required,oneof=premium"`
+	Name             string `json:"name" validate:"required,max=255"`
+	Price            int32  `json:"price" validate:"gte=0"`
+	DurationInMonths int16  `json:"duration_in_months" validate:"required,gte=1"`
+}
+
+// UpdatePlanRequest changes the plan for new invoices only, invoices already
+// issued keep their amount.
+type UpdatePlanRequest struct {
+	Name             string `json:"name" validate:"omitempty,max=255"`
+	Price            *int32 `json:"price" validate:"omitempty,gte=0"`
+	DurationInMonths *int16 `json:"duration_in_months" validate:"omitempty,gte=1"`
+}
@@ -0,0 +1,28 @@
+package dtos
+
+type PlannedPrayerResponse struct {
+	Name     string `json:"name"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+}
+
+type PlannedTaskResponse struct {
+	StartsAt string       `json:"starts_at"`
+	EndsAt   string       `json:"ends_at"`
+	Task     TaskResponse `json:"task"`
+}
+
+type UnscheduledTaskResponse struct {
+	DurationInMinutes int16        `json:"duration_in_minutes"`
+	Reason            string       `json:"reason"`
+	Task              TaskResponse `json:"task"`
+}
+
+type PlannerResponse struct {
+	Date        string                    `json:"date"`
+	DayStartsAt string                    `json:"day_starts_at"`
+	DayEndsAt   string                    `json:"day_ends_at"`
+	Prayers     []PlannedPrayerResponse   `json:"prayers"`
+	Tasks       []PlannedTaskResponse     `json:"tasks"`
+	Unscheduled []UnscheduledTaskResponse `json:"unscheduled"`
+}
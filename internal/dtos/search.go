@@ -0,0 +1,9 @@
+package dtos
+
+type SearchResultResponse struct {
+	Type    string  `json:"type"`
+	Id      string  `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Rank    float32 `json:"rank"`
+}
@@ -1,19 +1,142 @@
 package dtos
 
+type TaskAnchor struct {
+	Prayer          string `json:"prayer" validate:"required,oneof=subuh zuhur asar magrib isya"`
+	Relation        string `json:"relation" validate:"required,oneof=before after between"`
+	OffsetInMinutes int16  `json:"offset_in_minutes" validate:"gte=0,lte=720,excluded_if=Relation between"`
+}
+
+type TaskRecurrence struct {
+	Rule     string `json:"rule" validate:"required,max=255"`
+	StartsOn string `json:"starts_on" validate:"required,datetime=2006-01-02"`
+}
+
 type CreateTaskRequest struct {
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	Name        string          `json:"name" validate:"required"`
+	Description string          `json:"description" validate:"required"`
+	Anchor      *TaskAnchor     `json:"anchor"`
+	Recurrence  *TaskRecurrence `json:"recurrence"`
+	DueAt       string          `json:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Priority    int16           `json:"priority" validate:"gte=0,lte=3"`
+	// EstimatedDurationInMinutes is used by the planner, 0 means no estimate.
+	EstimatedDurationInMinutes int16 `json:"estimated_duration_in_minutes" validate:"omitempty,gte=1,lte=720"`
 }
 
 type UpdateTaskRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Checked     *bool  `json:"checked"`
+	Name                       string          `json:"name"`
+	Description                string          `json:"description"`
+	Checked                    *bool           `json:"checked"`
+	Anchor                     *TaskAnchor     `json:"anchor" validate:"excluded_if=RemoveAnchor true"`
+	RemoveAnchor               bool            `json:"remove_anchor"`
+	Recurrence                 *TaskRecurrence `json:"recurrence" validate:"excluded_if=RemoveRecurrence true"`
+	RemoveRecurrence           bool            `json:"remove_recurrence"`
+	DueAt                      string          `json:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00,excluded_if=RemoveDueAt true"`
+	RemoveDueAt                bool            `json:"remove_due_at"`
+	Priority                   *int16          `json:"priority" validate:"omitempty,gte=0,lte=3"`
+	EstimatedDurationInMinutes *int16          `json:"estimated_duration_in_minutes" validate:"omitempty,gte=1,lte=720,excluded_if=RemoveEstimatedDuration true"`
+	RemoveEstimatedDuration    bool            `json:"remove_estimated_duration"`
+}
+
+type BulkTaskOperation struct {
+	Op     string             `json:"op" validate:"required,oneof=create update check uncheck delete"`
+	TaskId string             `json:"task_id" validate:"required_unless=Op create,excluded_if=Op create,omitempty,uuid"`
+	Create *CreateTaskRequest `json:"create" validate:"required_if=Op create,excluded_unless=Op create"`
+	Update *UpdateTaskRequest `json:"update" validate:"required_if=Op update,excluded_unless=Op update"`
+}
+
+type BulkTaskRequest struct {
+	Operations []BulkTaskOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+type BulkTaskResult struct {
+	Index  int           `json:"index"`
+	Op     string        `json:"op"`
+	Status string        `json:"status"`
+	Error  string        `json:"error"`
+	Task   *TaskResponse `json:"task"`
+}
+
+type BulkTaskResponse struct {
+	Results []BulkTaskResult `json:"results"`
+}
+
+type MoveTaskRequest struct {
+	BeforeId string `json:"before_id" validate:"required_without=AfterId,excluded_with=AfterId"`
+	AfterId  string `json:"after_id"`
+}
+
+type TaskProgress struct {
+	Total   int64 `json:"total"`
+	Checked int64 `json:"checked"`
 }
 
 type TaskResponse struct {
-	Id          string `json:"id"`
+	Id                         string          `json:"id"`
+	Name                       string          `json:"name"`
+	Description                string          `json:"description"`
+	Checked                    bool            `json:"checked"`
+	Anchor                     *TaskAnchor     `json:"anchor"`
+	Recurrence                 *TaskRecurrence `json:"recurrence"`
+	ScheduledAt                string          `json:"scheduled_at"`
+	ScheduledUntil             string          `json:"scheduled_until"`
+	Progress                   TaskProgress    `json:"progress"`
+	DueAt                      string          `json:"due_at"`
+	Priority                   int16           `json:"priority"`
+	Labels                     []LabelResponse `json:"labels"`
+	Position                   int64           `json:"position"`
+	CreatedAt                  string          `json:"created_at"`
+	DeletedAt                  string          `json:"deleted_at"`
+	ListId                     string          `json:"list_id"`
+	AssigneeId                 string          `json:"assignee_id"`
+	CompletedBy                string          `json:"completed_by"`
+	EstimatedDurationInMinutes int16           `json:"estimated_duration_in_minutes"`
+	CompletedAt                string          `json:"completed_at"`
+}
+
+type TaskGroupResponse struct {
+	Prayer   string         `json:"prayer"`
+	StartsAt string         `json:"starts_at"`
+	EndsAt   string         `json:"ends_at"`
+	Tasks    []TaskResponse `json:"tasks"`
+}
+
+type UpdateTaskOccurrenceRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
-	Checked     bool   `json:"checked"`
+	Checked     *bool  `json:"checked"`
+	Skipped     *bool  `json:"skipped"`
+}
+
+type TaskOccurrenceResponse struct {
+	TaskId         string `json:"task_id"`
+	Date           string `json:"date"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Checked        bool   `json:"checked"`
+	Skipped        bool   `json:"skipped"`
+	ScheduledAt    string `json:"scheduled_at"`
+	ScheduledUntil string `json:"scheduled_until"`
+}
+
+type TaskStatsPeriod struct {
+	StartsOn  string `json:"starts_on"`
+	Completed int64  `json:"completed"`
+}
+
+type TaskStatsPrayerSlot struct {
+	Prayer    string `json:"prayer"`
+	Completed int64  `json:"completed"`
+}
+
+type TaskStatsResponse struct {
+	From                              string                `json:"from"`
+	To                                string                `json:"to"`
+	Completed                         int64                 `json:"completed"`
+	OnTime                            int64                 `json:"on_time"`
+	Overdue                           int64                 `json:"overdue"`
+	WithoutDueDate                    int64                 `json:"without_due_date"`
+	AverageCompletionLatencyInSeconds int64                 `json:"average_completion_latency_in_seconds"`
+	Days                              []TaskStatsPeriod     `json:"days"`
+	Weeks                             []TaskStatsPeriod     `json:"weeks"`
+	PrayerSlots                       []TaskStatsPrayerSlot `json:"prayer_slots"`
 }
@@ -0,0 +1,22 @@
+package dtos
+
+type CreateTaskItemRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type UpdateTaskItemRequest struct {
+	Name    string `json:"name" validate:"max=255"`
+	Checked *bool  `json:"checked"`
+}
+
+type ReorderTaskItemsRequest struct {
+	ItemIds []string `json:"item_ids" validate:"required,min=1,dive,uuid"`
+}
+
+type TaskItemResponse struct {
+	Id       string `json:"id"`
+	TaskId   string `json:"task_id"`
+	Name     string `json:"name"`
+	Checked  bool   `json:"checked"`
+	Position int32  `json:"position"`
+}
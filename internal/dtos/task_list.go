@@ -0,0 +1,43 @@
+package dtos
+
+type CreateTaskListRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type TaskListResponse struct {
+	Id        string `json:"id"`
+	OwnerId   string `json:"owner_id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+type InviteTaskListMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type TaskListMemberResponse struct {
+	UserId   string `json:"user_id"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+type CreateTaskListTaskRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description" validate:"required"`
+	AssigneeId  string `json:"assignee_id" validate:"omitempty,uuid"`
+	DueAt       string `json:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Priority    int16  `json:"priority" validate:"gte=0,lte=3"`
+}
+
+type UpdateTaskListTaskRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Checked        *bool  `json:"checked"`
+	AssigneeId     string `json:"assignee_id" validate:"omitempty,uuid,excluded_if=RemoveAssignee true"`
+	RemoveAssignee bool   `json:"remove_assignee"`
+	DueAt          string `json:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00,excluded_if=RemoveDueAt true"`
+	RemoveDueAt    bool   `json:"remove_due_at"`
+	Priority       *int16 `json:"priority" validate:"omitempty,gte=0,lte=3"`
+}
@@ -1,13 +1,20 @@
 package dtos
 
 type UserRequest struct {
-	Email     string `json:"email" validate:"omitempty,email"`
-	Password  string `json:"password" validate:"omitempty,min=8"`
+	Email string `json:"email" validate:"omitempty,email"`
+	// Password is rejected, passwords are changed through ChangePasswordRequest
+	// so they get hashed and checked against the current one.
+	Password  string `json:"password" validate:"isdefault"`
 	Username  string `json:"username" validate:"omitempty,min=2"`
 	Latitude  string `json:"latitude" validate:"omitempty,latitude"`
 	Longitude string `json:"longitude" validate:"omitempty,longitude"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,nefield=CurrentPassword"`
+}
+
 type UserSubscription struct {
 	Id        string `json:"id"`
 	PlanId    string `json:"plan_id"`
@@ -17,13 +24,19 @@ type UserSubscription struct {
 }
 
 type UserResponse struct {
-	Id           string            `json:"id"`
-	Email        string            `json:"email"`
-	Name         string            `json:"name"`
-	Latitude     float64           `json:"latitude"`
-	Longitude    float64           `json:"longitude"`
-	City         string            `json:"city"`
-	Timezone     string            `json:"timezone"`
-	CreatedAt    string            `json:"created_at"`
-	Subscription *UserSubscription `json:"subscription"`
+	Id              string            `json:"id"`
+	Email           string            `json:"email"`
+	EmailVerifiedAt string            `json:"email_verified_at"`
+	Name            string            `json:"name"`
+	Latitude        float64           `json:"latitude"`
+	Longitude       float64           `json:"longitude"`
+	City            string            `json:"city"`
+	Timezone        string            `json:"timezone"`
+	Role            string            `json:"role"`
+	CreatedAt       string            `json:"created_at"`
+	Subscription    *UserSubscription `json:"subscription"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin support influencer"`
 }
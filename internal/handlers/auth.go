@@ -3,8 +3,9 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ import (
 	"github.com/mdayat/demi-masa-backend-service/configs"
 	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
 	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc"
 	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
 	"github.com/mdayat/demi-masa-backend-service/internal/services"
 	"github.com/mdayat/demi-masa-backend-service/repository"
@@ -26,6 +28,13 @@ type AuthHandler interface {
 	Login(res http.ResponseWriter, req *http.Request)
 	Logout(res http.ResponseWriter, req *http.Request)
 	Refresh(res http.ResponseWriter, req *http.Request)
+	VerifyEmail(res http.ResponseWriter, req *http.Request)
+	ResendEmailVerification(res http.ResponseWriter, req *http.Request)
+	ForgotPassword(res http.ResponseWriter, req *http.Request)
+	ResetPassword(res http.ResponseWriter, req *http.Request)
+	LoginWithGoogle(res http.ResponseWriter, req *http.Request)
+	VerifyMFA(res http.ResponseWriter, req *http.Request)
+	GetJWKS(res http.ResponseWriter, req *http.Request)
 }
 
 type auth struct {
@@ -57,6 +66,7 @@ func (a auth) Register(res http.ResponseWriter, req *http.Request) {
 		Username:  reqBody.Username,
 		UserEmail: reqBody.Email,
 		Password:  reqBody.Password,
+		Client:    newSessionClient(req, reqBody.DeviceName),
 	})
 
 	if err != nil {
@@ -71,6 +81,12 @@ func (a auth) Register(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// The user can ask for another mail, so failing to send it doesn't fail
+	// the registration.
+	if err := a.service.SendEmailVerification(ctx, result.User.ID); err != nil {
+		logger.Error().Err(err).Caller().Msg("failed to send email verification")
+	}
+
 	resBody := dtos.AuthResponse{
 		RefreshToken: result.RefreshToken,
 		AccessToken:  result.AccessToken,
@@ -82,10 +98,16 @@ func (a auth) Register(res http.ResponseWriter, req *http.Request) {
 			Longitude: result.User.Coordinates.P.X,
 			City:      result.User.City,
 			Timezone:  result.User.Timezone,
+			Role:      result.User.Role,
 			CreatedAt: result.User.CreatedAt.Time.Format(time.RFC3339),
 		},
 	}
 
+	if wantsRefreshTokenCookie(req) {
+		resBody.CSRFToken = a.setRefreshTokenCookies(res, resBody.RefreshToken)
+		resBody.RefreshToken = ""
+	}
+
 	params := httputil.SendSuccessResponseParams{
 		StatusCode: http.StatusCreated,
 		ResBody:    resBody,
@@ -115,10 +137,14 @@ func (a auth) Login(res http.ResponseWriter, req *http.Request) {
 	result, err := a.service.AuthenticateUser(ctx, services.AuthenticateUserParams{
 		Email:    reqBody.Email,
 		Password: reqBody.Password,
+		Client:   newSessionClient(req, reqBody.DeviceName),
 	})
 
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		var throttledErr *services.LoginThrottledError
+		if errors.As(err, &throttledErr) {
+			sendLoginThrottledError(res, req, throttledErr)
+		} else if errors.Is(err, pgx.ErrNoRows) {
 			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("user not found")
 			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		} else {
@@ -128,6 +154,11 @@ func (a auth) Login(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if result.MFAToken != "" {
+		sendMFAChallenge(res, req, result.MFAToken)
+		return
+	}
+
 	resBody := dtos.AuthResponse{
 		RefreshToken: result.RefreshToken,
 		AccessToken:  result.AccessToken,
@@ -139,10 +170,20 @@ func (a auth) Login(res http.ResponseWriter, req *http.Request) {
 			Longitude: result.User.Coordinates.P.X,
 			City:      result.User.City,
 			Timezone:  result.User.Timezone,
+			Role:      result.User.Role,
 			CreatedAt: result.User.CreatedAt.Time.Format(time.RFC3339),
 		},
 	}
 
+	if result.User.EmailVerifiedAt.Valid {
+		resBody.User.EmailVerifiedAt = result.User.EmailVerifiedAt.Time.Format(time.RFC3339)
+	}
+
+	if wantsRefreshTokenCookie(req) {
+		resBody.CSRFToken = a.setRefreshTokenCookies(res, resBody.RefreshToken)
+		resBody.RefreshToken = ""
+	}
+
 	params := httputil.SendSuccessResponseParams{
 		StatusCode: http.StatusOK,
 		ResBody:    resBody,
@@ -161,15 +202,19 @@ func (a auth) Logout(res http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	logger := log.Ctx(ctx).With().Logger()
 
-	authHeader := req.Header.Get("Authorization")
-	splittedAuthHeader := strings.Split(authHeader, "Bearer ")
-	if authHeader == "" || len(splittedAuthHeader) != 2 {
-		logger.Error().Err(errors.New("invalid authorization header")).Caller().Int("status_code", http.StatusUnauthorized).Send()
-		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	refreshToken, fromCookie, err := a.refreshTokenFromRequest(req)
+	if err != nil {
+		if errors.Is(err, errInvalidCSRFToken) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Send()
+			http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Send()
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		}
 		return
 	}
 
-	claims, err := a.service.ValidateRefreshToken(splittedAuthHeader[1])
+	claims, err := a.service.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid refresh token")
 		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
@@ -204,6 +249,10 @@ func (a auth) Logout(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if fromCookie {
+		a.clearRefreshTokenCookies(res)
+	}
+
 	logger.Info().Int("status_code", http.StatusOK).Msg("successfully revoked refresh token")
 }
 
@@ -211,69 +260,384 @@ func (a auth) Refresh(res http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	logger := log.Ctx(ctx).With().Logger()
 
-	authHeader := req.Header.Get("Authorization")
-	splittedAuthHeader := strings.Split(authHeader, "Bearer ")
-	if authHeader == "" || len(splittedAuthHeader) != 2 {
-		logger.Error().Err(errors.New("invalid authorization header")).Caller().Int("status_code", http.StatusUnauthorized).Send()
-		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	refreshToken, fromCookie, err := a.refreshTokenFromRequest(req)
+	if err != nil {
+		if errors.Is(err, errInvalidCSRFToken) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Send()
+			http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Send()
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		}
 		return
 	}
 
-	claims, err := a.service.ValidateRefreshToken(splittedAuthHeader[1])
+	claims, err := a.service.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid refresh token")
 		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 		return
 	}
 
-	refreshToken, err := retryutil.RetryWithData(func() (repository.RefreshToken, error) {
-		refreshTokenUUID, err := uuid.Parse(claims.ID)
-		if err != nil {
-			return repository.RefreshToken{}, fmt.Errorf("failed to parse JTI to UUID: %w", err)
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	result, err := a.service.RotateRefreshToken(ctx, services.RotateRefreshTokenParams{
+		Jti:      claims.ID,
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		Client:   newSessionClient(req, ""),
+	})
+
+	if err != nil {
+		var reuseErr *services.RefreshTokenReuseError
+		if errors.As(err, &reuseErr) {
+			logger.Warn().
+				Err(err).
+				Str("security_event", "refresh_token_reuse").
+				Str("user_id", claims.Subject).
+				Str("family_id", reuseErr.FamilyID.String()).
+				Int("status_code", http.StatusUnauthorized).
+				Msg("revoked refresh token family after reuse of rotated refresh token")
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		} else if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, services.ErrRefreshTokenRevoked) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid refresh token")
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to rotate refresh token")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		}
+		return
+	}
 
-		userUUID, err := uuid.Parse(claims.Subject)
-		if err != nil {
-			return repository.RefreshToken{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+	resBody := dtos.RefreshResponse{
+		RefreshToken: result.RefreshToken,
+		AccessToken:  result.AccessToken,
+	}
+
+	if fromCookie || wantsRefreshTokenCookie(req) {
+		resBody.CSRFToken = a.setRefreshTokenCookies(res, resBody.RefreshToken)
+		resBody.RefreshToken = ""
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully rotated refresh token")
+}
+
+func (a auth) VerifyEmail(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.VerifyEmailRequest
+	if err := httputil.DecodeAndValidate(req, a.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	user, err := a.service.VerifyEmail(ctx, reqBody.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid email verification token")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to verify email")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		}
+		return
+	}
 
-		return a.configs.Db.Queries.SelectUserRefreshToken(ctx, repository.SelectUserRefreshTokenParams{
-			ID:     pgtype.UUID{Bytes: refreshTokenUUID, Valid: true},
-			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
-		})
-	})
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Str("user_id", user.ID.String()).Int("status_code", http.StatusNoContent).Msg("successfully verified email")
+}
+
+func (a auth) ResendEmailVerification(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
 
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user refresh token")
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
 		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	if (err != nil && errors.Is(err, pgx.ErrNoRows)) || refreshToken.Revoked || refreshToken.ExpiresAt.Time.Before(time.Now()) {
-		logger.Error().Err(errors.New("invalid refresh token")).Caller().Int("status_code", http.StatusUnauthorized).Send()
-		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	err = a.service.SendEmailVerification(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("user not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("email already verified")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send email verification")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
 		return
 	}
 
-	result, err := a.service.RotateRefreshToken(ctx, services.RotateRefreshTokenParams{
-		Jti:       refreshToken.ID.String(),
-		UserUUID:  refreshToken.UserID,
-		ExpiresAt: refreshToken.ExpiresAt.Time,
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully sent email verification")
+}
+
+func (a auth) ForgotPassword(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.ForgotPasswordRequest
+	if err := httputil.DecodeAndValidate(req, a.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.service.ForgotPassword(ctx, reqBody.Email); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send password reset")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully handled forgot password")
+}
+
+func (a auth) ResetPassword(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.ResetPasswordRequest
+	if err := httputil.DecodeAndValidate(req, a.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	err := a.service.ResetPassword(ctx, services.ResetPasswordParams{
+		Token:    reqBody.Token,
+		Password: reqBody.Password,
+	})
+
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid password reset token")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to reset password")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully reset password")
+}
+
+func (a auth) LoginWithGoogle(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.GoogleLoginRequest
+	if err := httputil.DecodeAndValidate(req, a.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	result, err := a.service.AuthenticateGoogleUser(ctx, services.AuthenticateGoogleUserParams{
+		Code:         reqBody.Code,
+		RedirectURI:  reqBody.RedirectURI,
+		CodeVerifier: reqBody.CodeVerifier,
+		Nonce:        reqBody.Nonce,
+		Client:       newSessionClient(req, reqBody.DeviceName),
 	})
 
 	if err != nil {
-		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to rotate refresh token")
+		if errors.Is(err, services.ErrGoogleNotConfigured) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("google sign in not configured")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else if errors.Is(err, oidc.ErrExchangeFailed) || errors.Is(err, oidc.ErrInvalidIDToken) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid google authorization code")
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		} else if errors.Is(err, services.ErrEmailNotVerified) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Msg("google email not verified")
+			http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to authenticate google user")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if result.MFAToken != "" {
+		sendMFAChallenge(res, req, result.MFAToken)
+		return
+	}
+
+	resBody := dtos.AuthResponse{
+		RefreshToken: result.RefreshToken,
+		AccessToken:  result.AccessToken,
+		User: dtos.UserResponse{
+			Id:        result.User.ID.String(),
+			Email:     result.User.Email,
+			Name:      result.User.Name,
+			Latitude:  result.User.Coordinates.P.Y,
+			Longitude: result.User.Coordinates.P.X,
+			City:      result.User.City,
+			Timezone:  result.User.Timezone,
+			Role:      result.User.Role,
+			CreatedAt: result.User.CreatedAt.Time.Format(time.RFC3339),
+		},
+	}
+
+	if result.User.EmailVerifiedAt.Valid {
+		resBody.User.EmailVerifiedAt = result.User.EmailVerifiedAt.Time.Format(time.RFC3339)
+	}
+
+	if wantsRefreshTokenCookie(req) {
+		resBody.CSRFToken = a.setRefreshTokenCookies(res, resBody.RefreshToken)
+		resBody.RefreshToken = ""
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
 		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	resBody := dtos.RefreshResponse{
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully authenticated google user")
+}
+
+// sendLoginThrottledError tells the client when it may try to sign in again.
+func sendLoginThrottledError(res http.ResponseWriter, req *http.Request, throttledErr *services.LoginThrottledError) {
+	logger := log.Ctx(req.Context()).With().Logger()
+
+	retryAfter := int(math.Ceil(throttledErr.RetryAfter.Seconds()))
+	res.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	resBody := dtos.ErrorResponse{
+		Error:   "too_many_attempts",
+		Message: fmt.Sprintf("too many failed sign in attempts, try again in %d seconds", retryAfter),
+	}
+
+	if throttledErr.Locked {
+		resBody = dtos.ErrorResponse{
+			Error:   "account_locked",
+			Message: fmt.Sprintf("signing in is locked after too many failed attempts, try again in %d minutes", int(math.Ceil(float64(retryAfter)/60))),
+		}
+	}
+
+	params := httputil.SendErrorResponseParams{
+		StatusCode: http.StatusTooManyRequests,
+		ResBody:    resBody,
+	}
+
+	logger.Warn().Err(throttledErr).Caller().Int("status_code", http.StatusTooManyRequests).Str("security_event", "login_throttled").Msg("sign in throttled")
+	if err := httputil.SendErrorResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send error response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// sendMFAChallenge responds to a sign in of a user with two-factor
+// authentication enabled, which is finished through VerifyMFA.
+func sendMFAChallenge(res http.ResponseWriter, req *http.Request, mfaToken string) {
+	logger := log.Ctx(req.Context()).With().Logger()
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody: dtos.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		},
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully challenged user for second factor")
+}
+
+func (a auth) VerifyMFA(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.VerifyMFARequest
+	if err := httputil.DecodeAndValidate(req, a.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	result, err := a.service.VerifyMFAChallenge(ctx, services.VerifyMFAChallengeParams{
+		Token:  reqBody.MFAToken,
+		Code:   reqBody.Code,
+		Client: newSessionClient(req, ""),
+	})
+
+	if err != nil {
+		var throttledErr *services.LoginThrottledError
+		if errors.As(err, &throttledErr) {
+			sendLoginThrottledError(res, req, throttledErr)
+		} else if errors.Is(err, services.ErrInvalidMFAToken) || errors.Is(err, services.ErrInvalidMFACode) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid MFA challenge")
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to verify MFA challenge")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := dtos.AuthResponse{
 		RefreshToken: result.RefreshToken,
 		AccessToken:  result.AccessToken,
+		User: dtos.UserResponse{
+			Id:        result.User.ID.String(),
+			Email:     result.User.Email,
+			Name:      result.User.Name,
+			Latitude:  result.User.Coordinates.P.Y,
+			Longitude: result.User.Coordinates.P.X,
+			City:      result.User.City,
+			Timezone:  result.User.Timezone,
+			Role:      result.User.Role,
+			CreatedAt: result.User.CreatedAt.Time.Format(time.RFC3339),
+		},
+	}
+
+	if result.User.EmailVerifiedAt.Valid {
+		resBody.User.EmailVerifiedAt = result.User.EmailVerifiedAt.Time.Format(time.RFC3339)
+	}
+
+	if wantsRefreshTokenCookie(req) {
+		resBody.CSRFToken = a.setRefreshTokenCookies(res, resBody.RefreshToken)
+		resBody.RefreshToken = ""
 	}
 
 	params := httputil.SendSuccessResponseParams{
-		StatusCode: http.StatusCreated,
+		StatusCode: http.StatusOK,
 		ResBody:    resBody,
 	}
 
@@ -283,5 +647,29 @@ func (a auth) Refresh(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully rotated refresh token")
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully verified MFA challenge")
+}
+
+// jwksMaxAge is how long verifiers may cache the JWKS. Keys should be added to
+// the ring at least this long before they become active.
+const jwksMaxAge = time.Hour
+
+// GetJWKS publishes the public keys access tokens are verified with.
+func (a auth) GetJWKS(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	res.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    a.configs.KeyRing.JWKS(),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got JWKS")
 }
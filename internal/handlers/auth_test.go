@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc/oidctest"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+func TestEmailVerificationHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	verifyEmailTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "VerifyEmail/Bad Request (invalid token)",
+			reqBody:        `{"token": "invalid"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "VerifyEmail/Bad Request (missing token)",
+			reqBody:        `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range verifyEmailTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/auth/verify-email", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("ResendEmailVerification/Conflict", func(t *testing.T) {
+		url := fmt.Sprintf("%s/auth/verify-email/resend", testServer.URL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		// The seeded test user has verified their email.
+		if res.StatusCode != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, res.StatusCode)
+		}
+	})
+}
+
+func TestPasswordResetHandlers(t *testing.T) {
+	passwordResetTable := []struct {
+		name           string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "ForgotPassword/Success",
+			path:           "auth/password/forgot",
+			reqBody:        `{"email": "example@gmail.com"}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "ForgotPassword/Success (unknown email)",
+			path:           "auth/password/forgot",
+			reqBody:        `{"email": "unknown@gmail.com"}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "ForgotPassword/Bad Request (email)",
+			path:           "auth/password/forgot",
+			reqBody:        `{"email": "example"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ResetPassword/Bad Request (invalid token)",
+			path:           "auth/password/reset",
+			reqBody:        `{"token": "invalid", "password": "new-password"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ResetPassword/Bad Request (password)",
+			path:           "auth/password/reset",
+			reqBody:        `{"token": "invalid", "password": "short"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range passwordResetTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/%s", testServer.URL, v.path)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGoogleLoginHandler(t *testing.T) {
+	redirectURI := "http://localhost:3000/auth/google/callback"
+
+	googleLoginTable := []struct {
+		name           string
+		code           string
+		expectedStatus int
+		expectedEmail  string
+	}{
+		{
+			name: "Success (links existing user)",
+			code: testGoogleIssuer.IssueCode(oidctest.Identity{
+				Subject:       "google-example",
+				Email:         "example@gmail.com",
+				EmailVerified: true,
+				Name:          "Example",
+			}, "nonce", redirectURI),
+			expectedStatus: http.StatusOK,
+			expectedEmail:  "example@gmail.com",
+		},
+		{
+			name:           "Unauthorized (invalid code)",
+			code:           "invalid",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Forbidden (email not verified)",
+			code: testGoogleIssuer.IssueCode(oidctest.Identity{
+				Subject: "google-unverified",
+				Email:   "unverified@gmail.com",
+				Name:    "Unverified",
+			}, "nonce", redirectURI),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, v := range googleLoginTable {
+		t.Run(v.name, func(t *testing.T) {
+			reqBody := fmt.Sprintf(`{"code": %q, "redirect_uri": %q, "nonce": "nonce"}`, v.code, redirectURI)
+			url := fmt.Sprintf("%s/auth/google", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resBody dtos.AuthResponse
+			if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			if resBody.User.Email != v.expectedEmail {
+				t.Fatalf("expected email %q, got %q", v.expectedEmail, resBody.User.Email)
+			}
+		})
+	}
+}
+
+func TestGoogleLoginTakeover(t *testing.T) {
+	ctx := context.TODO()
+	redirectURI := "http://localhost:3000/auth/google/callback"
+	email := fmt.Sprintf("takeover-%s@gmail.com", uuid.NewString()[:8])
+
+	// Someone registers the email without owning it and enrols a second
+	// factor, which must not lock the owner out of signing in with Google.
+	reqBody := fmt.Sprintf(`{"username": "Squatter", "email": %q, "password": "squatter-password"}`, email)
+	res, err := testClient.Post(fmt.Sprintf("%s/auth/register", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	squatter, err := testQueries.SelectUserByEmail(ctx, email)
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	_, err = testQueries.UpsertUserTOTP(ctx, repository.UpsertUserTOTPParams{UserID: squatter.ID, EncryptedSecret: "secret"})
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	_, err = testQueries.ConfirmUserTOTP(ctx, repository.ConfirmUserTOTPParams{UserID: squatter.ID})
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	code := testGoogleIssuer.IssueCode(oidctest.Identity{
+		Subject:       "google-" + email,
+		Email:         email,
+		EmailVerified: true,
+		Name:          "Owner",
+	}, "nonce", redirectURI)
+
+	reqBody = fmt.Sprintf(`{"code": %q, "redirect_uri": %q, "nonce": "nonce"}`, code, redirectURI)
+	res, err = testClient.Post(fmt.Sprintf("%s/auth/google", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var resBody struct {
+		dtos.AuthResponse
+		dtos.MFAChallengeResponse
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	if resBody.MFARequired || resBody.AccessToken == "" {
+		t.Fatalf("expected tokens, got an MFA challenge")
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	ctx := context.TODO()
+
+	// A fresh email and address, so earlier runs don't count.
+	suffix := uuid.NewString()[:8]
+	reqBody := fmt.Sprintf(`{"email": "throttled-%s@gmail.com", "password": "wrong-password"}`, suffix)
+	ipAddress := fmt.Sprintf("2001:db8::%s", suffix[:4])
+
+	login := func(t *testing.T) *http.Response {
+		url := fmt.Sprintf("%s/auth/login", testServer.URL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		req.Header.Set("X-Real-IP", ipAddress)
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		return res
+	}
+
+	t.Run("Login/Not Found (free attempts)", func(t *testing.T) {
+		for range 5 {
+			res := login(t)
+			res.Body.Close()
+
+			if res.StatusCode != http.StatusNotFound {
+				t.Fatalf("expected status %d, got %d", http.StatusNotFound, res.StatusCode)
+			}
+		}
+	})
+
+	t.Run("Login/Too Many Requests", func(t *testing.T) {
+		res := login(t)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, res.StatusCode)
+		}
+
+		if res.Header.Get("Retry-After") == "" {
+			t.Fatal("expected Retry-After header")
+		}
+
+		var resBody dtos.ErrorResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if resBody.Error != "too_many_attempts" {
+			t.Fatalf("expected error %q, got %q", "too_many_attempts", resBody.Error)
+		}
+	})
+}
+
+func TestLoginThrottleParallel(t *testing.T) {
+	ctx := context.TODO()
+
+	suffix := uuid.NewString()[:8]
+	reqBody := fmt.Sprintf(`{"email": "throttled-parallel-%s@gmail.com", "password": "wrong-password"}`, suffix)
+	ipAddress := fmt.Sprintf("2001:db8:1::%s", suffix[:4])
+
+	// Parallel guesses are counted one after another, so only the free
+	// attempts get through.
+	statusCodes := make(chan int, 10)
+	var wg sync.WaitGroup
+	for range cap(statusCodes) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url := fmt.Sprintf("%s/auth/login", testServer.URL)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer([]byte(reqBody)))
+			if err != nil {
+				t.Errorf("wasn't expecting error, got: %v", err)
+				return
+			}
+			req.Header.Set("X-Real-IP", ipAddress)
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Errorf("wasn't expecting error, got: %v", err)
+				return
+			}
+			res.Body.Close()
+			statusCodes <- res.StatusCode
+		}()
+	}
+
+	wg.Wait()
+	close(statusCodes)
+
+	var notFound int
+	for statusCode := range statusCodes {
+		if statusCode == http.StatusNotFound {
+			notFound++
+		} else if statusCode != http.StatusTooManyRequests {
+			t.Errorf("expected status %d or %d, got %d", http.StatusNotFound, http.StatusTooManyRequests, statusCode)
+		}
+	}
+
+	if notFound != 5 {
+		t.Fatalf("expected %d failed attempts to get through, got %d", 5, notFound)
+	}
+}
+
+func TestGetJWKS(t *testing.T) {
+	url := fmt.Sprintf("%s/.well-known/jwks.json", testServer.URL)
+	res, err := testClient.Get(url)
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var keySet oidc.JSONWebKeySet
+	if err := json.NewDecoder(res.Body).Decode(&keySet); err != nil {
+		t.Fatalf("unexpected response body: %v", res)
+	}
+
+	// The key the test server signs with.
+	if len(keySet.Keys) != 1 || keySet.Keys[0].Kid != "test" || keySet.Keys[0].Kty != "OKP" {
+		t.Fatalf("unexpected JWKS: %+v", keySet)
+	}
+}
+
+func TestRefreshTokenCookie(t *testing.T) {
+	ctx := context.TODO()
+
+	findCookie := func(res *http.Response, name string) *http.Cookie {
+		for _, cookie := range res.Cookies() {
+			if cookie.Name == name {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	var refreshTokenCookie, csrfTokenCookie *http.Cookie
+	t.Run("Register/Created (cookie mode)", func(t *testing.T) {
+		reqBody := fmt.Sprintf(`{"username": "Cookie", "email": "cookie-%s@gmail.com", "password": "cookie-password"}`, uuid.NewString()[:8])
+		url := fmt.Sprintf("%s/auth/register", testServer.URL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		req.Header.Set("X-Refresh-Token-Mode", "cookie")
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		var resBody dtos.AuthResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if resBody.RefreshToken != "" {
+			t.Fatal("expected no refresh token in response body")
+		}
+
+		refreshTokenCookie = findCookie(res, "refresh_token")
+		if refreshTokenCookie == nil || !refreshTokenCookie.HttpOnly || !refreshTokenCookie.Secure || refreshTokenCookie.Path != "/auth" {
+			t.Fatalf("unexpected refresh token cookie: %v", refreshTokenCookie)
+		}
+
+		csrfTokenCookie = findCookie(res, "csrf_token")
+		if csrfTokenCookie == nil || csrfTokenCookie.Value != resBody.CSRFToken {
+			t.Fatalf("unexpected CSRF token cookie: %v", csrfTokenCookie)
+		}
+	})
+
+	sendCookieRequest := func(t *testing.T, method, path, csrfToken string) *http.Response {
+		url := fmt.Sprintf("%s/%s", testServer.URL, path)
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		// The client doesn't send Secure cookies over plain HTTP by itself.
+		req.AddCookie(refreshTokenCookie)
+		req.AddCookie(csrfTokenCookie)
+		if csrfToken != "" {
+			req.Header.Set("X-CSRF-Token", csrfToken)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		return res
+	}
+
+	t.Run("Refresh/Forbidden (missing CSRF token)", func(t *testing.T) {
+		res := sendCookieRequest(t, http.MethodGet, "auth/refresh", "")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("Refresh/Forbidden (wrong CSRF token)", func(t *testing.T) {
+		res := sendCookieRequest(t, http.MethodGet, "auth/refresh", "forged")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("Refresh/Created", func(t *testing.T) {
+		res := sendCookieRequest(t, http.MethodGet, "auth/refresh", csrfTokenCookie.Value)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		var resBody dtos.RefreshResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if resBody.RefreshToken != "" || resBody.AccessToken == "" {
+			t.Fatalf("unexpected response body: %+v", resBody)
+		}
+
+		rotatedCookie := findCookie(res, "refresh_token")
+		if rotatedCookie == nil || rotatedCookie.Value == refreshTokenCookie.Value {
+			t.Fatalf("expected rotated refresh token cookie, got: %v", rotatedCookie)
+		}
+
+		refreshTokenCookie = rotatedCookie
+		csrfTokenCookie = findCookie(res, "csrf_token")
+	})
+
+	t.Run("Logout/OK", func(t *testing.T) {
+		res := sendCookieRequest(t, http.MethodPost, "auth/logout", csrfTokenCookie.Value)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		if cookie := findCookie(res, "refresh_token"); cookie == nil || cookie.MaxAge >= 0 {
+			t.Fatalf("expected refresh token cookie to be cleared, got: %v", cookie)
+		}
+	})
+}
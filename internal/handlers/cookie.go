@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+)
+
+// In the refresh cookie mode the refresh token is kept out of reach of the
+// scripts of the web app. Clients opt in with the X-Refresh-Token-Mode: cookie
+// header when signing in, and the refresh token is then set as an HttpOnly
+// cookie scoped to /auth instead of being sent in the response body.
+//
+// Refresh and Logout read the cookie when there is no Authorization header.
+// Since browsers attach it to any request, they also require the CSRF token in
+// the X-CSRF-Token header. It is sent in the response body and in a cookie the
+// web app can read, and it must match both that cookie and the refresh token.
+const (
+	refreshTokenModeHeader = "X-Refresh-Token-Mode"
+	csrfTokenHeader        = "X-CSRF-Token"
+	refreshTokenCookieName = "refresh_token"
+	csrfTokenCookieName    = "csrf_token"
+)
+
+var (
+	errMissingRefreshToken = errors.New("missing refresh token")
+	errInvalidCSRFToken    = errors.New("invalid CSRF token")
+)
+
+func wantsRefreshTokenCookie(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get(refreshTokenModeHeader), "cookie")
+}
+
+// setRefreshTokenCookies sets the refresh token and its CSRF token as cookies,
+// and returns the CSRF token.
+func (a auth) setRefreshTokenCookies(res http.ResponseWriter, refreshToken string) string {
+	csrfToken := a.service.CreateCSRFToken(refreshToken)
+	maxAge := int(services.RefreshTokenExpiration.Seconds())
+
+	http.SetCookie(res, &http.Cookie{
+		Name:     refreshTokenCookieName,
+		Value:    refreshToken,
+		Path:     "/auth",
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	http.SetCookie(res, &http.Cookie{
+		Name:     csrfTokenCookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		Domain:   a.configs.Env.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return csrfToken
+}
+
+func (a auth) clearRefreshTokenCookies(res http.ResponseWriter) {
+	http.SetCookie(res, &http.Cookie{
+		Name:     refreshTokenCookieName,
+		Path:     "/auth",
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	http.SetCookie(res, &http.Cookie{
+		Name:     csrfTokenCookieName,
+		Path:     "/",
+		Domain:   a.configs.Env.CookieDomain,
+		MaxAge:   -1,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// refreshTokenFromRequest returns the refresh token of the Authorization
+// header, or else of the cookie after checking the CSRF token. It reports
+// whether the token came from the cookie.
+func (a auth) refreshTokenFromRequest(req *http.Request) (string, bool, error) {
+	if authHeader := req.Header.Get("Authorization"); authHeader != "" {
+		splittedAuthHeader := strings.Split(authHeader, "Bearer ")
+		if len(splittedAuthHeader) != 2 {
+			return "", false, errors.New("invalid authorization header")
+		}
+		return splittedAuthHeader[1], false, nil
+	}
+
+	refreshTokenCookie, err := req.Cookie(refreshTokenCookieName)
+	if err != nil {
+		return "", false, errMissingRefreshToken
+	}
+
+	csrfTokenCookie, err := req.Cookie(csrfTokenCookieName)
+	if err != nil {
+		return "", true, errInvalidCSRFToken
+	}
+
+	csrfToken := req.Header.Get(csrfTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(csrfToken), []byte(csrfTokenCookie.Value)) != 1 ||
+		!a.service.ValidateCSRFToken(refreshTokenCookie.Value, csrfToken) {
+		return "", true, errInvalidCSRFToken
+	}
+
+	return refreshTokenCookie.Value, true, nil
+}
@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mdayat/demi-masa-backend-service/configs"
 	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
 	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
@@ -17,6 +22,10 @@ import (
 
 type CouponHandler interface {
 	GetCoupon(res http.ResponseWriter, req *http.Request)
+	GetCoupons(res http.ResponseWriter, req *http.Request)
+	CreateCoupon(res http.ResponseWriter, req *http.Request)
+	UpdateCoupon(res http.ResponseWriter, req *http.Request)
+	DeleteCoupon(res http.ResponseWriter, req *http.Request)
 }
 
 type coupon struct {
@@ -70,3 +79,189 @@ func (c coupon) GetCoupon(res http.ResponseWriter, req *http.Request) {
 	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got coupon")
 
 }
+
+func (c coupon) GetCoupons(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	coupons, err := retryutil.RetryWithData(func() ([]repository.Coupon, error) {
+		return c.configs.Db.Queries.SelectCoupons(ctx)
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select coupons")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := make([]dtos.CouponResponse, 0, len(coupons))
+	for _, coupon := range coupons {
+		resBody = append(resBody, dtos.CouponResponse{
+			Code:               coupon.Code,
+			InfluencerUsername: coupon.InfluencerUsername,
+			Quota:              coupon.Quota,
+			CreatedAt:          coupon.CreatedAt.Time.Format(time.RFC3339),
+		})
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got coupons")
+}
+
+func (c coupon) CreateCoupon(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.CouponRequest
+	if err := httputil.DecodeAndValidate(req, c.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := retryutil.RetryWithData(func() (repository.Coupon, error) {
+		return c.configs.Db.Queries.InsertCoupon(ctx, repository.InsertCouponParams{
+			Code:               reqBody.Code,
+			InfluencerUsername: reqBody.InfluencerUsername,
+			Quota:              reqBody.Quota,
+		})
+	})
+
+	if err != nil {
+		// Codes of deleted coupons stay taken, invoices still refer to them.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("coupon already exist")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to insert coupon")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := dtos.CouponResponse{
+		Code:               coupon.Code,
+		InfluencerUsername: coupon.InfluencerUsername,
+		Quota:              coupon.Quota,
+		CreatedAt:          coupon.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/coupons/%s", c.configs.Env.OriginURL, url.PathEscape(coupon.Code)))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created coupon")
+}
+
+func (c coupon) UpdateCoupon(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.UpdateCouponRequest
+	if err := httputil.DecodeAndValidate(req, c.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if reqBody.InfluencerUsername == "" && reqBody.Quota == nil {
+		res.WriteHeader(http.StatusNoContent)
+		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
+		return
+	}
+
+	var influencerUsername pgtype.Text
+	if reqBody.InfluencerUsername != "" {
+		influencerUsername = pgtype.Text{String: reqBody.InfluencerUsername, Valid: true}
+	}
+
+	var quota pgtype.Int2
+	if reqBody.Quota != nil {
+		quota = pgtype.Int2{Int16: *reqBody.Quota, Valid: true}
+	}
+
+	couponCode := chi.URLParam(req, "couponCode")
+	coupon, err := retryutil.RetryWithData(func() (repository.Coupon, error) {
+		return c.configs.Db.Queries.UpdateCoupon(ctx, repository.UpdateCouponParams{
+			Code:               couponCode,
+			InfluencerUsername: influencerUsername,
+			Quota:              quota,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("coupon not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update coupon")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := dtos.CouponResponse{
+		Code:               coupon.Code,
+		InfluencerUsername: coupon.InfluencerUsername,
+		Quota:              coupon.Quota,
+		CreatedAt:          coupon.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated coupon")
+}
+
+// DeleteCoupon soft deletes the coupon, so it can't be redeemed anymore.
+func (c coupon) DeleteCoupon(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	couponCode := chi.URLParam(req, "couponCode")
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		return c.configs.Db.Queries.DeleteCoupon(ctx, couponCode)
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to delete coupon")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("coupon not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted coupon")
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestCouponAdminHandlers(t *testing.T) {
+	ctx := context.TODO()
+	couponCode := "admin-" + uuid.NewString()[:8]
+
+	sendCouponRequest := func(t *testing.T, method, path, reqBody string) *http.Response {
+		url := fmt.Sprintf("%s/%s", testServer.URL, path)
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		return res
+	}
+
+	couponTable := []struct {
+		name           string
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "CreateCoupon/Created",
+			method:         http.MethodPost,
+			path:           "coupons",
+			reqBody:        fmt.Sprintf(`{"code": %q, "influencer_username": "influencer", "quota": 10}`, couponCode),
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "CreateCoupon/Conflict",
+			method:         http.MethodPost,
+			path:           "coupons",
+			reqBody:        fmt.Sprintf(`{"code": %q, "influencer_username": "influencer", "quota": 10}`, couponCode),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "UpdateCoupon/Bad Request (negative quota)",
+			method:         http.MethodPut,
+			path:           "coupons/" + couponCode,
+			reqBody:        `{"quota": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UpdateCoupon/Success",
+			method:         http.MethodPut,
+			path:           "coupons/" + couponCode,
+			reqBody:        `{"quota": 20}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "DeleteCoupon/No Content",
+			method:         http.MethodDelete,
+			path:           "coupons/" + couponCode,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "GetCoupon/Not Found (deleted)",
+			method:         http.MethodGet,
+			path:           "coupons/" + couponCode,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "UpdateCoupon/Not Found (deleted)",
+			method:         http.MethodPut,
+			path:           "coupons/" + couponCode,
+			reqBody:        `{"quota": 30}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range couponTable {
+		t.Run(v.name, func(t *testing.T) {
+			res := sendCouponRequest(t, v.method, v.path, v.reqBody)
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("GetCoupons/Success", func(t *testing.T) {
+		res := sendCouponRequest(t, http.MethodGet, "coupons", "")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody []dtos.CouponResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		for _, coupon := range resBody {
+			if coupon.Code == couponCode {
+				t.Fatalf("expected deleted coupon %q not to be listed", couponCode)
+			}
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+var entitlementMessages = map[services.Entitlement]string{
+	services.EntitlementUnlimitedTasks: "the free plan is limited to %d active tasks",
+	services.EntitlementFullHistory:    "the free plan only keeps the last %d days of history",
+	services.EntitlementExtendedStats:  "the free plan only covers stats of up to %d days",
+}
+
+// sendEntitlementError responds with the missing entitlement when err is an
+// EntitlementError and reports whether it did. Free users get 402 as
+// subscribing unlocks the entitlement, subscribed users get 403.
+func sendEntitlementError(res http.ResponseWriter, req *http.Request, err error) bool {
+	var entitlementErr *services.EntitlementError
+	if !errors.As(err, &entitlementErr) {
+		return false
+	}
+
+	statusCode := http.StatusPaymentRequired
+	if entitlementErr.Subscribed {
+		statusCode = http.StatusForbidden
+	}
+
+	logger := log.Ctx(req.Context()).With().Logger()
+	logger.Error().Err(err).Caller().Int("status_code", statusCode).Msg("missing entitlement")
+
+	params := httputil.SendErrorResponseParams{
+		StatusCode: statusCode,
+		ResBody: dtos.EntitlementErrorResponse{
+			Error:       "entitlement_required",
+			Entitlement: string(entitlementErr.Entitlement),
+			Limit:       entitlementErr.Limit,
+			Message:     fmt.Sprintf(entitlementMessages[entitlementErr.Entitlement], entitlementErr.Limit),
+		},
+	}
+
+	if err := httputil.SendErrorResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send error response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+
+	return true
+}
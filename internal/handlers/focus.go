@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type FocusHandler interface {
+	StartFocusSession(res http.ResponseWriter, req *http.Request)
+	GetActiveFocusSession(res http.ResponseWriter, req *http.Request)
+	GetFocusSession(res http.ResponseWriter, req *http.Request)
+	PauseFocusSession(res http.ResponseWriter, req *http.Request)
+	ResumeFocusSession(res http.ResponseWriter, req *http.Request)
+	FinishFocusSession(res http.ResponseWriter, req *http.Request)
+	GetFocusTotals(res http.ResponseWriter, req *http.Request)
+	GetFocusPreference(res http.ResponseWriter, req *http.Request)
+	UpdateFocusPreference(res http.ResponseWriter, req *http.Request)
+}
+
+type focus struct {
+	configs     configs.Configs
+	service     services.FocusServicer
+	entitlement services.EntitlementServicer
+}
+
+func NewFocusHandler(configs configs.Configs, service services.FocusServicer, entitlement services.EntitlementServicer) FocusHandler {
+	return &focus{
+		configs:     configs,
+		service:     service,
+		entitlement: entitlement,
+	}
+}
+
+func newFocusSessionResponse(result services.FocusSessionResult) dtos.FocusSessionResponse {
+	session := result.Session
+	resBody := dtos.FocusSessionResponse{
+		Id:                       session.ID.String(),
+		PlannedDurationInMinutes: session.PlannedDurationInMinutes,
+		Status:                   session.Status,
+		FocusedSeconds:           result.FocusedSeconds,
+		PausedForPrayer:          session.PausedForPrayer.String,
+		StartedAt:                session.StartedAt.Time.Format(time.RFC3339),
+	}
+
+	if session.TaskID.Valid {
+		resBody.TaskId = session.TaskID.String()
+	}
+
+	if session.EndedAt.Valid {
+		resBody.EndedAt = session.EndedAt.Time.Format(time.RFC3339)
+	}
+
+	if result.UpcomingPrayer != nil {
+		resBody.UpcomingPrayer = &dtos.FocusPrayerWarning{
+			Prayer: result.UpcomingPrayer.Name,
+			Time:   result.UpcomingPrayer.Time.Format(time.RFC3339),
+		}
+	}
+
+	return resBody
+}
+
+func (f focus) StartFocusSession(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.StartFocusSessionRequest
+	if err := httputil.DecodeAndValidate(req, f.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := services.StartFocusSessionParams{
+		UserUUID:                 pgtype.UUID{Bytes: userUUID, Valid: true},
+		PlannedDurationInMinutes: reqBody.PlannedDurationInMinutes,
+	}
+
+	if reqBody.TaskId != "" {
+		taskUUID, err := uuid.Parse(reqBody.TaskId)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid task Id")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		params.TaskUUID = pgtype.UUID{Bytes: taskUUID, Valid: true}
+	}
+
+	result, err := f.service.StartFocusSession(ctx, params)
+	if err != nil {
+		if errors.Is(err, services.ErrFocusSessionActive) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("focus session already active")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to start focus session")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/focus-sessions/%s", f.configs.Env.OriginURL, result.Session.ID.String()))
+	successParams := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    newFocusSessionResponse(result),
+	}
+
+	if err := httputil.SendSuccessResponse(res, successParams); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully started focus session")
+}
+
+func (f focus) GetActiveFocusSession(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := f.service.GetActiveFocusSession(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("active focus session not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get active focus session")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newFocusSessionResponse(result),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got active focus session")
+}
+
+func (f focus) GetFocusSession(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	sessionId := chi.URLParam(req, "sessionId")
+	sessionUUID, err := uuid.Parse(sessionId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("focus session not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := f.service.GetFocusSession(ctx, services.FocusSessionParams{
+		UserUUID:    pgtype.UUID{Bytes: userUUID, Valid: true},
+		SessionUUID: pgtype.UUID{Bytes: sessionUUID, Valid: true},
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("focus session not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get focus session")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newFocusSessionResponse(result),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got focus session")
+}
+
+func (f focus) PauseFocusSession(res http.ResponseWriter, req *http.Request) {
+	f.transitionFocusSession(res, req, f.service.PauseFocusSession, "pause")
+}
+
+func (f focus) ResumeFocusSession(res http.ResponseWriter, req *http.Request) {
+	f.transitionFocusSession(res, req, f.service.ResumeFocusSession, "resume")
+}
+
+func (f focus) FinishFocusSession(res http.ResponseWriter, req *http.Request) {
+	f.transitionFocusSession(res, req, f.service.FinishFocusSession, "finish")
+}
+
+// transitionFocusSession handles the pause, resume, and finish endpoints,
+// which only differ in the service method they call.
+func (f focus) transitionFocusSession(
+	res http.ResponseWriter,
+	req *http.Request,
+	transition func(ctx context.Context, arg services.FocusSessionParams) (services.FocusSessionResult, error),
+	action string,
+) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	sessionId := chi.URLParam(req, "sessionId")
+	sessionUUID, err := uuid.Parse(sessionId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("focus session not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := transition(ctx, services.FocusSessionParams{
+		UserUUID:    pgtype.UUID{Bytes: userUUID, Valid: true},
+		SessionUUID: pgtype.UUID{Bytes: sessionUUID, Valid: true},
+	})
+
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFocusSessionTransition) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("invalid focus session transition")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("focus session not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msgf("failed to %s focus session", action)
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newFocusSessionResponse(result),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msgf("successfully performed focus session %s", action)
+}
+
+func (f focus) GetFocusTotals(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := services.GetFocusTotalsParams{UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true}}
+	if dateString := req.URL.Query().Get("date"); dateString != "" {
+		date, err := time.Parse(time.DateOnly, dateString)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid date query param")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		params.Date = date
+	}
+
+	params.Entitlements, err = f.entitlement.GetEntitlements(ctx, params.UserUUID)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	totals, err := f.service.GetFocusTotals(ctx, params)
+	if err != nil {
+		if sendEntitlementError(res, req, err) {
+			return
+		}
+
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get focus totals")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	successParams := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody: dtos.FocusTotalsResponse{
+			Date:          totals.Date.Format(time.DateOnly),
+			DailySeconds:  totals.DailySeconds,
+			WeekStartsOn:  totals.WeekStartsOn.Format(time.DateOnly),
+			WeeklySeconds: totals.WeeklySeconds,
+		},
+	}
+
+	if err := httputil.SendSuccessResponse(res, successParams); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got focus totals")
+}
+
+func newFocusPreferenceResponse(preference repository.FocusPreference) dtos.FocusPreferenceResponse {
+	return dtos.FocusPreferenceResponse{
+		DayStartsAt:                  formatClockTime(preference.DayStartsAt),
+		DayEndsAt:                    formatClockTime(preference.DayEndsAt),
+		PrayerDurationInMinutes:      preference.PrayerDurationInMinutes,
+		BreakInMinutes:               preference.BreakInMinutes,
+		DefaultTaskDurationInMinutes: preference.DefaultTaskDurationInMinutes,
+	}
+}
+
+// formatClockTime formats a time of day as HH:MM.
+func formatClockTime(clockTime pgtype.Time) string {
+	minutes := clockTime.Microseconds / time.Minute.Microseconds()
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// parseClockTime parses a time of day formatted as HH:MM.
+func parseClockTime(value string) (pgtype.Time, error) {
+	clockTime, err := time.Parse("15:04", value)
+	if err != nil {
+		return pgtype.Time{}, err
+	}
+
+	sinceMidnight := time.Duration(clockTime.Hour())*time.Hour + time.Duration(clockTime.Minute())*time.Minute
+	return pgtype.Time{Microseconds: sinceMidnight.Microseconds(), Valid: true}, nil
+}
+
+func (f focus) GetFocusPreference(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	preference, err := f.service.GetFocusPreference(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get focus preference")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newFocusPreferenceResponse(preference),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got focus preference")
+}
+
+func (f focus) UpdateFocusPreference(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.UpdateFocusPreferenceRequest
+	if err := httputil.DecodeAndValidate(req, f.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := services.UpdateFocusPreferenceParams{UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true}}
+	if reqBody.DayStartsAt != "" {
+		params.DayStartsAt, err = parseClockTime(reqBody.DayStartsAt)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid day_starts_at")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if reqBody.DayEndsAt != "" {
+		params.DayEndsAt, err = parseClockTime(reqBody.DayEndsAt)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid day_ends_at")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if reqBody.PrayerDurationInMinutes != nil {
+		params.PrayerDurationInMinutes = pgtype.Int2{Int16: *reqBody.PrayerDurationInMinutes, Valid: true}
+	}
+
+	if reqBody.BreakInMinutes != nil {
+		params.BreakInMinutes = pgtype.Int2{Int16: *reqBody.BreakInMinutes, Valid: true}
+	}
+
+	if reqBody.DefaultTaskDurationInMinutes != nil {
+		params.DefaultTaskDurationInMinutes = pgtype.Int2{Int16: *reqBody.DefaultTaskDurationInMinutes, Valid: true}
+	}
+
+	preference, err := f.service.UpdateFocusPreference(ctx, params)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFocusDay) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid focus day")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update focus preference")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	successParams := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newFocusPreferenceResponse(preference),
+	}
+
+	if err := httputil.SendSuccessResponse(res, successParams); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated focus preference")
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestFocusHandlers(t *testing.T) {
+	ctx := context.TODO()
+	var startedSession dtos.FocusSessionResponse
+
+	startTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.FocusSessionResponse
+	}{
+		{
+			name:           "StartFocusSession/Bad Request",
+			reqBody:        `{"planned_duration_in_minutes": 0}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "StartFocusSession/Not Found (task)",
+			reqBody:        `{"task_id": "00000000-0000-0000-0000-000000000000", "planned_duration_in_minutes": 25}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "StartFocusSession/Success",
+			reqBody:        `{"planned_duration_in_minutes": 25}`,
+			expectedStatus: http.StatusCreated,
+			expectedResult: dtos.FocusSessionResponse{PlannedDurationInMinutes: 25, Status: "running"},
+		},
+		{
+			name:           "StartFocusSession/Conflict",
+			reqBody:        `{"planned_duration_in_minutes": 25}`,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, v := range startTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/focus-sessions", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				if err := json.NewDecoder(res.Body).Decode(&startedSession); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				ignoredFields := cmpopts.IgnoreFields(dtos.FocusSessionResponse{}, "Id", "FocusedSeconds", "StartedAt", "UpcomingPrayer")
+				if diff := cmp.Diff(v.expectedResult, startedSession, ignoredFields); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+
+	getTable := []struct {
+		name           string
+		sessionId      string
+		expectedStatus int
+	}{
+		{
+			name:           "GetFocusSession/Success",
+			sessionId:      startedSession.Id,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GetFocusSession/Not Found",
+			sessionId:      "00000000-0000-0000-0000-000000000000",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range getTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/focus-sessions/%s", testServer.URL, v.sessionId)
+			res, err := testClient.Get(url)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var session dtos.FocusSessionResponse
+				if err := json.NewDecoder(res.Body).Decode(&session); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if session.Id != startedSession.Id || session.Status != "running" {
+					t.Errorf("expected the started focus session, got %+v", session)
+				}
+			}
+		})
+	}
+
+	t.Run("GetActiveFocusSession/Success", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/focus-sessions/active", testServer.URL))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var activeSession dtos.FocusSessionResponse
+		if err := json.NewDecoder(res.Body).Decode(&activeSession); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if activeSession.Id != startedSession.Id {
+			t.Errorf("expected active session %s, got %s", startedSession.Id, activeSession.Id)
+		}
+	})
+
+	transitionTable := []struct {
+		name           string
+		sessionId      string
+		action         string
+		expectedStatus int
+		expectedResult string
+	}{
+		{
+			name:           "PauseFocusSession/Success",
+			sessionId:      startedSession.Id,
+			action:         "pause",
+			expectedStatus: http.StatusOK,
+			expectedResult: "paused",
+		},
+		{
+			name:           "PauseFocusSession/Conflict",
+			sessionId:      startedSession.Id,
+			action:         "pause",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "ResumeFocusSession/Success",
+			sessionId:      startedSession.Id,
+			action:         "resume",
+			expectedStatus: http.StatusOK,
+			expectedResult: "running",
+		},
+		{
+			name:           "FinishFocusSession/Success",
+			sessionId:      startedSession.Id,
+			action:         "finish",
+			expectedStatus: http.StatusOK,
+			expectedResult: "finished",
+		},
+		{
+			name:           "FinishFocusSession/Conflict",
+			sessionId:      startedSession.Id,
+			action:         "finish",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "FinishFocusSession/Not Found",
+			sessionId:      "00000000-0000-0000-0000-000000000000",
+			action:         "finish",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range transitionTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/focus-sessions/%s/%s", testServer.URL, v.sessionId, v.action)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var session dtos.FocusSessionResponse
+				if err := json.NewDecoder(res.Body).Decode(&session); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if session.Status != v.expectedResult {
+					t.Errorf("expected status %s, got %s", v.expectedResult, session.Status)
+				}
+			}
+		})
+	}
+
+	totalsTable := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{
+			name:           "GetFocusTotals/Success",
+			query:          "",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GetFocusTotals/Success (date)",
+			query:          "?date=2025-03-19",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GetFocusTotals/Bad Request",
+			query:          "?date=19-03-2025",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range totalsTable {
+		t.Run(v.name, func(t *testing.T) {
+			res, err := testClient.Get(fmt.Sprintf("%s/focus-sessions/totals%s", testServer.URL, v.query))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var totals dtos.FocusTotalsResponse
+				if err := json.NewDecoder(res.Body).Decode(&totals); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if totals.WeeklySeconds < totals.DailySeconds {
+					t.Errorf("expected weekly seconds to be at least %d, got %d", totals.DailySeconds, totals.WeeklySeconds)
+				}
+			}
+		})
+	}
+
+	preferenceTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.FocusPreferenceResponse
+	}{
+		{
+			name:           "UpdateFocusPreference/Bad Request",
+			reqBody:        `{"day_starts_at": "5 AM"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UpdateFocusPreference/Bad Request (day range)",
+			reqBody:        `{"day_starts_at": "23:00"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UpdateFocusPreference/Success",
+			reqBody:        `{"day_starts_at": "06:30", "break_in_minutes": 10}`,
+			expectedStatus: http.StatusOK,
+			expectedResult: dtos.FocusPreferenceResponse{
+				DayStartsAt:                  "06:30",
+				DayEndsAt:                    "22:00",
+				PrayerDurationInMinutes:      20,
+				BreakInMinutes:               10,
+				DefaultTaskDurationInMinutes: 30,
+			},
+		},
+		{
+			name:           "UpdateFocusPreference/Success (restore)",
+			reqBody:        `{"day_starts_at": "05:00", "break_in_minutes": 5}`,
+			expectedStatus: http.StatusOK,
+			expectedResult: dtos.FocusPreferenceResponse{
+				DayStartsAt:                  "05:00",
+				DayEndsAt:                    "22:00",
+				PrayerDurationInMinutes:      20,
+				BreakInMinutes:               5,
+				DefaultTaskDurationInMinutes: 30,
+			},
+		},
+	}
+
+	for _, v := range preferenceTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/focus-preferences", testServer.URL)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var preference dtos.FocusPreferenceResponse
+				if err := json.NewDecoder(res.Body).Decode(&preference); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(v.expectedResult, preference); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+}
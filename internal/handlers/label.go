@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type LabelHandler interface {
+	GetLabels(res http.ResponseWriter, req *http.Request)
+	CreateLabel(res http.ResponseWriter, req *http.Request)
+	UpdateLabel(res http.ResponseWriter, req *http.Request)
+	DeleteLabel(res http.ResponseWriter, req *http.Request)
+	AttachTaskLabel(res http.ResponseWriter, req *http.Request)
+	DetachTaskLabel(res http.ResponseWriter, req *http.Request)
+}
+
+type label struct {
+	configs configs.Configs
+}
+
+func NewLabelHandler(configs configs.Configs) LabelHandler {
+	return &label{
+		configs: configs,
+	}
+}
+
+func (l label) GetLabels(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	labels, err := retryutil.RetryWithData(func() ([]repository.Label, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return l.configs.Db.Queries.SelectUserLabels(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user labels")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := make([]dtos.LabelResponse, 0, len(labels))
+	for _, label := range labels {
+		resBody = append(resBody, newLabelResponse(label))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got labels")
+}
+
+func (l label) CreateLabel(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.CreateLabelRequest
+	if err := httputil.DecodeAndValidate(req, l.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	labelUUID := uuid.New()
+	userId := ctx.Value(userIdKey{}).(string)
+	label, err := retryutil.RetryWithData(func() (repository.Label, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return repository.Label{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return l.configs.Db.Queries.InsertUserLabel(ctx, repository.InsertUserLabelParams{
+			ID:     pgtype.UUID{Bytes: labelUUID, Valid: true},
+			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+			Name:   reqBody.Name,
+			Color:  reqBody.Color,
+		})
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("label already exist")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to insert user label")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := newLabelResponse(label)
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/labels/%s", l.configs.Env.OriginURL, resBody.Id))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created label")
+}
+
+func (l label) UpdateLabel(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.UpdateLabelRequest
+	if err := httputil.DecodeAndValidate(req, l.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	labelId := chi.URLParam(req, "labelId")
+	labelUUID, err := uuid.Parse(labelId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if reqBody.Name == "" && reqBody.Color == "" {
+		res.WriteHeader(http.StatusNoContent)
+		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
+		return
+	}
+
+	var name pgtype.Text
+	if reqBody.Name != "" {
+		name = pgtype.Text{String: reqBody.Name, Valid: true}
+	}
+
+	var color pgtype.Text
+	if reqBody.Color != "" {
+		color = pgtype.Text{String: reqBody.Color, Valid: true}
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	label, err := retryutil.RetryWithData(func() (repository.Label, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return repository.Label{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return l.configs.Db.Queries.UpdateUserLabel(ctx, repository.UpdateUserLabelParams{
+			ID:     pgtype.UUID{Bytes: labelUUID, Valid: true},
+			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+			Name:   name,
+			Color:  color,
+		})
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("label not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("label already exist")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update user label")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newLabelResponse(label),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated label")
+}
+
+func (l label) DeleteLabel(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	labelId := chi.URLParam(req, "labelId")
+	labelUUID, err := uuid.Parse(labelId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return l.configs.Db.Queries.DeleteUserLabel(ctx, repository.DeleteUserLabelParams{
+			ID:     pgtype.UUID{Bytes: labelUUID, Valid: true},
+			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to delete user label")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted label")
+}
+
+func (l label) AttachTaskLabel(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskUUID, err := uuid.Parse(chi.URLParam(req, "taskId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	labelUUID, err := uuid.Parse(chi.URLParam(req, "labelId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return l.configs.Db.Queries.InsertUserTaskLabel(ctx, repository.InsertUserTaskLabelParams{
+			TaskID:  pgtype.UUID{Bytes: taskUUID, Valid: true},
+			LabelID: pgtype.UUID{Bytes: labelUUID, Valid: true},
+			UserID:  pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to insert user task label")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Both the task and the label must belong to the user, otherwise nothing
+	// is inserted.
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("task or label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully attached label to task")
+}
+
+func (l label) DetachTaskLabel(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskUUID, err := uuid.Parse(chi.URLParam(req, "taskId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	labelUUID, err := uuid.Parse(chi.URLParam(req, "labelId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return l.configs.Db.Queries.DeleteUserTaskLabel(ctx, repository.DeleteUserTaskLabelParams{
+			TaskID:  pgtype.UUID{Bytes: taskUUID, Valid: true},
+			LabelID: pgtype.UUID{Bytes: labelUUID, Valid: true},
+			UserID:  pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to delete user task label")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("task label not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully detached label from task")
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestLabelHandlers(t *testing.T) {
+	ctx := context.TODO()
+	var createdLabel dtos.LabelResponse
+
+	createLabelTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.LabelResponse
+	}{
+		{
+			name:           "CreateLabel/Success",
+			reqBody:        `{"name": "work", "color": "#1e90ff"}`,
+			expectedStatus: http.StatusCreated,
+			expectedResult: dtos.LabelResponse{Name: "work", Color: "#1e90ff"},
+		},
+		{
+			name:           "CreateLabel/Conflict",
+			reqBody:        `{"name": "work", "color": "#000000"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "CreateLabel/Bad Request (color)",
+			reqBody:        `{"name": "home", "color": "#fff"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range createLabelTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/labels", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				if err := json.NewDecoder(res.Body).Decode(&createdLabel); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(v.expectedResult, createdLabel, cmpopts.IgnoreFields(dtos.LabelResponse{}, "Id", "CreatedAt")); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+
+	var labeledTask, otherTask dtos.TaskResponse
+	createTaskTable := []struct {
+		name    string
+		reqBody string
+		task    *dtos.TaskResponse
+	}{
+		{
+			name:    "CreateTask/Success (labeled)",
+			reqBody: `{"name": "labeled", "description": "description", "due_at": "2025-03-15T10:00:00+07:00", "priority": 3}`,
+			task:    &labeledTask,
+		},
+		{
+			name:    "CreateTask/Success (other)",
+			reqBody: `{"name": "other", "description": "description", "priority": 1}`,
+			task:    &otherTask,
+		},
+	}
+
+	for _, v := range createTaskTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+			}
+
+			if err := json.NewDecoder(res.Body).Decode(v.task); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+		})
+	}
+
+	expectedDueAt := time.Date(2025, time.March, 15, 3, 0, 0, 0, time.UTC)
+	if dueAt, err := time.Parse(time.RFC3339, labeledTask.DueAt); err != nil || !dueAt.Equal(expectedDueAt) {
+		t.Errorf("expected due_at %s, got %s", expectedDueAt.Format(time.RFC3339), labeledTask.DueAt)
+	}
+
+	taskLabelTable := []struct {
+		name           string
+		method         string
+		taskId         string
+		labelId        string
+		expectedStatus int
+	}{
+		{
+			name:           "AttachTaskLabel/Success",
+			method:         http.MethodPut,
+			taskId:         labeledTask.Id,
+			labelId:        createdLabel.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "AttachTaskLabel/Success (already attached)",
+			method:         http.MethodPut,
+			taskId:         labeledTask.Id,
+			labelId:        createdLabel.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "AttachTaskLabel/Not Found (label)",
+			method:         http.MethodPut,
+			taskId:         labeledTask.Id,
+			labelId:        uuid.NewString(),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "DetachTaskLabel/Not Found",
+			method:         http.MethodDelete,
+			taskId:         otherTask.Id,
+			labelId:        createdLabel.Id,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range taskLabelTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s/labels/%s", testServer.URL, v.taskId, v.labelId)
+			req, err := http.NewRequestWithContext(ctx, v.method, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Errorf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	getTasksTable := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIds    []string
+		expectedCursor bool
+	}{
+		{
+			name:           "GetTasks/Success (label)",
+			query:          "?label=" + createdLabel.Id,
+			expectedStatus: http.StatusOK,
+			expectedIds:    []string{labeledTask.Id},
+		},
+		{
+			name:           "GetTasks/Success (due_before)",
+			query:          "?due_before=2025-03-16T00:00:00Z",
+			expectedStatus: http.StatusOK,
+			expectedIds:    []string{labeledTask.Id},
+		},
+		{
+			name:           "GetTasks/Success (priority)",
+			query:          "?priority=1",
+			expectedStatus: http.StatusOK,
+			expectedIds:    []string{otherTask.Id},
+		},
+		{
+			name:           "GetTasks/Success (sort by priority)",
+			query:          "?sort=-priority&limit=1",
+			expectedStatus: http.StatusOK,
+			expectedIds:    []string{labeledTask.Id},
+			expectedCursor: true,
+		},
+		{
+			name:           "GetTasks/Bad Request (sort)",
+			query:          "?sort=name",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GetTasks/Bad Request (limit)",
+			query:          "?limit=101",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GetTasks/Bad Request (cursor)",
+			query:          "?cursor=invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range getTasksTable {
+		t.Run(v.name, func(t *testing.T) {
+			res, err := testClient.Get(fmt.Sprintf("%s/tasks%s", testServer.URL, v.query))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var tasks []dtos.TaskResponse
+			if err = json.NewDecoder(res.Body).Decode(&tasks); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			taskIds := make([]string, 0, len(tasks))
+			for _, task := range tasks {
+				taskIds = append(taskIds, task.Id)
+				if task.Id == labeledTask.Id && (len(task.Labels) != 1 || task.Labels[0].Id != createdLabel.Id) {
+					t.Errorf("expected task to be labeled with %s, got %v", createdLabel.Id, task.Labels)
+				}
+			}
+
+			if diff := cmp.Diff(v.expectedIds, taskIds); diff != "" {
+				t.Error(diff)
+			}
+
+			if hasCursor := res.Header.Get("X-Next-Cursor") != ""; hasCursor != v.expectedCursor {
+				t.Errorf("expected next cursor to be %t, got %t", v.expectedCursor, hasCursor)
+			}
+		})
+	}
+
+	t.Run("GetTasks/Success (next page)", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/tasks?sort=-priority&limit=1", testServer.URL))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		res.Body.Close()
+
+		cursor := res.Header.Get("X-Next-Cursor")
+		res, err = testClient.Get(fmt.Sprintf("%s/tasks?sort=-priority&limit=1&cursor=%s", testServer.URL, cursor))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var tasks []dtos.TaskResponse
+		if err = json.NewDecoder(res.Body).Decode(&tasks); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if len(tasks) != 1 || tasks[0].Id == labeledTask.Id {
+			t.Errorf("expected the next page to continue after %s, got %v", labeledTask.Id, tasks)
+		}
+	})
+
+	updateLabelTable := []struct {
+		name           string
+		labelId        string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.LabelResponse
+	}{
+		{
+			name:           "UpdateLabel/Success",
+			labelId:        createdLabel.Id,
+			reqBody:        `{"color": "#ff0000"}`,
+			expectedStatus: http.StatusOK,
+			expectedResult: dtos.LabelResponse{
+				Id:        createdLabel.Id,
+				Name:      createdLabel.Name,
+				Color:     "#ff0000",
+				CreatedAt: createdLabel.CreatedAt,
+			},
+		},
+		{
+			name:           "UpdateLabel/Success (no update performed)",
+			labelId:        createdLabel.Id,
+			reqBody:        `{}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "UpdateLabel/Not Found",
+			labelId:        uuid.NewString(),
+			reqBody:        `{"name": "home"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range updateLabelTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/labels/%s", testServer.URL, v.labelId)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var updatedLabel dtos.LabelResponse
+				if err := json.NewDecoder(res.Body).Decode(&updatedLabel); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(v.expectedResult, updatedLabel); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+
+	deleteTable := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "DetachTaskLabel/Success",
+			path:           fmt.Sprintf("tasks/%s/labels/%s", labeledTask.Id, createdLabel.Id),
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DeleteLabel/Success",
+			path:           "labels/" + createdLabel.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DeleteLabel/Not Found",
+			path:           "labels/" + createdLabel.Id,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "DeleteTask/Success (labeled)",
+			path:           "tasks/" + labeledTask.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DeleteTask/Success (other)",
+			path:           "tasks/" + otherTask.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, v := range deleteTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/%s", testServer.URL, v.path)
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Errorf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
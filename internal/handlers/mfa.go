@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+type MFAHandler interface {
+	GetMFAStatus(res http.ResponseWriter, req *http.Request)
+	EnrollTOTP(res http.ResponseWriter, req *http.Request)
+	ConfirmTOTP(res http.ResponseWriter, req *http.Request)
+	DisableTOTP(res http.ResponseWriter, req *http.Request)
+	RegenerateRecoveryCodes(res http.ResponseWriter, req *http.Request)
+}
+
+type mfa struct {
+	configs configs.Configs
+	service services.MFAServicer
+}
+
+func NewMFAHandler(configs configs.Configs, service services.MFAServicer) MFAHandler {
+	return &mfa{
+		configs: configs,
+		service: service,
+	}
+}
+
+// sendMFAError maps the errors of second factor changes to status codes.
+func sendMFAError(res http.ResponseWriter, req *http.Request, err error) {
+	logger := log.Ctx(req.Context()).With().Logger()
+
+	if errors.Is(err, services.ErrMFANotConfigured) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("two-factor authentication not configured")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	} else if errors.Is(err, services.ErrTOTPAlreadyEnabled) || errors.Is(err, services.ErrTOTPNotEnabled) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("conflicting TOTP state")
+		http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+	} else if errors.Is(err, services.ErrMFAEmailNotVerified) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Msg("email not verified")
+		http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	} else if errors.Is(err, services.ErrInvalidMFACode) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Msg("invalid two-factor code")
+		http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	} else {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to change two-factor authentication")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func (m mfa) GetMFAStatus(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	status, err := m.service.GetMFAStatus(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get two-factor authentication status")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody: dtos.MFAStatusResponse{
+			TOTPEnabled:            status.TOTPEnabled,
+			RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+		},
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got two-factor authentication status")
+}
+
+func (m mfa) EnrollTOTP(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := m.service.EnrollTOTP(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		sendMFAError(res, req, err)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody: dtos.TOTPEnrollmentResponse{
+			Secret:     result.Secret,
+			OtpauthURI: result.URI,
+		},
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully enrolled TOTP")
+}
+
+func (m mfa) ConfirmTOTP(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.MFACodeRequest
+	if err := httputil.DecodeAndValidate(req, m.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	recoveryCodes, err := m.service.ConfirmTOTP(ctx, services.VerifySecondFactorParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		Code:     reqBody.Code,
+	})
+
+	if err != nil {
+		sendMFAError(res, req, err)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    dtos.RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully confirmed TOTP")
+}
+
+func (m mfa) DisableTOTP(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.MFACodeRequest
+	if err := httputil.DecodeAndValidate(req, m.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	err = m.service.DisableTOTP(ctx, services.VerifySecondFactorParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		Code:     reqBody.Code,
+	})
+
+	if err != nil {
+		sendMFAError(res, req, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully disabled TOTP")
+}
+
+func (m mfa) RegenerateRecoveryCodes(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.MFACodeRequest
+	if err := httputil.DecodeAndValidate(req, m.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	recoveryCodes, err := m.service.RegenerateRecoveryCodes(ctx, services.VerifySecondFactorParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		Code:     reqBody.Code,
+	})
+
+	if err != nil {
+		sendMFAError(res, req, err)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    dtos.RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully regenerated recovery codes")
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc/oidctest"
+	"github.com/mdayat/demi-masa-backend-service/internal/totp"
+)
+
+func TestMFAHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	sendMFARequest := func(t *testing.T, method, path, reqBody string) *http.Response {
+		url := fmt.Sprintf("%s/%s", testServer.URL, path)
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		return res
+	}
+
+	t.Run("ConfirmTOTP/Conflict (not enrolled)", func(t *testing.T) {
+		res := sendMFARequest(t, http.MethodPost, "users/me/mfa/totp/confirm", `{"code": "123456"}`)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, res.StatusCode)
+		}
+	})
+
+	var secret []byte
+	t.Run("EnrollTOTP/Created", func(t *testing.T) {
+		res := sendMFARequest(t, http.MethodPost, "users/me/mfa/totp", "")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		var resBody dtos.TOTPEnrollmentResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		var err error
+		secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(resBody.Secret)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+	})
+
+	var recoveryCodes []string
+	t.Run("ConfirmTOTP/Success", func(t *testing.T) {
+		reqBody := fmt.Sprintf(`{"code": %q}`, totp.Code(secret, time.Now()))
+		res := sendMFARequest(t, http.MethodPost, "users/me/mfa/totp/confirm", reqBody)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody dtos.RecoveryCodesResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if len(resBody.RecoveryCodes) != 10 {
+			t.Fatalf("expected 10 recovery codes, got %d", len(resBody.RecoveryCodes))
+		}
+		recoveryCodes = resBody.RecoveryCodes
+	})
+
+	t.Run("EnrollTOTP/Conflict (already enabled)", func(t *testing.T) {
+		res := sendMFARequest(t, http.MethodPost, "users/me/mfa/totp", "")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, res.StatusCode)
+		}
+	})
+
+	t.Run("RegenerateRecoveryCodes/Success", func(t *testing.T) {
+		reqBody := fmt.Sprintf(`{"code": %q}`, recoveryCodes[0])
+		res := sendMFARequest(t, http.MethodPost, "users/me/mfa/recovery-codes", reqBody)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody dtos.RecoveryCodesResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+		recoveryCodes = resBody.RecoveryCodes
+	})
+
+	t.Run("GetMFAStatus/Success", func(t *testing.T) {
+		res := sendMFARequest(t, http.MethodGet, "users/me/mfa", "")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody dtos.MFAStatusResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if !resBody.TOTPEnabled || resBody.RecoveryCodesRemaining != 10 {
+			t.Fatalf("unexpected status: %+v", resBody)
+		}
+	})
+
+	var mfaToken string
+	t.Run("GoogleLogin/MFA Required", func(t *testing.T) {
+		redirectURI := "http://localhost:3000/auth/google/callback"
+		code := testGoogleIssuer.IssueCode(oidctest.Identity{
+			Subject:       "google-example",
+			Email:         "example@gmail.com",
+			EmailVerified: true,
+			Name:          "Example",
+		}, "nonce", redirectURI)
+
+		res := sendMFARequest(t, http.MethodPost, "auth/google", fmt.Sprintf(`{"code": %q, "redirect_uri": %q, "nonce": "nonce"}`, code, redirectURI))
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody dtos.MFAChallengeResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if !resBody.MFARequired || resBody.MFAToken == "" {
+			t.Fatalf("expected an MFA challenge, got %+v", resBody)
+		}
+		mfaToken = resBody.MFAToken
+	})
+
+	t.Run("VerifyMFA/Success (recovery code)", func(t *testing.T) {
+		reqBody := fmt.Sprintf(`{"mfa_token": %q, "code": %q}`, mfaToken, recoveryCodes[len(recoveryCodes)-1])
+		res := sendMFARequest(t, http.MethodPost, "auth/mfa/verify", reqBody)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody dtos.AuthResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if resBody.User.Email != "example@gmail.com" || resBody.User.Role != "user" {
+			t.Fatalf("expected the user with role %q, got %+v", "user", resBody.User)
+		}
+	})
+
+	disableTOTPTable := []struct {
+		name           string
+		reqBody        func() string
+		expectedStatus int
+	}{
+		{
+			name:           "DisableTOTP/Forbidden (wrong code)",
+			reqBody:        func() string { return `{"code": "aaaa-aaaa"}` },
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "DisableTOTP/No Content",
+			reqBody:        func() string { return fmt.Sprintf(`{"code": %q}`, recoveryCodes[0]) },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DisableTOTP/Conflict (not enabled)",
+			reqBody:        func() string { return fmt.Sprintf(`{"code": %q}`, recoveryCodes[1]) },
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, v := range disableTOTPTable {
+		t.Run(v.name, func(t *testing.T) {
+			res := sendMFARequest(t, http.MethodDelete, "users/me/mfa/totp", v.reqBody())
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("VerifyMFA/Unauthorized (invalid token)", func(t *testing.T) {
+		res := sendMFARequest(t, http.MethodPost, "auth/mfa/verify", `{"mfa_token": "invalid", "code": "123456"}`)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+}
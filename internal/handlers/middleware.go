@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
@@ -20,13 +22,40 @@ type Authenticator interface {
 
 type userIdKey struct{}
 
+// sessionIdKey holds the session the access token was issued for. It is empty
+// for access tokens issued before sessions were tracked.
+type sessionIdKey struct{}
+
+// userRoleKey holds the role of the access token, see RequireRole.
+type userRoleKey struct{}
+
+// tokenScopesKey holds the scopes of the personal access token the request was
+// authenticated with, see RequireScope. It is unset for access tokens, which
+// aren't limited to scopes.
+type tokenScopesKey struct{}
+
+// authenticatePersonalAccessToken returns the context of a request
+// authenticated with a personal access token.
+func authenticatePersonalAccessToken(ctx context.Context, service services.PersonalAccessTokenServicer, tokenString string) (context.Context, error) {
+	personalAccessToken, err := service.ValidatePersonalAccessToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, userIdKey{}, personalAccessToken.UserID.String())
+	ctx = context.WithValue(ctx, tokenScopesKey{}, personalAccessToken.Scopes)
+	return ctx, nil
+}
+
 type prodAuthenticator struct {
-	authService services.AuthServicer
+	authService                services.AuthServicer
+	personalAccessTokenService services.PersonalAccessTokenServicer
 }
 
-func NewProdAuthenticator(authService services.AuthServicer) Authenticator {
+func NewProdAuthenticator(authService services.AuthServicer, personalAccessTokenService services.PersonalAccessTokenServicer) Authenticator {
 	return &prodAuthenticator{
-		authService: authService,
+		authService:                authService,
+		personalAccessTokenService: personalAccessTokenService,
 	}
 }
 
@@ -43,6 +72,23 @@ func (p prodAuthenticator) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		if strings.HasPrefix(splittedAuthHeader[1], services.PersonalAccessTokenPrefix) {
+			ctx, err := authenticatePersonalAccessToken(ctx, p.personalAccessTokenService, splittedAuthHeader[1])
+			if err != nil {
+				if errors.Is(err, services.ErrInvalidPersonalAccessToken) {
+					logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid personal access token")
+					http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				} else {
+					logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to validate personal access token")
+					http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				return
+			}
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+			return
+		}
+
 		claims, err := p.authService.ValidateAccessToken(splittedAuthHeader[1])
 		if err != nil {
 			logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid access token")
@@ -50,26 +96,51 @@ func (p prodAuthenticator) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		req = req.WithContext(context.WithValue(req.Context(), userIdKey{}, claims.Subject))
+		role := claims.Role
+		if role == "" {
+			role = services.RoleUser
+		}
+
+		ctx = context.WithValue(ctx, userIdKey{}, claims.Subject)
+		ctx = context.WithValue(ctx, sessionIdKey{}, claims.SessionID)
+		ctx = context.WithValue(ctx, userRoleKey{}, role)
+		req = req.WithContext(ctx)
 		next.ServeHTTP(res, req)
 	})
 }
 
 type testAuthenticator struct {
-	configs configs.Configs
+	configs                    configs.Configs
+	personalAccessTokenService services.PersonalAccessTokenServicer
 }
 
 func NewTestAuthenticator(configs configs.Configs) Authenticator {
 	return &testAuthenticator{
-		configs: configs,
+		configs:                    configs,
+		personalAccessTokenService: services.NewPersonalAccessTokenService(configs),
 	}
 }
 
+// Authenticate signs requests in as the test user, unless they carry a
+// personal access token, so scopes can be tested.
 func (t testAuthenticator) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		ctx := req.Context()
 		logger := log.Ctx(ctx).With().Logger()
 
+		tokenString, _ := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if strings.HasPrefix(tokenString, services.PersonalAccessTokenPrefix) {
+			ctx, err := authenticatePersonalAccessToken(ctx, t.personalAccessTokenService, tokenString)
+			if err != nil {
+				logger.Error().Err(err).Caller().Int("status_code", http.StatusUnauthorized).Msg("invalid personal access token")
+				http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+			return
+		}
+
 		testUser, err := retryutil.RetryWithData(func() (repository.User, error) {
 			return t.configs.Db.Queries.SelectUserByEmail(ctx, "example@gmail.com")
 		})
@@ -80,7 +151,9 @@ func (t testAuthenticator) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		req = req.WithContext(context.WithValue(ctx, userIdKey{}, testUser.ID.String()))
+		ctx = context.WithValue(ctx, userIdKey{}, testUser.ID.String())
+		ctx = context.WithValue(ctx, userRoleKey{}, testUser.Role)
+		req = req.WithContext(ctx)
 		next.ServeHTTP(res, req)
 	})
 }
@@ -88,6 +161,9 @@ func (t testAuthenticator) Authenticate(next http.Handler) http.Handler {
 type MiddlewareHandler interface {
 	Logger(next http.Handler) http.Handler
 	Authenticate(next http.Handler) http.Handler
+	RequireRole(roles ...string) func(next http.Handler) http.Handler
+	RequireScope(resource string) func(next http.Handler) http.Handler
+	RequireSession(next http.Handler) http.Handler
 }
 
 type middleware struct {
@@ -120,3 +196,71 @@ func (m middleware) Logger(next http.Handler) http.Handler {
 func (m middleware) Authenticate(next http.Handler) http.Handler {
 	return m.authenticator.Authenticate(next)
 }
+
+// RequireRole only lets users with one of the roles through. It goes after
+// Authenticate, which puts the role of the access token in the context.
+func (m middleware) RequireRole(roles ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			logger := log.Ctx(ctx).With().Logger()
+
+			role, _ := ctx.Value(userRoleKey{}).(string)
+			if !slices.Contains(roles, role) {
+				logger.Error().Err(fmt.Errorf("role %q isn't allowed", role)).Caller().Int("status_code", http.StatusForbidden).Send()
+				http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+// RequireScope lets personal access tokens through that have the scope of the
+// resource for the method, like tasks:read for GET and HEAD, and tasks:write
+// otherwise. Access tokens aren't limited to scopes.
+func (m middleware) RequireScope(resource string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			logger := log.Ctx(ctx).With().Logger()
+
+			scopes, ok := ctx.Value(tokenScopesKey{}).([]string)
+			if !ok {
+				next.ServeHTTP(res, req)
+				return
+			}
+
+			scope := resource + ":write"
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				scope = resource + ":read"
+			}
+
+			if !slices.Contains(scopes, scope) {
+				logger.Error().Err(fmt.Errorf("missing scope %s", scope)).Caller().Int("status_code", http.StatusForbidden).Send()
+				http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+// RequireSession rejects personal access tokens, for routes that manage the
+// account rather than its data.
+func (m middleware) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := log.Ctx(ctx).With().Logger()
+
+		if _, ok := ctx.Value(tokenScopesKey{}).([]string); ok {
+			logger.Error().Err(errors.New("personal access tokens aren't allowed")).Caller().Int("status_code", http.StatusForbidden).Send()
+			http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(res, req)
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+)
+
+func TestRequireRole(t *testing.T) {
+	customMiddleware := NewMiddlewareHandler(configs.Configs{}, nil)
+	handler := customMiddleware.RequireRole(services.RoleAdmin, services.RoleSupport)(
+		http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusNoContent)
+		}),
+	)
+
+	requireRoleTable := []struct {
+		name           string
+		role           any
+		expectedStatus int
+	}{
+		{name: "RequireRole/Allowed (admin)", role: services.RoleAdmin, expectedStatus: http.StatusNoContent},
+		{name: "RequireRole/Allowed (support)", role: services.RoleSupport, expectedStatus: http.StatusNoContent},
+		{name: "RequireRole/Forbidden (user)", role: services.RoleUser, expectedStatus: http.StatusForbidden},
+		{name: "RequireRole/Forbidden (influencer)", role: services.RoleInfluencer, expectedStatus: http.StatusForbidden},
+		{name: "RequireRole/Forbidden (unauthenticated)", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, v := range requireRoleTable {
+		t.Run(v.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if v.role != nil {
+				req = req.WithContext(context.WithValue(req.Context(), userRoleKey{}, v.role))
+			}
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.Code)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	customMiddleware := NewMiddlewareHandler(configs.Configs{}, nil)
+	handler := customMiddleware.RequireScope("tasks")(
+		http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusNoContent)
+		}),
+	)
+
+	requireScopeTable := []struct {
+		name           string
+		method         string
+		scopes         []string
+		expectedStatus int
+	}{
+		{name: "RequireScope/Allowed (access token)", method: http.MethodPost, expectedStatus: http.StatusNoContent},
+		{name: "RequireScope/Allowed (read)", method: http.MethodGet, scopes: []string{"tasks:read"}, expectedStatus: http.StatusNoContent},
+		{name: "RequireScope/Allowed (write)", method: http.MethodPut, scopes: []string{"tasks:write"}, expectedStatus: http.StatusNoContent},
+		{name: "RequireScope/Forbidden (write with read)", method: http.MethodDelete, scopes: []string{"tasks:read"}, expectedStatus: http.StatusForbidden},
+		{name: "RequireScope/Forbidden (read with write)", method: http.MethodGet, scopes: []string{"tasks:write"}, expectedStatus: http.StatusForbidden},
+		{name: "RequireScope/Forbidden (other resource)", method: http.MethodGet, scopes: []string{"lists:read"}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, v := range requireScopeTable {
+		t.Run(v.name, func(t *testing.T) {
+			req := httptest.NewRequest(v.method, "/", nil)
+			if v.scopes != nil {
+				req = req.WithContext(context.WithValue(req.Context(), tokenScopesKey{}, v.scopes))
+			}
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.Code)
+			}
+		})
+	}
+}
@@ -97,6 +97,39 @@ func (p payment) CreateInvoice(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	userId := ctx.Value(userIdKey{}).(string)
+	user, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return repository.SelectUserRow{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return p.configs.Db.Queries.SelectUser(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		logger.Error().Caller().Int("status_code", http.StatusForbidden).Msg("email not verified")
+		params := httputil.SendErrorResponseParams{
+			StatusCode: http.StatusForbidden,
+			ResBody: dtos.ErrorResponse{
+				Error:   "email_unverified",
+				Message: "verify your email address before making payments",
+			},
+		}
+
+		if err := httputil.SendErrorResponse(res, params); err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send error response")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
 	var shouldRollbackCoupon bool
 	var couponCode pgtype.Text
 
@@ -163,7 +196,6 @@ func (p payment) CreateInvoice(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	userId := ctx.Value(userIdKey{}).(string)
 	expiresAt := time.Unix(int64(tripayTxResponse.ExpiredTime), 0)
 
 	retryableFunc := func() (repository.Invoice, error) {
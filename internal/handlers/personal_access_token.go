@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type PersonalAccessTokenHandler interface {
+	GetPersonalAccessTokens(res http.ResponseWriter, req *http.Request)
+	GetPersonalAccessToken(res http.ResponseWriter, req *http.Request)
+	CreatePersonalAccessToken(res http.ResponseWriter, req *http.Request)
+	RevokePersonalAccessToken(res http.ResponseWriter, req *http.Request)
+}
+
+type personalAccessToken struct {
+	configs configs.Configs
+	service services.PersonalAccessTokenServicer
+}
+
+func NewPersonalAccessTokenHandler(configs configs.Configs, service services.PersonalAccessTokenServicer) PersonalAccessTokenHandler {
+	return &personalAccessToken{
+		configs: configs,
+		service: service,
+	}
+}
+
+func newPersonalAccessTokenResponse(personalAccessToken repository.PersonalAccessToken) dtos.PersonalAccessTokenResponse {
+	resBody := dtos.PersonalAccessTokenResponse{
+		Id:          personalAccessToken.ID.String(),
+		Name:        personalAccessToken.Name,
+		TokenPrefix: personalAccessToken.TokenPrefix,
+		Scopes:      personalAccessToken.Scopes,
+		CreatedAt:   personalAccessToken.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	if personalAccessToken.ExpiresAt.Valid {
+		resBody.ExpiresAt = personalAccessToken.ExpiresAt.Time.Format(time.RFC3339)
+	}
+
+	if personalAccessToken.LastUsedAt.Valid {
+		resBody.LastUsedAt = personalAccessToken.LastUsedAt.Time.Format(time.RFC3339)
+	}
+
+	return resBody
+}
+
+func (p personalAccessToken) GetPersonalAccessTokens(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	personalAccessTokens, err := retryutil.RetryWithData(func() ([]repository.PersonalAccessToken, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return p.configs.Db.Queries.SelectUserPersonalAccessTokens(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user personal access tokens")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := make([]dtos.PersonalAccessTokenResponse, 0, len(personalAccessTokens))
+	for _, personalAccessToken := range personalAccessTokens {
+		resBody = append(resBody, newPersonalAccessTokenResponse(personalAccessToken))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got personal access tokens")
+}
+
+func (p personalAccessToken) GetPersonalAccessToken(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	tokenId := chi.URLParam(req, "tokenId")
+	tokenUUID, err := uuid.Parse(tokenId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("personal access token not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	personalAccessToken, err := retryutil.RetryWithData(func() (repository.PersonalAccessToken, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return repository.PersonalAccessToken{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return p.configs.Db.Queries.SelectUserPersonalAccessToken(ctx, repository.SelectUserPersonalAccessTokenParams{
+			ID:     pgtype.UUID{Bytes: tokenUUID, Valid: true},
+			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("personal access token not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user personal access token")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newPersonalAccessTokenResponse(personalAccessToken),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got personal access token")
+}
+
+func (p personalAccessToken) CreatePersonalAccessToken(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.PersonalAccessTokenRequest
+	if err := httputil.DecodeAndValidate(req, p.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var expiresAt time.Time
+	if reqBody.ExpiresInDays > 0 {
+		expiresAt = time.Now().AddDate(0, 0, reqBody.ExpiresInDays)
+	}
+
+	result, err := p.service.CreatePersonalAccessToken(ctx, services.CreatePersonalAccessTokenParams{
+		UserUUID:  pgtype.UUID{Bytes: userUUID, Valid: true},
+		Name:      reqBody.Name,
+		Scopes:    reqBody.Scopes,
+		ExpiresAt: expiresAt,
+	})
+
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyPersonalAccessTokens) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Send()
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to create personal access token")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := newPersonalAccessTokenResponse(result.PersonalAccessToken)
+	resBody.Token = result.Token
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/users/me/tokens/%s", p.configs.Env.OriginURL, resBody.Id))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created personal access token")
+}
+
+func (p personalAccessToken) RevokePersonalAccessToken(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	tokenId := chi.URLParam(req, "tokenId")
+	tokenUUID, err := uuid.Parse(tokenId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("personal access token not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return p.configs.Db.Queries.DeleteUserPersonalAccessToken(ctx, repository.DeleteUserPersonalAccessTokenParams{
+			ID:     pgtype.UUID{Bytes: tokenUUID, Valid: true},
+			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to delete user personal access token")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("personal access token not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully revoked personal access token")
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+)
+
+func TestPersonalAccessTokenHandlers(t *testing.T) {
+	ctx := context.TODO()
+	var createdToken dtos.PersonalAccessTokenResponse
+
+	createTokenTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.PersonalAccessTokenResponse
+	}{
+		{
+			name:           "CreatePersonalAccessToken/Success",
+			reqBody:        `{"name": "calendar sync", "scopes": ["tasks:read"], "expires_in_days": 30}`,
+			expectedStatus: http.StatusCreated,
+			expectedResult: dtos.PersonalAccessTokenResponse{Name: "calendar sync", Scopes: []string{"tasks:read"}},
+		},
+		{
+			name:           "CreatePersonalAccessToken/Bad Request (scope)",
+			reqBody:        `{"name": "admin", "scopes": ["coupons:write"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CreatePersonalAccessToken/Bad Request (no scopes)",
+			reqBody:        `{"name": "nothing", "scopes": []}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range createTokenTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/users/me/tokens", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				if err := json.NewDecoder(res.Body).Decode(&createdToken); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				ignoredFields := cmpopts.IgnoreFields(dtos.PersonalAccessTokenResponse{}, "Id", "TokenPrefix", "ExpiresAt", "CreatedAt", "Token")
+				if diff := cmp.Diff(v.expectedResult, createdToken, ignoredFields); diff != "" {
+					t.Error(diff)
+				}
+
+				if !strings.HasPrefix(createdToken.Token, createdToken.TokenPrefix) || !strings.HasPrefix(createdToken.Token, services.PersonalAccessTokenPrefix) {
+					t.Errorf("unexpected token %q with prefix %q", createdToken.Token, createdToken.TokenPrefix)
+				}
+
+				if createdToken.ExpiresAt == "" {
+					t.Error("expected the token to expire")
+				}
+			}
+		})
+	}
+
+	t.Run("GetPersonalAccessTokens/Success", func(t *testing.T) {
+		url := fmt.Sprintf("%s/users/me/tokens", testServer.URL)
+		res, err := testClient.Get(url)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var tokens []dtos.PersonalAccessTokenResponse
+		if err := json.NewDecoder(res.Body).Decode(&tokens); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if len(tokens) == 0 || tokens[0].Id != createdToken.Id || tokens[0].Token != "" {
+			t.Errorf("expected the created token without its secret, got: %+v", tokens)
+		}
+	})
+
+	getTokenTable := []struct {
+		name           string
+		tokenId        func() string
+		expectedStatus int
+	}{
+		{
+			name:           "GetPersonalAccessToken/Success",
+			tokenId:        func() string { return createdToken.Id },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GetPersonalAccessToken/Not Found",
+			tokenId:        func() string { return "00000000-0000-0000-0000-000000000000" },
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range getTokenTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/users/me/tokens/%s", testServer.URL, v.tokenId())
+			res, err := testClient.Get(url)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var token dtos.PersonalAccessTokenResponse
+			if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			if token.Id != createdToken.Id || token.Name != createdToken.Name || token.Token != "" {
+				t.Errorf("expected the created token without its secret, got: %+v", token)
+			}
+		})
+	}
+
+	authorizedTable := []struct {
+		name           string
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "GetTasks/Success (tasks:read)",
+			method:         http.MethodGet,
+			path:           "/tasks",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "CreateTask/Forbidden (tasks:write)",
+			method:         http.MethodPost,
+			path:           "/tasks",
+			reqBody:        `{"name": "from integration", "description": "description", "priority": 1}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "GetLabels/Forbidden (labels:read)",
+			method:         http.MethodGet,
+			path:           "/labels",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "GetPersonalAccessTokens/Forbidden (session only)",
+			method:         http.MethodGet,
+			path:           "/users/me/tokens",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, v := range authorizedTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s%s", testServer.URL, v.path)
+			req, err := http.NewRequestWithContext(ctx, v.method, url, strings.NewReader(v.reqBody))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+createdToken.Token)
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	revokeTokenTable := []struct {
+		name           string
+		tokenId        string
+		expectedStatus int
+	}{
+		{
+			name:           "RevokePersonalAccessToken/Success",
+			tokenId:        createdToken.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "RevokePersonalAccessToken/Not Found",
+			tokenId:        createdToken.Id,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range revokeTokenTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/users/me/tokens/%s", testServer.URL, v.tokenId)
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("GetTasks/Unauthorized (revoked)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks", testServer.URL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+createdToken.Token)
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+}
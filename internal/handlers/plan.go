@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -20,6 +21,9 @@ import (
 type PlanHandler interface {
 	GetPlans(res http.ResponseWriter, req *http.Request)
 	GetPlan(res http.ResponseWriter, req *http.Request)
+	CreatePlan(res http.ResponseWriter, req *http.Request)
+	UpdatePlan(res http.ResponseWriter, req *http.Request)
+	DeletePlan(res http.ResponseWriter, req *http.Request)
 }
 
 type plan struct {
@@ -121,3 +125,170 @@ func (p plan) GetPlan(res http.ResponseWriter, req *http.Request) {
 
 	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got plan")
 }
+
+func (p plan) CreatePlan(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.PlanRequest
+	if err := httputil.DecodeAndValidate(req, p.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := retryutil.RetryWithData(func() (repository.Plan, error) {
+		return p.configs.Db.Queries.InsertPlan(ctx, repository.InsertPlanParams{
+			ID:               pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Type:             reqBody.Type,
+			Name:             reqBody.Name,
+			Price:            reqBody.Price,
+			DurationInMonths: reqBody.DurationInMonths,
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to insert plan")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := dtos.PlanResponse{
+		Id:               plan.ID.String(),
+		Type:             plan.Type,
+		Name:             plan.Name,
+		Price:            plan.Price,
+		DurationInMonths: plan.DurationInMonths,
+		CreatedAt:        plan.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/plans/%s", p.configs.Env.OriginURL, resBody.Id))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created plan")
+}
+
+func (p plan) UpdatePlan(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	planId := chi.URLParam(req, "planId")
+	planUUID, err := uuid.Parse(planId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("plan not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	var reqBody dtos.UpdatePlanRequest
+	if err := httputil.DecodeAndValidate(req, p.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if reqBody.Name == "" && reqBody.Price == nil && reqBody.DurationInMonths == nil {
+		res.WriteHeader(http.StatusNoContent)
+		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
+		return
+	}
+
+	var name pgtype.Text
+	if reqBody.Name != "" {
+		name = pgtype.Text{String: reqBody.Name, Valid: true}
+	}
+
+	var price pgtype.Int4
+	if reqBody.Price != nil {
+		price = pgtype.Int4{Int32: *reqBody.Price, Valid: true}
+	}
+
+	var durationInMonths pgtype.Int2
+	if reqBody.DurationInMonths != nil {
+		durationInMonths = pgtype.Int2{Int16: *reqBody.DurationInMonths, Valid: true}
+	}
+
+	plan, err := retryutil.RetryWithData(func() (repository.Plan, error) {
+		return p.configs.Db.Queries.UpdatePlan(ctx, repository.UpdatePlanParams{
+			ID:               pgtype.UUID{Bytes: planUUID, Valid: true},
+			Name:             name,
+			Price:            price,
+			DurationInMonths: durationInMonths,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("plan not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update plan")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := dtos.PlanResponse{
+		Id:               plan.ID.String(),
+		Type:             plan.Type,
+		Name:             plan.Name,
+		Price:            plan.Price,
+		DurationInMonths: plan.DurationInMonths,
+		CreatedAt:        plan.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated plan")
+}
+
+// DeletePlan soft deletes the plan, so subscriptions to it keep their plan.
+func (p plan) DeletePlan(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	planId := chi.URLParam(req, "planId")
+	planUUID, err := uuid.Parse(planId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("plan not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		return p.configs.Db.Queries.DeletePlan(ctx, pgtype.UUID{Bytes: planUUID, Valid: true})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to delete plan")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("plan not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted plan")
+}
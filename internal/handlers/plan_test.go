@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestPlanAdminHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	sendPlanRequest := func(t *testing.T, method, path, reqBody string) *http.Response {
+		url := fmt.Sprintf("%s/%s", testServer.URL, path)
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		return res
+	}
+
+	var createdPlan dtos.PlanResponse
+	createPlanTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "CreatePlan/Bad Request (type)",
+			reqBody:        `{"type": "free", "name": "yearly", "price": 1000000, "duration_in_months": 12}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CreatePlan/Created",
+			reqBody:        `{"type": "premium", "name": "yearly", "price": 1000000, "duration_in_months": 12}`,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, v := range createPlanTable {
+		t.Run(v.name, func(t *testing.T) {
+			res := sendPlanRequest(t, http.MethodPost, "plans", v.reqBody)
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				if err := json.NewDecoder(res.Body).Decode(&createdPlan); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+			}
+		})
+	}
+
+	t.Run("UpdatePlan/Success", func(t *testing.T) {
+		res := sendPlanRequest(t, http.MethodPut, "plans/"+createdPlan.Id, `{"price": 900000}`)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var resBody dtos.PlanResponse
+		if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		expectedPlan := dtos.PlanResponse{Type: "premium", Name: "yearly", Price: 900000, DurationInMonths: 12}
+		if diff := cmp.Diff(expectedPlan, resBody, cmpopts.IgnoreFields(dtos.PlanResponse{}, "Id", "CreatedAt")); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	deletePlanTable := []struct {
+		name           string
+		method         string
+		expectedStatus int
+	}{
+		{name: "DeletePlan/No Content", method: http.MethodDelete, expectedStatus: http.StatusNoContent},
+		{name: "DeletePlan/Not Found (already deleted)", method: http.MethodDelete, expectedStatus: http.StatusNotFound},
+		{name: "GetPlan/Not Found (deleted)", method: http.MethodGet, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, v := range deletePlanTable {
+		t.Run(v.name, func(t *testing.T) {
+			res := sendPlanRequest(t, v.method, "plans/"+createdPlan.Id, "")
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+type PlannerHandler interface {
+	GetPlanner(res http.ResponseWriter, req *http.Request)
+}
+
+type planner struct {
+	configs configs.Configs
+	service services.PlannerServicer
+}
+
+func NewPlannerHandler(configs configs.Configs, service services.PlannerServicer) PlannerHandler {
+	return &planner{
+		configs: configs,
+		service: service,
+	}
+}
+
+func newPlannerResponse(plan services.DayPlan) dtos.PlannerResponse {
+	resBody := dtos.PlannerResponse{
+		Date:        plan.Date.Format(time.DateOnly),
+		DayStartsAt: plan.DayStartsAt.Format(time.RFC3339),
+		DayEndsAt:   plan.DayEndsAt.Format(time.RFC3339),
+		Prayers:     make([]dtos.PlannedPrayerResponse, 0, len(plan.Prayers)),
+		Tasks:       make([]dtos.PlannedTaskResponse, 0, len(plan.Tasks)),
+		Unscheduled: make([]dtos.UnscheduledTaskResponse, 0, len(plan.Unscheduled)),
+	}
+
+	for _, prayer := range plan.Prayers {
+		resBody.Prayers = append(resBody.Prayers, dtos.PlannedPrayerResponse{
+			Name:     prayer.Name,
+			StartsAt: prayer.StartsAt.Format(time.RFC3339),
+			EndsAt:   prayer.EndsAt.Format(time.RFC3339),
+		})
+	}
+
+	for _, plannedTask := range plan.Tasks {
+		resBody.Tasks = append(resBody.Tasks, dtos.PlannedTaskResponse{
+			StartsAt: plannedTask.StartsAt.Format(time.RFC3339),
+			EndsAt:   plannedTask.EndsAt.Format(time.RFC3339),
+			Task:     newTaskResponse(plannedTask.Task, taskExtras{}),
+		})
+	}
+
+	for _, unscheduledTask := range plan.Unscheduled {
+		resBody.Unscheduled = append(resBody.Unscheduled, dtos.UnscheduledTaskResponse{
+			DurationInMinutes: unscheduledTask.DurationInMinutes,
+			Reason:            unscheduledTask.Reason,
+			Task:              newTaskResponse(unscheduledTask.Task, taskExtras{}),
+		})
+	}
+
+	return resBody
+}
+
+func (p planner) GetPlanner(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := services.PlanDayParams{UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true}}
+	if dateString := req.URL.Query().Get("date"); dateString != "" {
+		date, err := time.Parse(time.DateOnly, dateString)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid date query param")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		params.Date = date
+	}
+
+	plan, err := p.service.PlanDay(ctx, params)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to plan day")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	successParams := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newPlannerResponse(plan),
+	}
+
+	if err := httputil.SendSuccessResponse(res, successParams); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got planner")
+}
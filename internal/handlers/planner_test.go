@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestPlannerHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	var fittingTask, longTask, recurringTask, anchoredTask dtos.TaskResponse
+	createTable := []struct {
+		name    string
+		reqBody string
+		result  *dtos.TaskResponse
+	}{
+		{
+			name:    "CreateTask/Success (fitting)",
+			reqBody: `{"name": "write report", "description": "", "due_at": "2099-01-01T12:00:00Z", "estimated_duration_in_minutes": 45}`,
+			result:  &fittingTask,
+		},
+		{
+			name:    "CreateTask/Success (too long)",
+			reqBody: `{"name": "move house", "description": "", "due_at": "2099-01-01T12:00:00Z", "estimated_duration_in_minutes": 720}`,
+			result:  &longTask,
+		},
+		{
+			name:    "CreateTask/Success (recurring)",
+			reqBody: `{"name": "daily review", "description": "", "recurrence": {"rule": "FREQ=DAILY", "starts_on": "2098-12-01"}, "estimated_duration_in_minutes": 30}`,
+			result:  &recurringTask,
+		},
+		{
+			name:    "CreateTask/Success (anchored)",
+			reqBody: `{"name": "read quran", "description": "", "anchor": {"prayer": "asar", "relation": "after", "offset_in_minutes": 30}, "estimated_duration_in_minutes": 20}`,
+			result:  &anchoredTask,
+		},
+	}
+
+	for _, v := range createTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+			}
+
+			if err := json.NewDecoder(res.Body).Decode(v.result); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+		})
+	}
+
+	planTable := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{
+			name:           "GetPlanner/Success",
+			query:          "?date=2099-01-01",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GetPlanner/Bad Request",
+			query:          "?date=01-01-2099",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range planTable {
+		t.Run(v.name, func(t *testing.T) {
+			res, err := testClient.Get(fmt.Sprintf("%s/planner%s", testServer.URL, v.query))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var plan dtos.PlannerResponse
+			if err := json.NewDecoder(res.Body).Decode(&plan); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			if len(plan.Prayers) != 5 {
+				t.Errorf("expected 5 prayers, got %d", len(plan.Prayers))
+			}
+
+			plannedTasks := make(map[string]dtos.PlannedTaskResponse, len(plan.Tasks))
+			for _, plannedTask := range plan.Tasks {
+				plannedTasks[plannedTask.Task.Id] = plannedTask
+			}
+
+			for _, createdTask := range []dtos.TaskResponse{fittingTask, recurringTask, anchoredTask} {
+				if _, ok := plannedTasks[createdTask.Id]; !ok {
+					t.Errorf("expected task %s to be planned, got %+v", createdTask.Name, plan.Tasks)
+				}
+			}
+
+			var asar, magrib dtos.PlannedPrayerResponse
+			for _, prayer := range plan.Prayers {
+				switch prayer.Name {
+				case "asar":
+					asar = prayer
+				case "magrib":
+					magrib = prayer
+				}
+			}
+
+			asarStartsAt, _ := time.Parse(time.RFC3339, asar.StartsAt)
+			magribStartsAt, _ := time.Parse(time.RFC3339, magrib.StartsAt)
+			anchoredStartsAt, _ := time.Parse(time.RFC3339, plannedTasks[anchoredTask.Id].StartsAt)
+			if anchoredStartsAt.Before(asarStartsAt.Add(30*time.Minute)) || !anchoredStartsAt.Before(magribStartsAt) {
+				t.Errorf("expected task %s to be planned after asar, got %s", anchoredTask.Name, anchoredStartsAt)
+			}
+
+			if _, ok := plannedTasks[longTask.Id]; ok {
+				t.Errorf("expected task %s not to be planned", longTask.Name)
+			}
+
+			var unscheduled bool
+			for _, unscheduledTask := range plan.Unscheduled {
+				if unscheduledTask.Task.Id == longTask.Id && unscheduledTask.Reason == "no_free_slot" {
+					unscheduled = true
+				}
+			}
+
+			if !unscheduled {
+				t.Errorf("expected task %s to be unscheduled, got %+v", longTask.Name, plan.Unscheduled)
+			}
+		})
+	}
+
+	for _, createdTask := range []dtos.TaskResponse{fittingTask, longTask, recurringTask, anchoredTask} {
+		t.Run(fmt.Sprintf("DeleteTask/Success (%s)", createdTask.Name), func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s", testServer.URL, createdTask.Id)
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusNoContent {
+				t.Fatalf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+			}
+		})
+	}
+}
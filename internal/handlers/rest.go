@@ -24,8 +24,8 @@ func NewRestHandler(configs configs.Configs, customMiddleware MiddlewareHandler)
 	options := cors.Options{
 		AllowedOrigins:   strings.Split(configs.Env.AllowedOrigins, ","),
 		AllowedMethods:   []string{"GET", "PUT", "POST", "DELETE", "HEAD", "OPTIONS"},
-		AllowedHeaders:   []string{"User-Agent", "Content-Type", "Accept", "Accept-Encoding", "Accept-Language", "Cache-Control", "Connection", "Host", "Origin", "Referer", "Authorization"},
-		ExposedHeaders:   []string{"Content-Length", "Location"},
+		AllowedHeaders:   []string{"User-Agent", "Content-Type", "Accept", "Accept-Encoding", "Accept-Language", "Cache-Control", "Connection", "Host", "Origin", "Referer", "Authorization", "X-Refresh-Token-Mode", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Content-Length", "Location", "X-Next-Cursor", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}
@@ -36,26 +36,54 @@ func NewRestHandler(configs configs.Configs, customMiddleware MiddlewareHandler)
 	authHandler := NewAuthHandler(configs, authService)
 	router.Post("/auth/register", authHandler.Register)
 	router.Post("/auth/login", authHandler.Login)
+	router.Post("/auth/google", authHandler.LoginWithGoogle)
+	router.Post("/auth/mfa/verify", authHandler.VerifyMFA)
 	router.Post("/auth/logout", authHandler.Logout)
 	router.Get("/auth/refresh", authHandler.Refresh)
+	router.Post("/auth/verify-email", authHandler.VerifyEmail)
+	router.Post("/auth/password/forgot", authHandler.ForgotPassword)
+	router.Post("/auth/password/reset", authHandler.ResetPassword)
+	router.Get("/.well-known/jwks.json", authHandler.GetJWKS)
 
 	paymentService := services.NewPaymentService(configs)
 	paymentHandler := NewPaymentHandler(configs, paymentService)
 	router.Post("/payments/callback", paymentHandler.TripayCallback)
 
+	userService := services.NewUserService(configs)
+	userHandler := NewUserHandler(configs, userService)
+	entitlementService := services.NewEntitlementService(configs)
+
+	// Routes that manage the account, which personal access tokens can't use.
 	router.Group(func(r chi.Router) {
 		r.Use(customMiddleware.Authenticate)
+		r.Use(customMiddleware.RequireSession)
+		admin := r.With(customMiddleware.RequireRole(services.RoleAdmin))
 
-		userService := services.NewUserService(configs)
-		userHandler := NewUserHandler(configs, userService)
-		r.Get("/users/me", userHandler.GetUser)
 		r.Delete("/users/me", userHandler.DeleteUser)
 		r.Put("/users/me", userHandler.UpdateUser)
+		r.Put("/users/me/password", userHandler.ChangePassword)
+		admin.Put("/users/{userId}/role", userHandler.UpdateUserRole)
+		r.Post("/auth/verify-email/resend", authHandler.ResendEmailVerification)
+
+		mfaService := services.NewMFAService(configs)
+		mfaHandler := NewMFAHandler(configs, mfaService)
+		r.Get("/users/me/mfa", mfaHandler.GetMFAStatus)
+		r.Post("/users/me/mfa/totp", mfaHandler.EnrollTOTP)
+		r.Post("/users/me/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+		r.Delete("/users/me/mfa/totp", mfaHandler.DisableTOTP)
+		r.Post("/users/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+		personalAccessTokenService := services.NewPersonalAccessTokenService(configs)
+		personalAccessTokenHandler := NewPersonalAccessTokenHandler(configs, personalAccessTokenService)
+		r.Get("/users/me/tokens", personalAccessTokenHandler.GetPersonalAccessTokens)
+		r.Post("/users/me/tokens", personalAccessTokenHandler.CreatePersonalAccessToken)
+		r.Get("/users/me/tokens/{tokenId}", personalAccessTokenHandler.GetPersonalAccessToken)
+		r.Delete("/users/me/tokens/{tokenId}", personalAccessTokenHandler.RevokePersonalAccessToken)
 
-		prayerService := services.NewPrayerService(configs)
-		prayerHandler := NewPrayerHandler(configs, prayerService)
-		r.Get("/prayers", prayerHandler.GetPrayers)
-		r.Put("/prayers/{prayerId}", prayerHandler.UpdatePrayer)
+		sessionHandler := NewSessionHandler(configs)
+		r.Get("/sessions", sessionHandler.GetSessions)
+		r.Delete("/sessions", sessionHandler.RevokeOtherSessions)
+		r.Delete("/sessions/{sessionId}", sessionHandler.RevokeSession)
 
 		r.Get("/invoices/active", paymentHandler.GetActiveInvoice)
 		r.Post("/invoices", paymentHandler.CreateInvoice)
@@ -64,15 +92,117 @@ func NewRestHandler(configs configs.Configs, customMiddleware MiddlewareHandler)
 		planHandler := NewPlanHandler(configs)
 		r.Get("/plans", planHandler.GetPlans)
 		r.Get("/plans/{planId}", planHandler.GetPlan)
-
-		taskHandler := NewTaskHandler(configs)
-		r.Get("/tasks", taskHandler.GetTasks)
-		r.Post("/tasks", taskHandler.CreateTask)
-		r.Put("/tasks/{taskId}", taskHandler.UpdateTask)
-		r.Delete("/tasks/{taskId}", taskHandler.DeleteTask)
+		admin.Post("/plans", planHandler.CreatePlan)
+		admin.Put("/plans/{planId}", planHandler.UpdatePlan)
+		admin.Delete("/plans/{planId}", planHandler.DeletePlan)
 
 		couponHandler := NewCouponHandler(configs)
 		r.Get("/coupons/{couponCode}", couponHandler.GetCoupon)
+		r.With(customMiddleware.RequireRole(services.RoleAdmin, services.RoleSupport)).Get("/coupons", couponHandler.GetCoupons)
+		admin.Post("/coupons", couponHandler.CreateCoupon)
+		admin.Put("/coupons/{couponCode}", couponHandler.UpdateCoupon)
+		admin.Delete("/coupons/{couponCode}", couponHandler.DeleteCoupon)
+	})
+
+	// Routes of the data of the account. Personal access tokens can use them
+	// with the scope of the resource, see RequireScope.
+	router.Group(func(r chi.Router) {
+		r.Use(customMiddleware.Authenticate)
+
+		r.With(customMiddleware.RequireScope("user")).Get("/users/me", userHandler.GetUser)
+
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireScope("prayers"))
+
+			prayerService := services.NewPrayerService(configs)
+			prayerHandler := NewPrayerHandler(configs, prayerService)
+			r.Get("/prayers", prayerHandler.GetPrayers)
+			r.Put("/prayers/{prayerId}", prayerHandler.UpdatePrayer)
+		})
+
+		labelHandler := NewLabelHandler(configs)
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireScope("tasks"))
+
+			taskService := services.NewTaskService(configs)
+			taskHandler := NewTaskHandler(configs, taskService, entitlementService)
+			r.Get("/tasks", taskHandler.GetTasks)
+			r.Post("/tasks", taskHandler.CreateTask)
+			r.Get("/tasks/trash", taskHandler.GetTrashedTasks)
+			r.Get("/tasks/stats", taskHandler.GetTaskStats)
+			r.Post("/tasks/bulk", taskHandler.BulkTasks)
+			r.Put("/tasks/{taskId}", taskHandler.UpdateTask)
+			r.Delete("/tasks/{taskId}", taskHandler.DeleteTask)
+			r.Post("/tasks/{taskId}/move", taskHandler.MoveTask)
+			r.Post("/tasks/{taskId}/restore", taskHandler.RestoreTask)
+			r.Get("/tasks/{taskId}/occurrences", taskHandler.GetTaskOccurrences)
+			r.Put("/tasks/{taskId}/occurrences/{date}", taskHandler.UpdateTaskOccurrence)
+			r.Post("/tasks/{taskId}/occurrences/{date}/skip", taskHandler.SkipTaskOccurrence)
+
+			taskItemService := services.NewTaskItemService(configs)
+			taskItemHandler := NewTaskItemHandler(configs, taskItemService)
+			r.Get("/tasks/{taskId}/items", taskItemHandler.GetTaskItems)
+			r.Post("/tasks/{taskId}/items", taskItemHandler.CreateTaskItem)
+			r.Put("/tasks/{taskId}/items/order", taskItemHandler.ReorderTaskItems)
+			r.Put("/tasks/{taskId}/items/{itemId}", taskItemHandler.UpdateTaskItem)
+			r.Delete("/tasks/{taskId}/items/{itemId}", taskItemHandler.DeleteTaskItem)
+
+			r.Put("/tasks/{taskId}/labels/{labelId}", labelHandler.AttachTaskLabel)
+			r.Delete("/tasks/{taskId}/labels/{labelId}", labelHandler.DetachTaskLabel)
+
+			searchHandler := NewSearchHandler(configs)
+			r.Get("/search", searchHandler.Search)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireScope("labels"))
+
+			r.Get("/labels", labelHandler.GetLabels)
+			r.Post("/labels", labelHandler.CreateLabel)
+			r.Put("/labels/{labelId}", labelHandler.UpdateLabel)
+			r.Delete("/labels/{labelId}", labelHandler.DeleteLabel)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireScope("lists"))
+
+			taskListService := services.NewTaskListService(configs)
+			taskListHandler := NewTaskListHandler(configs, taskListService, entitlementService)
+			r.Get("/lists", taskListHandler.GetTaskLists)
+			r.Post("/lists", taskListHandler.CreateTaskList)
+			r.Delete("/lists/{listId}", taskListHandler.DeleteTaskList)
+			r.Get("/lists/{listId}/members", taskListHandler.GetTaskListMembers)
+			r.Post("/lists/{listId}/members", taskListHandler.InviteTaskListMember)
+			r.Delete("/lists/{listId}/members/{userId}", taskListHandler.RemoveTaskListMember)
+			r.Get("/lists/{listId}/tasks", taskListHandler.GetTaskListTasks)
+			r.Post("/lists/{listId}/tasks", taskListHandler.CreateTaskListTask)
+			r.Put("/lists/{listId}/tasks/{taskId}", taskListHandler.UpdateTaskListTask)
+			r.Delete("/lists/{listId}/tasks/{taskId}", taskListHandler.DeleteTaskListTask)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireScope("focus"))
+
+			focusService := services.NewFocusService(configs)
+			focusHandler := NewFocusHandler(configs, focusService, entitlementService)
+			r.Post("/focus-sessions", focusHandler.StartFocusSession)
+			r.Get("/focus-sessions/active", focusHandler.GetActiveFocusSession)
+			r.Get("/focus-sessions/totals", focusHandler.GetFocusTotals)
+			r.Get("/focus-sessions/{sessionId}", focusHandler.GetFocusSession)
+			r.Post("/focus-sessions/{sessionId}/pause", focusHandler.PauseFocusSession)
+			r.Post("/focus-sessions/{sessionId}/resume", focusHandler.ResumeFocusSession)
+			r.Post("/focus-sessions/{sessionId}/finish", focusHandler.FinishFocusSession)
+			r.Get("/focus-preferences", focusHandler.GetFocusPreference)
+			r.Put("/focus-preferences", focusHandler.UpdateFocusPreference)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireScope("planner"))
+
+			plannerService := services.NewPlannerService(configs)
+			plannerHandler := NewPlannerHandler(configs, plannerService)
+			r.Get("/planner", plannerHandler.GetPlanner)
+		})
 	})
 
 	return router
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type SearchHandler interface {
+	Search(res http.ResponseWriter, req *http.Request)
+}
+
+type search struct {
+	configs configs.Configs
+}
+
+func NewSearchHandler(configs configs.Configs) SearchHandler {
+	return &search{
+		configs: configs,
+	}
+}
+
+const (
+	maxSearchQueryLength = 256
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 50
+)
+
+// Only tasks are searched, since there are no journal or prayer notes stored
+// yet. Results carry their type so notes can join the same endpoint later.
+const searchResultTypeTask = "task"
+
+func (s search) Search(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	query := strings.TrimSpace(req.URL.Query().Get("q"))
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		logger.Error().Caller().Int("status_code", http.StatusBadRequest).Msg("invalid q query params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if limitString := req.URL.Query().Get("limit"); limitString != "" {
+		var err error
+		limit, err = strconv.Atoi(limitString)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid limit query params")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	rows, err := retryutil.RetryWithData(func() ([]repository.SearchUserTasksRow, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return s.configs.Db.Queries.SearchUserTasks(ctx, repository.SearchUserTasksParams{
+			UserID:   pgtype.UUID{Bytes: userUUID, Valid: true},
+			Query:    query,
+			RowLimit: int32(limit),
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to search user tasks")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := make([]dtos.SearchResultResponse, 0, len(rows))
+	for _, row := range rows {
+		resBody = append(resBody, dtos.SearchResultResponse{
+			Type:    searchResultTypeTask,
+			Id:      row.ID.String(),
+			Title:   row.NameHighlight,
+			Snippet: row.DescriptionHighlight,
+			Rank:    row.Rank,
+		})
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully searched")
+}
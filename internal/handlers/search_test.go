@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestSearchHandlers(t *testing.T) {
+	ctx := context.TODO()
+	var createdTask dtos.TaskResponse
+
+	t.Run("CreateTask/Success (searchable)", func(t *testing.T) {
+		reqBody := `{"name": "Membaca buku tafsir <b>penting</b>", "description": "Lanjutkan bacaan surah Al-Kahfi setelah meeting tim"}`
+		res, err := testClient.Post(fmt.Sprintf("%s/tasks", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&createdTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+	})
+
+	searchTable := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedFound  bool
+	}{
+		{
+			name:           "Search/Success (indonesian)",
+			query:          "?q=" + url.QueryEscape("buku"),
+			expectedStatus: http.StatusOK,
+			expectedFound:  true,
+		},
+		{
+			name:           "Search/Success (english stem)",
+			query:          "?q=" + url.QueryEscape("meetings"),
+			expectedStatus: http.StatusOK,
+			expectedFound:  true,
+		},
+		{
+			name:           "Search/Success (no match)",
+			query:          "?q=" + url.QueryEscape("olahraga"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Search/Bad Request (empty)",
+			query:          "?q=",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Search/Bad Request (limit)",
+			query:          "?q=buku&limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range searchTable {
+		t.Run(v.name, func(t *testing.T) {
+			res, err := testClient.Get(fmt.Sprintf("%s/search%s", testServer.URL, v.query))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var results []dtos.SearchResultResponse
+			if err := json.NewDecoder(res.Body).Decode(&results); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			var found bool
+			for _, result := range results {
+				if result.Id != createdTask.Id {
+					continue
+				}
+
+				found = true
+				if result.Type != "task" || !strings.Contains(result.Title+result.Snippet, "<mark>") {
+					t.Errorf("expected a highlighted task result, got %+v", result)
+				}
+
+				if strings.Contains(result.Title, "<b>") || !strings.Contains(result.Title, "&lt;b&gt;") {
+					t.Errorf("expected the markup of the task to be escaped, got %q", result.Title)
+				}
+			}
+
+			if found != v.expectedFound {
+				t.Errorf("expected found to be %t, got %t", v.expectedFound, found)
+			}
+		})
+	}
+
+	t.Run("DeleteTask/Success (searchable)", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/tasks/%s", testServer.URL, createdTask.Id), nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// A session is a family of refresh tokens, identified by the family Id. Only
+// the latest token of a family is active.
+type SessionHandler interface {
+	GetSessions(res http.ResponseWriter, req *http.Request)
+	RevokeSession(res http.ResponseWriter, req *http.Request)
+	RevokeOtherSessions(res http.ResponseWriter, req *http.Request)
+}
+
+type session struct {
+	configs configs.Configs
+}
+
+func NewSessionHandler(configs configs.Configs) SessionHandler {
+	return &session{
+		configs: configs,
+	}
+}
+
+func newSessionClient(req *http.Request, deviceName string) services.SessionClient {
+	ipAddress, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ipAddress = req.RemoteAddr
+	}
+
+	return services.SessionClient{
+		DeviceName: deviceName,
+		UserAgent:  req.UserAgent(),
+		IPAddress:  ipAddress,
+	}
+}
+
+func newSessionResponse(refreshToken repository.RefreshToken, currentSessionId string) dtos.SessionResponse {
+	sessionId := refreshToken.FamilyID.String()
+	return dtos.SessionResponse{
+		Id:         sessionId,
+		DeviceName: refreshToken.DeviceName,
+		UserAgent:  refreshToken.UserAgent,
+		IpAddress:  refreshToken.IpAddress,
+		Current:    sessionId == currentSessionId,
+		CreatedAt:  refreshToken.CreatedAt.Time.Format(time.RFC3339),
+		LastUsedAt: refreshToken.LastUsedAt.Time.Format(time.RFC3339),
+	}
+}
+
+func (s session) GetSessions(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	sessionId, _ := ctx.Value(sessionIdKey{}).(string)
+	refreshTokens, err := retryutil.RetryWithData(func() ([]repository.RefreshToken, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return s.configs.Db.Queries.SelectUserSessions(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user sessions")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := make([]dtos.SessionResponse, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		resBody = append(resBody, newSessionResponse(refreshToken, sessionId))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got sessions")
+}
+
+func (s session) RevokeSession(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	sessionUUID, err := uuid.Parse(chi.URLParam(req, "sessionId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("session not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return s.configs.Db.Queries.RevokeRefreshTokenFamily(ctx, repository.RevokeRefreshTokenFamilyParams{
+			FamilyID: pgtype.UUID{Bytes: sessionUUID, Valid: true},
+			UserID:   pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to revoke refresh token family")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("session not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully revoked session")
+}
+
+// RevokeOtherSessions signs out every session except the one of the access
+// token used for the request.
+func (s session) RevokeOtherSessions(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var currentSessionUUID pgtype.UUID
+	if sessionId, _ := ctx.Value(sessionIdKey{}).(string); sessionId != "" {
+		sessionUUID, err := uuid.Parse(sessionId)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse session Id to UUID")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		currentSessionUUID = pgtype.UUID{Bytes: sessionUUID, Valid: true}
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	_, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return s.configs.Db.Queries.RevokeOtherRefreshTokenFamilies(ctx, repository.RevokeOtherRefreshTokenFamiliesParams{
+			UserID:   pgtype.UUID{Bytes: userUUID, Valid: true},
+			FamilyID: currentSessionUUID,
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to revoke other refresh token families")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully revoked other sessions")
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestSessionHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("GetSessions/Success", func(t *testing.T) {
+		url := fmt.Sprintf("%s/sessions", testServer.URL)
+		res, err := testClient.Get(url)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var sessions []dtos.SessionResponse
+		if err := json.NewDecoder(res.Body).Decode(&sessions); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		// The seeded refresh token of the test user.
+		if len(sessions) == 0 {
+			t.Fatal("expected at least one session")
+		}
+
+		for _, session := range sessions {
+			if session.Id == "" || session.CreatedAt == "" || session.LastUsedAt == "" {
+				t.Errorf("incomplete session: %+v", session)
+			}
+		}
+	})
+
+	revokeSessionTable := []struct {
+		name           string
+		sessionId      string
+		expectedStatus int
+	}{
+		{
+			name:           "RevokeSession/Not Found",
+			sessionId:      uuid.NewString(),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "RevokeSession/Not Found (invalid Id)",
+			sessionId:      "invalid",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range revokeSessionTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/sessions/%s", testServer.URL, v.sessionId)
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
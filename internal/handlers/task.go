@@ -1,18 +1,24 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mdayat/demi-masa-backend-service/configs"
 	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
 	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
 	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
 	"github.com/mdayat/demi-masa-backend-service/repository"
 	"github.com/rs/zerolog/log"
 )
@@ -22,46 +28,313 @@ type TaskHandler interface {
 	CreateTask(res http.ResponseWriter, req *http.Request)
 	UpdateTask(res http.ResponseWriter, req *http.Request)
 	DeleteTask(res http.ResponseWriter, req *http.Request)
+	MoveTask(res http.ResponseWriter, req *http.Request)
+	GetTrashedTasks(res http.ResponseWriter, req *http.Request)
+	RestoreTask(res http.ResponseWriter, req *http.Request)
+	BulkTasks(res http.ResponseWriter, req *http.Request)
+	GetTaskStats(res http.ResponseWriter, req *http.Request)
+	GetTaskOccurrences(res http.ResponseWriter, req *http.Request)
+	UpdateTaskOccurrence(res http.ResponseWriter, req *http.Request)
+	SkipTaskOccurrence(res http.ResponseWriter, req *http.Request)
 }
 
 type task struct {
-	configs configs.Configs
+	configs     configs.Configs
+	service     services.TaskServicer
+	entitlement services.EntitlementServicer
 }
 
-func NewTaskHandler(configs configs.Configs) TaskHandler {
+func NewTaskHandler(configs configs.Configs, service services.TaskServicer, entitlement services.EntitlementServicer) TaskHandler {
 	return &task{
-		configs: configs,
+		configs:     configs,
+		service:     service,
+		entitlement: entitlement,
 	}
 }
 
+// unscheduledGroup holds tasks without a prayer anchor when tasks are grouped
+// by prayer slot.
+const unscheduledGroup = "unscheduled"
+
+func hasAnchoredTask(tasks []repository.Task) bool {
+	for _, task := range tasks {
+		if task.AnchorPrayer.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+// taskExtras holds what is resolved alongside tasks but isn't stored on the
+// task row itself. Tasks missing from a map get the zero value.
+type taskExtras struct {
+	schedules  map[pgtype.UUID]services.TaskSchedule
+	progresses map[pgtype.UUID]services.TaskProgress
+	labels     map[pgtype.UUID][]repository.Label
+}
+
+// resolveTaskExtras resolves the extras of the given tasks, scheduling them
+// for today when they are anchored to a prayer.
+func (t task) resolveTaskExtras(ctx context.Context, tasks ...repository.Task) (taskExtras, error) {
+	var extras taskExtras
+	if hasAnchoredTask(tasks) {
+		result, err := t.service.ResolveTaskSchedules(ctx, services.ResolveTaskSchedulesParams{
+			UserUUID: tasks[0].UserID,
+			Tasks:    tasks,
+		})
+
+		if err != nil {
+			return taskExtras{}, fmt.Errorf("failed to resolve task schedules: %w", err)
+		}
+		extras.schedules = result.TaskSchedules
+	}
+
+	progresses, err := t.service.ResolveTaskProgress(ctx, tasks)
+	if err != nil {
+		return taskExtras{}, fmt.Errorf("failed to resolve task progress: %w", err)
+	}
+	extras.progresses = progresses
+
+	labels, err := t.service.ResolveTaskLabels(ctx, tasks)
+	if err != nil {
+		return taskExtras{}, fmt.Errorf("failed to resolve task labels: %w", err)
+	}
+	extras.labels = labels
+
+	return extras, nil
+}
+
+func newLabelResponse(label repository.Label) dtos.LabelResponse {
+	return dtos.LabelResponse{
+		Id:        label.ID.String(),
+		Name:      label.Name,
+		Color:     label.Color,
+		CreatedAt: label.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+func newTaskResponse(task repository.Task, extras taskExtras) dtos.TaskResponse {
+	resBody := dtos.TaskResponse{
+		Id:                         task.ID.String(),
+		Name:                       task.Name,
+		Description:                task.Description,
+		Checked:                    task.Checked,
+		Priority:                   task.Priority,
+		Labels:                     make([]dtos.LabelResponse, 0, len(extras.labels[task.ID])),
+		Position:                   task.Position,
+		CreatedAt:                  task.CreatedAt.Time.Format(time.RFC3339),
+		EstimatedDurationInMinutes: task.EstimatedDurationInMinutes.Int16,
+	}
+
+	if task.AnchorPrayer.Valid {
+		resBody.Anchor = &dtos.TaskAnchor{
+			Prayer:          task.AnchorPrayer.String,
+			Relation:        task.AnchorRelation.String,
+			OffsetInMinutes: task.AnchorOffsetInMinutes,
+		}
+	}
+
+	if task.RecurrenceRule.Valid {
+		resBody.Recurrence = &dtos.TaskRecurrence{
+			Rule:     task.RecurrenceRule.String,
+			StartsOn: task.RecurrenceStart.Time.Format(time.DateOnly),
+		}
+	}
+
+	if task.DueAt.Valid {
+		resBody.DueAt = task.DueAt.Time.Format(time.RFC3339)
+	}
+
+	if task.DeletedAt.Valid {
+		resBody.DeletedAt = task.DeletedAt.Time.Format(time.RFC3339)
+	}
+
+	if task.ListID.Valid {
+		resBody.ListId = task.ListID.String()
+	}
+
+	if task.AssigneeID.Valid {
+		resBody.AssigneeId = task.AssigneeID.String()
+	}
+
+	if task.CompletedBy.Valid {
+		resBody.CompletedBy = task.CompletedBy.String()
+	}
+
+	if task.CompletedAt.Valid {
+		resBody.CompletedAt = task.CompletedAt.Time.Format(time.RFC3339)
+	}
+
+	if schedule, ok := extras.schedules[task.ID]; ok {
+		resBody.ScheduledAt = schedule.StartsAt.Format(time.RFC3339)
+		if !schedule.EndsAt.IsZero() {
+			resBody.ScheduledUntil = schedule.EndsAt.Format(time.RFC3339)
+		}
+	}
+
+	if progress, ok := extras.progresses[task.ID]; ok {
+		resBody.Progress = dtos.TaskProgress{Total: progress.Total, Checked: progress.Checked}
+	}
+
+	for _, label := range extras.labels[task.ID] {
+		resBody.Labels = append(resBody.Labels, newLabelResponse(label))
+	}
+
+	return resBody
+}
+
+func newTaskOccurrenceResponse(occurrence services.TaskOccurrence) dtos.TaskOccurrenceResponse {
+	resBody := dtos.TaskOccurrenceResponse{
+		TaskId:      occurrence.TaskID.String(),
+		Date:        occurrence.Date.Format(time.DateOnly),
+		Name:        occurrence.Name,
+		Description: occurrence.Description,
+		Checked:     occurrence.Checked,
+		Skipped:     occurrence.Skipped,
+	}
+
+	if !occurrence.Schedule.StartsAt.IsZero() {
+		resBody.ScheduledAt = occurrence.Schedule.StartsAt.Format(time.RFC3339)
+	}
+
+	if !occurrence.Schedule.EndsAt.IsZero() {
+		resBody.ScheduledUntil = occurrence.Schedule.EndsAt.Format(time.RFC3339)
+	}
+
+	return resBody
+}
+
+func groupTasksByPrayer(
+	tasks []repository.Task,
+	prayerSlots []services.PrayerSlot,
+	extras taskExtras,
+) []dtos.TaskGroupResponse {
+	groups := make([]dtos.TaskGroupResponse, 0, len(prayerSlots)+1)
+	groupIndexes := make(map[string]int, len(prayerSlots)+1)
+
+	for _, slot := range prayerSlots {
+		groupIndexes[slot.Name] = len(groups)
+		groups = append(groups, dtos.TaskGroupResponse{
+			Prayer:   slot.Name,
+			StartsAt: slot.StartsAt.Format(time.RFC3339),
+			EndsAt:   slot.EndsAt.Format(time.RFC3339),
+			Tasks:    []dtos.TaskResponse{},
+		})
+	}
+
+	groupIndexes[unscheduledGroup] = len(groups)
+	groups = append(groups, dtos.TaskGroupResponse{
+		Prayer: unscheduledGroup,
+		Tasks:  []dtos.TaskResponse{},
+	})
+
+	for _, task := range tasks {
+		groupName := unscheduledGroup
+		if schedule, ok := extras.schedules[task.ID]; ok {
+			groupName = schedule.Slot
+		}
+
+		index := groupIndexes[groupName]
+		groups[index].Tasks = append(groups[index].Tasks, newTaskResponse(task, extras))
+	}
+
+	for _, group := range groups {
+		// RFC3339 strings share the same offset within a day, so they sort
+		// chronologically.
+		sort.SliceStable(group.Tasks, func(i, j int) bool {
+			return group.Tasks[i].ScheduledAt < group.Tasks[j].ScheduledAt
+		})
+	}
+
+	return groups
+}
+
 func (t task) GetTasks(res http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	logger := log.Ctx(ctx).With().Logger()
 
-	userId := ctx.Value(userIdKey{}).(string)
-	tasks, err := retryutil.RetryWithData(func() ([]repository.Task, error) {
-		userUUID, err := uuid.Parse(userId)
+	var date time.Time
+	if dateString := req.URL.Query().Get("date"); dateString != "" {
+		var err error
+		date, err = time.Parse(time.DateOnly, dateString)
 		if err != nil {
-			return []repository.Task{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid date query params")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
 		}
+	}
 
-		return t.configs.Db.Queries.SelectUserTasks(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
-	})
+	groupBy := req.URL.Query().Get("group_by")
+	if groupBy != "" && groupBy != "prayer" {
+		logger.Error().Caller().Int("status_code", http.StatusBadRequest).Msg("invalid group_by query params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	listParams, err := t.service.ParseListTasksQuery(req.URL.Query())
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid list query params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
 
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
 	if err != nil {
-		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user tasks")
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
 		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	resBody := make([]dtos.TaskResponse, 0, len(tasks))
-	for _, task := range tasks {
-		resBody = append(resBody, dtos.TaskResponse{
-			Id:          task.ID.String(),
-			Name:        task.Name,
-			Description: task.Description,
-			Checked:     task.Checked,
+	listParams.UserUUID = pgtype.UUID{Bytes: userUUID, Valid: true}
+	listResult, err := t.service.ListTasks(ctx, listParams)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to list user tasks")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tasks := listResult.Tasks
+	var result services.TaskSchedulesResult
+	if groupBy == "prayer" || hasAnchoredTask(tasks) {
+		result, err = t.service.ResolveTaskSchedules(ctx, services.ResolveTaskSchedulesParams{
+			UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+			Date:     date,
+			Tasks:    tasks,
 		})
+
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task schedules")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	progresses, err := t.service.ResolveTaskProgress(ctx, tasks)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task progress")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	labels, err := t.service.ResolveTaskLabels(ctx, tasks)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task labels")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	extras := taskExtras{schedules: result.TaskSchedules, progresses: progresses, labels: labels}
+
+	var resBody any
+	if groupBy == "prayer" {
+		resBody = groupTasksByPrayer(tasks, result.PrayerSlots, extras)
+	} else {
+		taskResponses := make([]dtos.TaskResponse, 0, len(tasks))
+		for _, task := range tasks {
+			taskResponses = append(taskResponses, newTaskResponse(task, extras))
+		}
+		resBody = taskResponses
 	}
 
 	params := httputil.SendSuccessResponseParams{
@@ -69,6 +342,10 @@ func (t task) GetTasks(res http.ResponseWriter, req *http.Request) {
 		ResBody:    resBody,
 	}
 
+	if listResult.NextCursor != "" {
+		res.Header().Set("X-Next-Cursor", listResult.NextCursor)
+	}
+
 	if err := httputil.SendSuccessResponse(res, params); err != nil {
 		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
 		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -78,6 +355,107 @@ func (t task) GetTasks(res http.ResponseWriter, req *http.Request) {
 	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got tasks")
 }
 
+// newInsertUserTaskParams converts a create request into insert params,
+// leaving the task and user Ids to the caller.
+func (t task) newInsertUserTaskParams(reqBody dtos.CreateTaskRequest) (repository.InsertUserTaskParams, error) {
+	insertParams := repository.InsertUserTaskParams{
+		Name:        reqBody.Name,
+		Description: reqBody.Description,
+		Priority:    reqBody.Priority,
+	}
+
+	if reqBody.EstimatedDurationInMinutes != 0 {
+		insertParams.EstimatedDurationInMinutes = pgtype.Int2{Int16: reqBody.EstimatedDurationInMinutes, Valid: true}
+	}
+
+	if reqBody.DueAt != "" {
+		dueAt, err := time.Parse(time.RFC3339, reqBody.DueAt)
+		if err != nil {
+			return repository.InsertUserTaskParams{}, fmt.Errorf("invalid due_at: %w", err)
+		}
+		insertParams.DueAt = pgtype.Timestamptz{Time: dueAt, Valid: true}
+	}
+
+	if reqBody.Anchor != nil {
+		insertParams.AnchorPrayer = pgtype.Text{String: reqBody.Anchor.Prayer, Valid: true}
+		insertParams.AnchorRelation = pgtype.Text{String: reqBody.Anchor.Relation, Valid: true}
+		insertParams.AnchorOffsetInMinutes = reqBody.Anchor.OffsetInMinutes
+	}
+
+	if reqBody.Recurrence != nil {
+		rule, startsOn, err := t.service.ValidateRecurrence(reqBody.Recurrence.Rule, reqBody.Recurrence.StartsOn)
+		if err != nil {
+			return repository.InsertUserTaskParams{}, fmt.Errorf("invalid recurrence: %w", err)
+		}
+
+		insertParams.RecurrenceRule = pgtype.Text{String: rule.String(), Valid: true}
+		insertParams.RecurrenceStart = pgtype.Date{Time: startsOn, Valid: true}
+	}
+
+	return insertParams, nil
+}
+
+// newUpdateUserTaskParams converts an update request into update params,
+// leaving the task and user Ids to the caller.
+func (t task) newUpdateUserTaskParams(reqBody dtos.UpdateTaskRequest) (repository.UpdateUserTaskParams, error) {
+	updateParams := repository.UpdateUserTaskParams{
+		RemoveAnchor:            reqBody.RemoveAnchor,
+		RemoveRecurrence:        reqBody.RemoveRecurrence,
+		RemoveDueAt:             reqBody.RemoveDueAt,
+		RemoveEstimatedDuration: reqBody.RemoveEstimatedDuration,
+	}
+
+	if reqBody.Name != "" {
+		updateParams.Name = pgtype.Text{String: reqBody.Name, Valid: true}
+	}
+
+	if reqBody.Description != "" {
+		updateParams.Description = pgtype.Text{String: reqBody.Description, Valid: true}
+	}
+
+	if reqBody.Checked != nil {
+		updateParams.Checked = pgtype.Bool{Bool: *reqBody.Checked, Valid: true}
+	}
+
+	if reqBody.Anchor != nil {
+		updateParams.AnchorPrayer = pgtype.Text{String: reqBody.Anchor.Prayer, Valid: true}
+		updateParams.AnchorRelation = pgtype.Text{String: reqBody.Anchor.Relation, Valid: true}
+		updateParams.AnchorOffsetInMinutes = pgtype.Int2{Int16: reqBody.Anchor.OffsetInMinutes, Valid: true}
+	}
+
+	if reqBody.Recurrence != nil {
+		rule, startsOn, err := t.service.ValidateRecurrence(reqBody.Recurrence.Rule, reqBody.Recurrence.StartsOn)
+		if err != nil {
+			return repository.UpdateUserTaskParams{}, fmt.Errorf("invalid recurrence: %w", err)
+		}
+
+		updateParams.RecurrenceRule = pgtype.Text{String: rule.String(), Valid: true}
+		updateParams.RecurrenceStart = pgtype.Date{Time: startsOn, Valid: true}
+	}
+
+	if reqBody.DueAt != "" {
+		dueAt, err := time.Parse(time.RFC3339, reqBody.DueAt)
+		if err != nil {
+			return repository.UpdateUserTaskParams{}, fmt.Errorf("invalid due_at: %w", err)
+		}
+		updateParams.DueAt = pgtype.Timestamptz{Time: dueAt, Valid: true}
+	}
+
+	if reqBody.Priority != nil {
+		updateParams.Priority = pgtype.Int2{Int16: *reqBody.Priority, Valid: true}
+	}
+
+	if reqBody.EstimatedDurationInMinutes != nil {
+		updateParams.EstimatedDurationInMinutes = pgtype.Int2{Int16: *reqBody.EstimatedDurationInMinutes, Valid: true}
+	}
+
+	return updateParams, nil
+}
+
+func isEmptyUpdateTaskRequest(reqBody dtos.UpdateTaskRequest) bool {
+	return reqBody.Name == "" && reqBody.Description == "" && reqBody.Checked == nil && reqBody.Anchor == nil && !reqBody.RemoveAnchor && reqBody.Recurrence == nil && !reqBody.RemoveRecurrence && reqBody.DueAt == "" && !reqBody.RemoveDueAt && reqBody.Priority == nil && reqBody.EstimatedDurationInMinutes == nil && !reqBody.RemoveEstimatedDuration
+}
+
 func (t task) CreateTask(res http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	logger := log.Ctx(ctx).With().Logger()
@@ -91,34 +469,59 @@ func (t task) CreateTask(res http.ResponseWriter, req *http.Request) {
 
 	taskUUID := uuid.New()
 	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
 
-	task, err := retryutil.RetryWithData(func() (repository.Task, error) {
-		userUUID, err := uuid.Parse(userId)
-		if err != nil {
-			return repository.Task{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
-		}
+	insertParams, err := t.newInsertUserTaskParams(reqBody)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid task params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
 
-		return t.configs.Db.Queries.InsertUserTask(ctx, repository.InsertUserTaskParams{
-			ID:          pgtype.UUID{Bytes: taskUUID, Valid: true},
-			UserID:      pgtype.UUID{Bytes: userUUID, Valid: true},
-			Name:        reqBody.Name,
-			Description: reqBody.Description,
-		})
-	})
+	insertParams.ID = pgtype.UUID{Bytes: taskUUID, Valid: true}
+	insertParams.UserID = pgtype.UUID{Bytes: userUUID, Valid: true}
 
+	entitlements, err := t.entitlement.GetEntitlements(ctx, insertParams.UserID)
 	if err != nil {
-		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to insert user task")
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
 		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	resBody := dtos.TaskResponse{
-		Id:          task.ID.String(),
-		Name:        task.Name,
-		Description: task.Description,
-		Checked:     task.Checked,
+	task, err := t.service.CreateTask(ctx, services.CreateTaskParams{
+		Insert:       insertParams,
+		Entitlements: entitlements,
+	})
+
+	if err != nil {
+		if !sendEntitlementError(res, req, err) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to create user task")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var result services.TaskSchedulesResult
+	if task.AnchorPrayer.Valid {
+		result, err = t.service.ResolveTaskSchedules(ctx, services.ResolveTaskSchedulesParams{
+			UserUUID: task.UserID,
+			Tasks:    []repository.Task{task},
+		})
+
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task schedules")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
 	}
 
+	resBody := newTaskResponse(task, taskExtras{schedules: result.TaskSchedules})
+
 	params := httputil.SendSuccessResponseParams{
 		StatusCode: http.StatusCreated,
 		ResBody:    resBody,
@@ -153,44 +556,47 @@ func (t task) UpdateTask(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if reqBody.Name == "" && reqBody.Description == "" && reqBody.Checked == nil {
+	if isEmptyUpdateTaskRequest(reqBody) {
 		res.WriteHeader(http.StatusNoContent)
 		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
 		return
 	}
 
-	var name pgtype.Text
-	if reqBody.Name != "" {
-		name = pgtype.Text{String: reqBody.Name, Valid: true}
+	updateParams, err := t.newUpdateUserTaskParams(reqBody)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid task params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
 	}
 
-	var description pgtype.Text
-	if reqBody.Description != "" {
-		description = pgtype.Text{String: reqBody.Description, Valid: true}
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
 
-	var checked pgtype.Bool
-	if reqBody.Checked != nil {
-		checked = pgtype.Bool{Bool: *reqBody.Checked, Valid: true}
-	}
+	updateParams.ID = pgtype.UUID{Bytes: taskUUID, Valid: true}
+	updateParams.UserID = pgtype.UUID{Bytes: userUUID, Valid: true}
 
-	userId := ctx.Value(userIdKey{}).(string)
-	task, err := retryutil.RetryWithData(func() (repository.Task, error) {
-		userUUID, err := uuid.Parse(userId)
-		if err != nil {
-			return repository.Task{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
-		}
+	entitlements, err := t.entitlement.GetEntitlements(ctx, updateParams.UserID)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
 
-		return t.configs.Db.Queries.UpdateUserTask(ctx, repository.UpdateUserTaskParams{
-			ID:          pgtype.UUID{Bytes: taskUUID, Valid: true},
-			UserID:      pgtype.UUID{Bytes: userUUID, Valid: true},
-			Name:        name,
-			Description: description,
-			Checked:     checked,
-		})
+	task, err := t.service.UpdateTask(ctx, services.UpdateTaskParams{
+		Update:       updateParams,
+		Entitlements: entitlements,
 	})
 
 	if err != nil {
+		if sendEntitlementError(res, req, err) {
+			return
+		}
+
 		if errors.Is(err, pgx.ErrNoRows) {
 			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
 			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -201,13 +607,15 @@ func (t task) UpdateTask(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	resBody := dtos.TaskResponse{
-		Id:          task.ID.String(),
-		Name:        task.Name,
-		Description: task.Description,
-		Checked:     task.Checked,
+	extras, err := t.resolveTaskExtras(ctx, task)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task extras")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
 
+	resBody := newTaskResponse(task, extras)
+
 	params := httputil.SendSuccessResponseParams{
 		StatusCode: http.StatusOK,
 		ResBody:    resBody,
@@ -260,5 +668,687 @@ func (t task) DeleteTask(res http.ResponseWriter, req *http.Request) {
 	}
 
 	res.WriteHeader(http.StatusNoContent)
-	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted task")
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully moved task to trash")
+}
+
+func (t task) MoveTask(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.MoveTaskRequest
+	if err := httputil.DecodeAndValidate(req, t.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	targetId := reqBody.AfterId
+	if reqBody.BeforeId != "" {
+		targetId = reqBody.BeforeId
+	}
+
+	targetUUID, err := uuid.Parse(targetId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid target task Id")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := t.service.MoveTask(ctx, services.MoveTaskParams{
+		UserUUID:   pgtype.UUID{Bytes: userUUID, Valid: true},
+		TaskUUID:   pgtype.UUID{Bytes: taskUUID, Valid: true},
+		TargetUUID: pgtype.UUID{Bytes: targetUUID, Valid: true},
+		Before:     reqBody.BeforeId != "",
+	})
+
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTaskMove) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid task move")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to move task")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	extras, err := t.resolveTaskExtras(ctx, task)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task extras")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newTaskResponse(task, extras),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully moved task")
+}
+
+func (t task) GetTrashedTasks(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	tasks, err := retryutil.RetryWithData(func() ([]repository.Task, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return t.configs.Db.Queries.SelectUserTrashedTasks(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to select user trashed tasks")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	progresses, err := t.service.ResolveTaskProgress(ctx, tasks)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task progress")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	labels, err := t.service.ResolveTaskLabels(ctx, tasks)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task labels")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Trashed tasks aren't scheduled, so prayer anchors are left unresolved.
+	extras := taskExtras{progresses: progresses, labels: labels}
+	resBody := make([]dtos.TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		resBody = append(resBody, newTaskResponse(task, extras))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got trashed tasks")
+}
+
+func newTaskStatsPeriods(periods []services.TaskStatsPeriod) []dtos.TaskStatsPeriod {
+	resBody := make([]dtos.TaskStatsPeriod, 0, len(periods))
+	for _, period := range periods {
+		resBody = append(resBody, dtos.TaskStatsPeriod{
+			StartsOn:  period.StartsOn.Format(time.DateOnly),
+			Completed: period.Completed,
+		})
+	}
+	return resBody
+}
+
+func (t task) GetTaskStats(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := services.GetTaskStatsParams{UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true}}
+	if fromString := req.URL.Query().Get("from"); fromString != "" {
+		params.From, err = time.Parse(time.DateOnly, fromString)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid from query param")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if toString := req.URL.Query().Get("to"); toString != "" {
+		params.To, err = time.Parse(time.DateOnly, toString)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid to query param")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	params.Entitlements, err = t.entitlement.GetEntitlements(ctx, params.UserUUID)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	stats, err := t.service.GetTaskStats(ctx, params)
+	if err != nil {
+		if sendEntitlementError(res, req, err) {
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidStatsRange) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid stats range")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get task stats")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := dtos.TaskStatsResponse{
+		From:                              stats.From.Format(time.DateOnly),
+		To:                                stats.To.Format(time.DateOnly),
+		Completed:                         stats.Completed,
+		OnTime:                            stats.OnTime,
+		Overdue:                           stats.Overdue,
+		WithoutDueDate:                    stats.WithoutDueDate,
+		AverageCompletionLatencyInSeconds: int64(stats.AverageCompletionLatency.Seconds()),
+		Days:                              newTaskStatsPeriods(stats.Days),
+		Weeks:                             newTaskStatsPeriods(stats.Weeks),
+		PrayerSlots:                       make([]dtos.TaskStatsPrayerSlot, 0, len(stats.PrayerSlots)),
+	}
+
+	for _, prayerSlot := range stats.PrayerSlots {
+		resBody.PrayerSlots = append(resBody.PrayerSlots, dtos.TaskStatsPrayerSlot{
+			Prayer:    prayerSlot.Name,
+			Completed: prayerSlot.Completed,
+		})
+	}
+
+	successParams := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, successParams); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got task stats")
+}
+
+func (t task) RestoreTask(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("trashed task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	entitlements, err := t.entitlement.GetEntitlements(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := t.service.RestoreTask(ctx, services.RestoreTaskParams{
+		UserUUID:     pgtype.UUID{Bytes: userUUID, Valid: true},
+		TaskUUID:     pgtype.UUID{Bytes: taskUUID, Valid: true},
+		Entitlements: entitlements,
+	})
+
+	if err != nil {
+		if sendEntitlementError(res, req, err) {
+			return
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("trashed task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to restore user task")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	extras, err := t.resolveTaskExtras(ctx, task)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task extras")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newTaskResponse(task, extras),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully restored task")
+}
+
+// maxOccurrenceRangeInDays bounds how many days of occurrences can be expanded
+// in a single request.
+const maxOccurrenceRangeInDays = 92
+
+func (t task) GetTaskOccurrences(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	from, err := time.Parse(time.DateOnly, req.URL.Query().Get("from"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid from query params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	to, err := time.Parse(time.DateOnly, req.URL.Query().Get("to"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid to query params")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if to.Before(from) || to.Sub(from) > maxOccurrenceRangeInDays*24*time.Hour {
+		logger.Error().Caller().Int("status_code", http.StatusBadRequest).Msg("invalid occurrence date range")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	occurrences, err := t.service.ExpandTaskOccurrences(ctx, services.ExpandTaskOccurrencesParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		TaskUUID: pgtype.UUID{Bytes: taskUUID, Valid: true},
+		From:     from,
+		To:       to,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, services.ErrTaskNotRecurring) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("recurring task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to expand task occurrences")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := make([]dtos.TaskOccurrenceResponse, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		resBody = append(resBody, newTaskOccurrenceResponse(occurrence))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got task occurrences")
+}
+
+func (t task) updateTaskOccurrence(res http.ResponseWriter, req *http.Request, arg services.UpdateTaskOccurrenceParams) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, chi.URLParam(req, "date"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task occurrence not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	arg.UserUUID = pgtype.UUID{Bytes: userUUID, Valid: true}
+	arg.TaskUUID = pgtype.UUID{Bytes: taskUUID, Valid: true}
+	arg.Date = date
+
+	occurrence, err := t.service.UpdateTaskOccurrence(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, services.ErrTaskNotRecurring) || errors.Is(err, services.ErrNotTaskOccurrence) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task occurrence not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update task occurrence")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newTaskOccurrenceResponse(occurrence),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated task occurrence")
+}
+
+func (t task) UpdateTaskOccurrence(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.UpdateTaskOccurrenceRequest
+	if err := httputil.DecodeAndValidate(req, t.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if reqBody.Name == "" && reqBody.Description == "" && reqBody.Checked == nil && reqBody.Skipped == nil {
+		res.WriteHeader(http.StatusNoContent)
+		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
+		return
+	}
+
+	var arg services.UpdateTaskOccurrenceParams
+	if reqBody.Name != "" {
+		arg.Name = pgtype.Text{String: reqBody.Name, Valid: true}
+	}
+
+	if reqBody.Description != "" {
+		arg.Description = pgtype.Text{String: reqBody.Description, Valid: true}
+	}
+
+	if reqBody.Checked != nil {
+		arg.Checked = pgtype.Bool{Bool: *reqBody.Checked, Valid: true}
+	}
+
+	if reqBody.Skipped != nil {
+		arg.Skipped = pgtype.Bool{Bool: *reqBody.Skipped, Valid: true}
+	}
+
+	t.updateTaskOccurrence(res, req, arg)
+}
+
+func (t task) SkipTaskOccurrence(res http.ResponseWriter, req *http.Request) {
+	t.updateTaskOccurrence(res, req, services.UpdateTaskOccurrenceParams{
+		Skipped: pgtype.Bool{Bool: true, Valid: true},
+	})
+}
+
+// newBulkTaskOperation converts an operation of a bulk request, where check
+// and uncheck are updates of the checked field only.
+func (t task) newBulkTaskOperation(operation dtos.BulkTaskOperation) (services.BulkTaskOperation, error) {
+	if operation.Op == "create" {
+		insertParams, err := t.newInsertUserTaskParams(*operation.Create)
+		if err != nil {
+			return services.BulkTaskOperation{}, err
+		}
+
+		insertParams.ID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+		return services.BulkTaskOperation{Kind: services.BulkTaskCreate, Insert: insertParams}, nil
+	}
+
+	taskUUID, err := uuid.Parse(operation.TaskId)
+	if err != nil {
+		return services.BulkTaskOperation{}, fmt.Errorf("failed to parse task Id to UUID: %w", err)
+	}
+
+	bulkOperation := services.BulkTaskOperation{TaskUUID: pgtype.UUID{Bytes: taskUUID, Valid: true}}
+	switch operation.Op {
+	case "update":
+		bulkOperation.Kind = services.BulkTaskUpdate
+		bulkOperation.Update, err = t.newUpdateUserTaskParams(*operation.Update)
+		if err != nil {
+			return services.BulkTaskOperation{}, err
+		}
+	case "check", "uncheck":
+		bulkOperation.Kind = services.BulkTaskUpdate
+		bulkOperation.Update.Checked = pgtype.Bool{Bool: operation.Op == "check", Valid: true}
+	case "delete":
+		bulkOperation.Kind = services.BulkTaskDelete
+	}
+
+	bulkOperation.Update.ID = bulkOperation.TaskUUID
+	return bulkOperation, nil
+}
+
+// newBulkTaskFailureResponse marks the operation at failedIndex as failed and
+// every other one as rolled back.
+func newBulkTaskFailureResponse(operations []dtos.BulkTaskOperation, failedIndex int, err error) dtos.BulkTaskResponse {
+	resBody := dtos.BulkTaskResponse{Results: make([]dtos.BulkTaskResult, 0, len(operations))}
+	for i, operation := range operations {
+		result := dtos.BulkTaskResult{Index: i, Op: operation.Op, Status: "rolled_back"}
+		if i == failedIndex {
+			result.Status = "failed"
+			result.Error = err.Error()
+		}
+		resBody.Results = append(resBody.Results, result)
+	}
+	return resBody
+}
+
+// bulkTaskErrorStatus maps the error of a failed bulk task operation to the
+// status of the response and the error reported for the operation. It reports
+// false for errors the operation itself isn't to blame for.
+func bulkTaskErrorStatus(err error) (int, error, bool) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound, errors.New("task not found"), true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return 0, nil, false
+	}
+
+	if pgErr.Code == pgerrcode.UniqueViolation {
+		return http.StatusConflict, fmt.Errorf("conflicts with an existing task (%s)", pgErr.ConstraintName), true
+	}
+
+	if pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+		return http.StatusBadRequest, fmt.Errorf("invalid task (%s)", pgErr.ConstraintName), true
+	}
+
+	if pgerrcode.IsDataException(pgErr.Code) {
+		return http.StatusBadRequest, errors.New("invalid task"), true
+	}
+
+	return 0, nil, false
+}
+
+func (t task) BulkTasks(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.BulkTaskRequest
+	if err := httputil.DecodeAndValidate(req, t.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	bulkParams := services.BulkTasksParams{
+		UserUUID:   pgtype.UUID{Bytes: userUUID, Valid: true},
+		Operations: make([]services.BulkTaskOperation, 0, len(reqBody.Operations)),
+	}
+
+	for i, operation := range reqBody.Operations {
+		bulkOperation, err := t.newBulkTaskOperation(operation)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Int("operation_index", i).Msg("invalid bulk task operation")
+			params := httputil.SendErrorResponseParams{
+				StatusCode: http.StatusBadRequest,
+				ResBody:    newBulkTaskFailureResponse(reqBody.Operations, i, err),
+			}
+
+			if err := httputil.SendErrorResponse(res, params); err != nil {
+				logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send error response")
+				http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+		bulkParams.Operations = append(bulkParams.Operations, bulkOperation)
+	}
+
+	bulkParams.Entitlements, err = t.entitlement.GetEntitlements(ctx, bulkParams.UserUUID)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := t.service.BulkTasks(ctx, bulkParams)
+	if err != nil {
+		if sendEntitlementError(res, req, err) {
+			return
+		}
+
+		var bulkErr *services.BulkTaskError
+		statusCode, operationErr, ok := bulkTaskErrorStatus(err)
+		if !errors.As(err, &bulkErr) || !ok {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to execute bulk task operations")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		logger.Error().Err(err).Caller().Int("status_code", statusCode).Int("operation_index", bulkErr.Index).Msg("bulk task operation failed")
+		params := httputil.SendErrorResponseParams{
+			StatusCode: statusCode,
+			ResBody:    newBulkTaskFailureResponse(reqBody.Operations, bulkErr.Index, operationErr),
+		}
+
+		if err := httputil.SendErrorResponse(res, params); err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send error response")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var resolvedTasks []repository.Task
+	for i, task := range tasks {
+		if bulkParams.Operations[i].Kind != services.BulkTaskDelete {
+			resolvedTasks = append(resolvedTasks, task)
+		}
+	}
+
+	var extras taskExtras
+	if len(resolvedTasks) != 0 {
+		extras, err = t.resolveTaskExtras(ctx, resolvedTasks...)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to resolve task extras")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	resBody := dtos.BulkTaskResponse{Results: make([]dtos.BulkTaskResult, 0, len(tasks))}
+	for i, task := range tasks {
+		result := dtos.BulkTaskResult{Index: i, Op: reqBody.Operations[i].Op, Status: "ok"}
+		if bulkParams.Operations[i].Kind != services.BulkTaskDelete {
+			taskResponse := newTaskResponse(task, extras)
+			result.Task = &taskResponse
+		}
+		resBody.Results = append(resBody.Results, result)
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Int("operations", len(tasks)).Msg("successfully executed bulk task operations")
 }
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type TaskItemHandler interface {
+	GetTaskItems(res http.ResponseWriter, req *http.Request)
+	CreateTaskItem(res http.ResponseWriter, req *http.Request)
+	UpdateTaskItem(res http.ResponseWriter, req *http.Request)
+	ReorderTaskItems(res http.ResponseWriter, req *http.Request)
+	DeleteTaskItem(res http.ResponseWriter, req *http.Request)
+}
+
+type taskItem struct {
+	configs configs.Configs
+	service services.TaskItemServicer
+}
+
+func NewTaskItemHandler(configs configs.Configs, service services.TaskItemServicer) TaskItemHandler {
+	return &taskItem{
+		configs: configs,
+		service: service,
+	}
+}
+
+func newTaskItemResponse(item repository.TaskItem) dtos.TaskItemResponse {
+	return dtos.TaskItemResponse{
+		Id:       item.ID.String(),
+		TaskId:   item.TaskID.String(),
+		Name:     item.Name,
+		Checked:  item.Checked,
+		Position: item.Position,
+	}
+}
+
+func (ti taskItem) GetTaskItems(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	items, err := ti.service.GetTaskItems(ctx, services.GetTaskItemsParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		TaskUUID: pgtype.UUID{Bytes: taskUUID, Valid: true},
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get task items")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := make([]dtos.TaskItemResponse, 0, len(items))
+	for _, item := range items {
+		resBody = append(resBody, newTaskItemResponse(item))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got task items")
+}
+
+func (ti taskItem) CreateTaskItem(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.CreateTaskItemRequest
+	if err := httputil.DecodeAndValidate(req, ti.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	item, err := ti.service.AddTaskItem(ctx, services.AddTaskItemParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		TaskUUID: pgtype.UUID{Bytes: taskUUID, Valid: true},
+		Name:     reqBody.Name,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to add task item")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := newTaskItemResponse(item)
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/tasks/%s/items/%s", ti.configs.Env.OriginURL, resBody.TaskId, resBody.Id))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created task item")
+}
+
+func (ti taskItem) UpdateTaskItem(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.UpdateTaskItemRequest
+	if err := httputil.DecodeAndValidate(req, ti.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	itemId := chi.URLParam(req, "itemId")
+	itemUUID, err := uuid.Parse(itemId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task item not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if reqBody.Name == "" && reqBody.Checked == nil {
+		res.WriteHeader(http.StatusNoContent)
+		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
+		return
+	}
+
+	var name pgtype.Text
+	if reqBody.Name != "" {
+		name = pgtype.Text{String: reqBody.Name, Valid: true}
+	}
+
+	var checked pgtype.Bool
+	if reqBody.Checked != nil {
+		checked = pgtype.Bool{Bool: *reqBody.Checked, Valid: true}
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	item, err := retryutil.RetryWithData(func() (repository.TaskItem, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return repository.TaskItem{}, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return ti.configs.Db.Queries.UpdateUserTaskItem(ctx, repository.UpdateUserTaskItemParams{
+			ID:      pgtype.UUID{Bytes: itemUUID, Valid: true},
+			TaskID:  pgtype.UUID{Bytes: taskUUID, Valid: true},
+			UserID:  pgtype.UUID{Bytes: userUUID, Valid: true},
+			Name:    name,
+			Checked: checked,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task item not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update user task item")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newTaskItemResponse(item),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated task item")
+}
+
+func (ti taskItem) ReorderTaskItems(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.ReorderTaskItemsRequest
+	if err := httputil.DecodeAndValidate(req, ti.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	itemUUIDs := make([]pgtype.UUID, 0, len(reqBody.ItemIds))
+	for _, itemId := range reqBody.ItemIds {
+		itemUUIDs = append(itemUUIDs, pgtype.UUID{Bytes: uuid.MustParse(itemId), Valid: true})
+	}
+
+	items, err := ti.service.ReorderTaskItems(ctx, services.ReorderTaskItemsParams{
+		UserUUID:  pgtype.UUID{Bytes: userUUID, Valid: true},
+		TaskUUID:  pgtype.UUID{Bytes: taskUUID, Valid: true},
+		ItemUUIDs: itemUUIDs,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else if errors.Is(err, services.ErrInvalidItemOrder) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid item order")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to reorder task items")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := make([]dtos.TaskItemResponse, 0, len(items))
+	for _, item := range items {
+		resBody = append(resBody, newTaskItemResponse(item))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully reordered task items")
+}
+
+func (ti taskItem) DeleteTaskItem(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	taskId := chi.URLParam(req, "taskId")
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	itemId := chi.URLParam(req, "itemId")
+	itemUUID, err := uuid.Parse(itemId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task item not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	affectedRows, err := retryutil.RetryWithData(func() (int64, error) {
+		userUUID, err := uuid.Parse(userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse user Id to UUID: %w", err)
+		}
+
+		return ti.configs.Db.Queries.DeleteUserTaskItem(ctx, repository.DeleteUserTaskItemParams{
+			ID:     pgtype.UUID{Bytes: itemUUID, Valid: true},
+			TaskID: pgtype.UUID{Bytes: taskUUID, Valid: true},
+			UserID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		})
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to delete user task item")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if affectedRows == 0 {
+		logger.Error().Caller().Int("status_code", http.StatusNotFound).Msg("task item not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted task item")
+}
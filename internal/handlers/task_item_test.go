@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestTaskItemHandlers(t *testing.T) {
+	ctx := context.TODO()
+	var parentTask dtos.TaskResponse
+	var createdItems []dtos.TaskItemResponse
+
+	t.Run("CreateTask/Success (parent)", func(t *testing.T) {
+		reqBody := `{"name": "name", "description": "description"}`
+		res, err := testClient.Post(fmt.Sprintf("%s/tasks", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&parentTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+	})
+
+	createTaskItemTable := []struct {
+		name           string
+		taskId         string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.TaskItemResponse
+	}{
+		{
+			name:           "CreateTaskItem/Success (first)",
+			taskId:         parentTask.Id,
+			reqBody:        `{"name": "first"}`,
+			expectedStatus: http.StatusCreated,
+			expectedResult: dtos.TaskItemResponse{TaskId: parentTask.Id, Name: "first", Position: 0},
+		},
+		{
+			name:           "CreateTaskItem/Success (second)",
+			taskId:         parentTask.Id,
+			reqBody:        `{"name": "second"}`,
+			expectedStatus: http.StatusCreated,
+			expectedResult: dtos.TaskItemResponse{TaskId: parentTask.Id, Name: "second", Position: 1},
+		},
+		{
+			name:           "CreateTaskItem/Bad Request (name)",
+			taskId:         parentTask.Id,
+			reqBody:        `{"name": ""}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CreateTaskItem/Not Found",
+			taskId:         uuid.NewString(),
+			reqBody:        `{"name": "first"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range createTaskItemTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s/items", testServer.URL, v.taskId)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				var item dtos.TaskItemResponse
+				if err := json.NewDecoder(res.Body).Decode(&item); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(v.expectedResult, item, cmpopts.IgnoreFields(dtos.TaskItemResponse{}, "Id")); diff != "" {
+					t.Error(diff)
+				}
+				createdItems = append(createdItems, item)
+			}
+		})
+	}
+
+	if len(createdItems) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(createdItems))
+	}
+
+	updateTaskItemTable := []struct {
+		name           string
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+		expectedResult any
+	}{
+		{
+			name:           "UpdateTaskItem/Success",
+			method:         http.MethodPut,
+			path:           createdItems[0].Id,
+			reqBody:        `{"checked": true}`,
+			expectedStatus: http.StatusOK,
+			expectedResult: dtos.TaskItemResponse{
+				Id:       createdItems[0].Id,
+				TaskId:   parentTask.Id,
+				Name:     "first",
+				Checked:  true,
+				Position: 0,
+			},
+		},
+		{
+			name:           "UpdateTaskItem/Not Found",
+			method:         http.MethodPut,
+			path:           uuid.NewString(),
+			reqBody:        `{"checked": true}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "ReorderTaskItems/Success",
+			method:         http.MethodPut,
+			path:           "order",
+			reqBody:        fmt.Sprintf(`{"item_ids": ["%s", "%s"]}`, createdItems[1].Id, createdItems[0].Id),
+			expectedStatus: http.StatusOK,
+			expectedResult: []dtos.TaskItemResponse{
+				{Id: createdItems[1].Id, TaskId: parentTask.Id, Name: "second", Position: 0},
+				{Id: createdItems[0].Id, TaskId: parentTask.Id, Name: "first", Checked: true, Position: 1},
+			},
+		},
+		{
+			name:           "ReorderTaskItems/Bad Request (missing item)",
+			method:         http.MethodPut,
+			path:           "order",
+			reqBody:        fmt.Sprintf(`{"item_ids": ["%s"]}`, createdItems[0].Id),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "DeleteTaskItem/Success",
+			method:         http.MethodDelete,
+			path:           createdItems[1].Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DeleteTaskItem/Not Found",
+			method:         http.MethodDelete,
+			path:           createdItems[1].Id,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range updateTaskItemTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s/items/%s", testServer.URL, parentTask.Id, v.path)
+			req, err := http.NewRequestWithContext(ctx, v.method, url, bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			switch expectedResult := v.expectedResult.(type) {
+			case dtos.TaskItemResponse:
+				var item dtos.TaskItemResponse
+				if err := json.NewDecoder(res.Body).Decode(&item); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(expectedResult, item); diff != "" {
+					t.Error(diff)
+				}
+			case []dtos.TaskItemResponse:
+				var items []dtos.TaskItemResponse
+				if err := json.NewDecoder(res.Body).Decode(&items); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(expectedResult, items); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+
+	t.Run("GetTasks/Success (progress)", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/tasks", testServer.URL))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var tasks []dtos.TaskResponse
+		if err = json.NewDecoder(res.Body).Decode(&tasks); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		for _, task := range tasks {
+			if task.Id != parentTask.Id {
+				continue
+			}
+
+			if diff := cmp.Diff(dtos.TaskProgress{Total: 1, Checked: 1}, task.Progress); diff != "" {
+				t.Error(diff)
+			}
+		}
+	})
+
+	t.Run("DeleteTask/Success (parent)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s", testServer.URL, parentTask.Id)
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,574 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+	"github.com/mdayat/demi-masa-backend-service/internal/httputil"
+	"github.com/mdayat/demi-masa-backend-service/internal/services"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+	"github.com/rs/zerolog/log"
+)
+
+type TaskListHandler interface {
+	GetTaskLists(res http.ResponseWriter, req *http.Request)
+	CreateTaskList(res http.ResponseWriter, req *http.Request)
+	DeleteTaskList(res http.ResponseWriter, req *http.Request)
+	GetTaskListMembers(res http.ResponseWriter, req *http.Request)
+	InviteTaskListMember(res http.ResponseWriter, req *http.Request)
+	RemoveTaskListMember(res http.ResponseWriter, req *http.Request)
+	GetTaskListTasks(res http.ResponseWriter, req *http.Request)
+	CreateTaskListTask(res http.ResponseWriter, req *http.Request)
+	UpdateTaskListTask(res http.ResponseWriter, req *http.Request)
+	DeleteTaskListTask(res http.ResponseWriter, req *http.Request)
+}
+
+type taskList struct {
+	configs     configs.Configs
+	service     services.TaskListServicer
+	entitlement services.EntitlementServicer
+}
+
+func NewTaskListHandler(configs configs.Configs, service services.TaskListServicer, entitlement services.EntitlementServicer) TaskListHandler {
+	return &taskList{
+		configs:     configs,
+		service:     service,
+		entitlement: entitlement,
+	}
+}
+
+func newTaskListResponse(list repository.TaskList) dtos.TaskListResponse {
+	return dtos.TaskListResponse{
+		Id:        list.ID.String(),
+		OwnerId:   list.OwnerID.String(),
+		Name:      list.Name,
+		CreatedAt: list.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+func newTaskListMemberResponse(member repository.SelectTaskListMembersRow) dtos.TaskListMemberResponse {
+	return dtos.TaskListMemberResponse{
+		UserId:   member.TaskListMember.UserID.String(),
+		Email:    member.Email,
+		Name:     member.Name,
+		Role:     member.TaskListMember.Role,
+		JoinedAt: member.TaskListMember.JoinedAt.Time.Format(time.RFC3339),
+	}
+}
+
+// sendTaskListError maps the errors of the task list service to a response.
+// Lists the user isn't a member of are reported as not found.
+func sendTaskListError(res http.ResponseWriter, req *http.Request, err error, notFoundMsg, failedMsg string) {
+	if sendEntitlementError(res, req, err) {
+		return
+	}
+
+	logger := log.Ctx(req.Context()).With().Logger()
+	if errors.Is(err, services.ErrTaskListForbidden) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Msg("forbidden task list action")
+		http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	} else if errors.Is(err, services.ErrAssigneeNotMember) || errors.Is(err, services.ErrTaskListOwnerLeave) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid task list action")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg(notFoundMsg)
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	} else {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg(failedMsg)
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// parseTaskListParams parses the acting user and the list of the request, and
+// responds itself when either can't be parsed.
+func parseTaskListParams(res http.ResponseWriter, req *http.Request) (services.TaskListParams, bool) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	listUUID, err := uuid.Parse(chi.URLParam(req, "listId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task list not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return services.TaskListParams{}, false
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return services.TaskListParams{}, false
+	}
+
+	return services.TaskListParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		ListUUID: pgtype.UUID{Bytes: listUUID, Valid: true},
+	}, true
+}
+
+func (tl taskList) GetTaskLists(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	lists, err := tl.service.GetTaskLists(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get task lists")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := make([]dtos.TaskListResponse, 0, len(lists))
+	for _, list := range lists {
+		resBody = append(resBody, newTaskListResponse(list))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got task lists")
+}
+
+func (tl taskList) CreateTaskList(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.CreateTaskListRequest
+	if err := httputil.DecodeAndValidate(req, tl.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	list, err := tl.service.CreateTaskList(ctx, services.CreateTaskListParams{
+		UserUUID: pgtype.UUID{Bytes: userUUID, Valid: true},
+		Name:     reqBody.Name,
+	})
+
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to create task list")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resBody := newTaskListResponse(list)
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/lists/%s", tl.configs.Env.OriginURL, resBody.Id))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created task list")
+}
+
+func (tl taskList) DeleteTaskList(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	if err := tl.service.DeleteTaskList(ctx, listParams); err != nil {
+		sendTaskListError(res, req, err, "task list not found", "failed to delete task list")
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted task list")
+}
+
+func (tl taskList) GetTaskListMembers(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	members, err := tl.service.GetTaskListMembers(ctx, listParams)
+	if err != nil {
+		sendTaskListError(res, req, err, "task list not found", "failed to get task list members")
+		return
+	}
+
+	resBody := make([]dtos.TaskListMemberResponse, 0, len(members))
+	for _, member := range members {
+		resBody = append(resBody, newTaskListMemberResponse(member))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got task list members")
+}
+
+func (tl taskList) InviteTaskListMember(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.InviteTaskListMemberRequest
+	if err := httputil.DecodeAndValidate(req, tl.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	member, err := tl.service.InviteTaskListMember(ctx, services.InviteTaskListMemberParams{
+		UserUUID: listParams.UserUUID,
+		ListUUID: listParams.ListUUID,
+		Email:    reqBody.Email,
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusConflict).Msg("user is already a task list member")
+			http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		} else {
+			sendTaskListError(res, req, err, "task list or invited user not found", "failed to invite task list member")
+		}
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    newTaskListMemberResponse(member),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully invited task list member")
+}
+
+func (tl taskList) RemoveTaskListMember(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	memberUUID, err := uuid.Parse(chi.URLParam(req, "userId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task list member not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	err = tl.service.RemoveTaskListMember(ctx, services.RemoveTaskListMemberParams{
+		UserUUID:   listParams.UserUUID,
+		ListUUID:   listParams.ListUUID,
+		MemberUUID: pgtype.UUID{Bytes: memberUUID, Valid: true},
+	})
+
+	if err != nil {
+		sendTaskListError(res, req, err, "task list member not found", "failed to remove task list member")
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully removed task list member")
+}
+
+func (tl taskList) GetTaskListTasks(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	tasks, err := tl.service.GetTaskListTasks(ctx, listParams)
+	if err != nil {
+		sendTaskListError(res, req, err, "task list not found", "failed to get task list tasks")
+		return
+	}
+
+	resBody := make([]dtos.TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		resBody = append(resBody, newTaskResponse(task, taskExtras{}))
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully got task list tasks")
+}
+
+func (tl taskList) CreateTaskListTask(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.CreateTaskListTaskRequest
+	if err := httputil.DecodeAndValidate(req, tl.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	createParams := services.CreateTaskListTaskParams{
+		UserUUID:    listParams.UserUUID,
+		ListUUID:    listParams.ListUUID,
+		Name:        reqBody.Name,
+		Description: reqBody.Description,
+		Priority:    reqBody.Priority,
+	}
+
+	if reqBody.AssigneeId != "" {
+		assigneeUUID, err := uuid.Parse(reqBody.AssigneeId)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid assignee Id")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		createParams.AssigneeUUID = pgtype.UUID{Bytes: assigneeUUID, Valid: true}
+	}
+
+	if reqBody.DueAt != "" {
+		dueAt, err := time.Parse(time.RFC3339, reqBody.DueAt)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid due_at")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		createParams.DueAt = pgtype.Timestamptz{Time: dueAt, Valid: true}
+	}
+
+	entitlements, err := tl.entitlement.GetEntitlements(ctx, listParams.UserUUID)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	createParams.Entitlements = entitlements
+	task, err := tl.service.CreateTaskListTask(ctx, createParams)
+	if err != nil {
+		sendTaskListError(res, req, err, "task list not found", "failed to create task list task")
+		return
+	}
+
+	resBody := newTaskResponse(task, taskExtras{})
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusCreated,
+		ResBody:    resBody,
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("%s/lists/%s/tasks/%s", tl.configs.Env.OriginURL, resBody.ListId, resBody.Id))
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusCreated).Msg("successfully created task list task")
+}
+
+func (tl taskList) UpdateTaskListTask(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.UpdateTaskListTaskRequest
+	if err := httputil.DecodeAndValidate(req, tl.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	taskUUID, err := uuid.Parse(chi.URLParam(req, "taskId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if reqBody.Name == "" && reqBody.Description == "" && reqBody.Checked == nil && reqBody.AssigneeId == "" && !reqBody.RemoveAssignee && reqBody.DueAt == "" && !reqBody.RemoveDueAt && reqBody.Priority == nil {
+		res.WriteHeader(http.StatusNoContent)
+		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
+		return
+	}
+
+	updateParams := repository.UpdateTaskListTaskParams{
+		ID:             pgtype.UUID{Bytes: taskUUID, Valid: true},
+		ListID:         listParams.ListUUID,
+		RemoveAssignee: reqBody.RemoveAssignee,
+		RemoveDueAt:    reqBody.RemoveDueAt,
+	}
+
+	if reqBody.Name != "" {
+		updateParams.Name = pgtype.Text{String: reqBody.Name, Valid: true}
+	}
+
+	if reqBody.Description != "" {
+		updateParams.Description = pgtype.Text{String: reqBody.Description, Valid: true}
+	}
+
+	if reqBody.Checked != nil {
+		updateParams.Checked = pgtype.Bool{Bool: *reqBody.Checked, Valid: true}
+	}
+
+	if reqBody.AssigneeId != "" {
+		assigneeUUID, err := uuid.Parse(reqBody.AssigneeId)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid assignee Id")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		updateParams.AssigneeID = pgtype.UUID{Bytes: assigneeUUID, Valid: true}
+	}
+
+	if reqBody.DueAt != "" {
+		dueAt, err := time.Parse(time.RFC3339, reqBody.DueAt)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid due_at")
+			http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		updateParams.DueAt = pgtype.Timestamptz{Time: dueAt, Valid: true}
+	}
+
+	if reqBody.Priority != nil {
+		updateParams.Priority = pgtype.Int2{Int16: *reqBody.Priority, Valid: true}
+	}
+
+	entitlements, err := tl.entitlement.GetEntitlements(ctx, listParams.UserUUID)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to get entitlements")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := tl.service.UpdateTaskListTask(ctx, services.UpdateTaskListTaskParams{
+		UserUUID:     listParams.UserUUID,
+		Params:       updateParams,
+		Entitlements: entitlements,
+	})
+
+	if err != nil {
+		sendTaskListError(res, req, err, "task not found", "failed to update task list task")
+		return
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    newTaskResponse(task, taskExtras{}),
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated task list task")
+}
+
+func (tl taskList) DeleteTaskListTask(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	listParams, ok := parseTaskListParams(res, req)
+	if !ok {
+		return
+	}
+
+	taskUUID, err := uuid.Parse(chi.URLParam(req, "taskId"))
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("task not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	err = tl.service.DeleteTaskListTask(ctx, services.DeleteTaskListTaskParams{
+		UserUUID: listParams.UserUUID,
+		ListUUID: listParams.ListUUID,
+		TaskUUID: pgtype.UUID{Bytes: taskUUID, Valid: true},
+	})
+
+	if err != nil {
+		sendTaskListError(res, req, err, "task not found", "failed to delete task list task")
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully deleted task list task")
+}
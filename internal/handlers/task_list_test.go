@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdayat/demi-masa-backend-service/internal/dtos"
+)
+
+func TestTaskListHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	var createdList dtos.TaskListResponse
+	t.Run("CreateTaskList/Success", func(t *testing.T) {
+		url := fmt.Sprintf("%s/lists", testServer.URL)
+		res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(`{"name": "family"}`)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&createdList); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if createdList.Name != "family" {
+			t.Errorf("expected name family, got %s", createdList.Name)
+		}
+	})
+
+	inviteTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "InviteTaskListMember/Bad Request",
+			reqBody:        `{"email": "not an email"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "InviteTaskListMember/Not Found",
+			reqBody:        `{"email": "nobody@example.com"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "InviteTaskListMember/Conflict",
+			reqBody:        `{"email": "example@gmail.com"}`,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, v := range inviteTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/lists/%s/members", testServer.URL, createdList.Id)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	var members []dtos.TaskListMemberResponse
+	t.Run("GetTaskListMembers/Success", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/lists/%s/members", testServer.URL, createdList.Id))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&members); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		expectedMembers := []dtos.TaskListMemberResponse{{UserId: createdList.OwnerId, Email: "example@gmail.com", Role: "owner"}}
+		if diff := cmp.Diff(expectedMembers, members, cmpopts.IgnoreFields(dtos.TaskListMemberResponse{}, "Name", "JoinedAt")); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	var createdTask dtos.TaskResponse
+	createTaskTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "CreateTaskListTask/Bad Request (assignee)",
+			reqBody:        `{"name": "groceries", "description": "buy rice", "assignee_id": "00000000-0000-0000-0000-000000000000"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CreateTaskListTask/Success",
+			reqBody:        fmt.Sprintf(`{"name": "groceries", "description": "buy rice", "assignee_id": "%s"}`, createdList.OwnerId),
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, v := range createTaskTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/lists/%s/tasks", testServer.URL, createdList.Id)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				if err := json.NewDecoder(res.Body).Decode(&createdTask); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if createdTask.ListId != createdList.Id || createdTask.AssigneeId != createdList.OwnerId {
+					t.Errorf("expected task of list %s assigned to %s, got %+v", createdList.Id, createdList.OwnerId, createdTask)
+				}
+			}
+		})
+	}
+
+	t.Run("UpdateTaskListTask/Success (checked)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/lists/%s/tasks/%s", testServer.URL, createdList.Id, createdTask.Id)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(`{"checked": true}`)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var updatedTask dtos.TaskResponse
+		if err := json.NewDecoder(res.Body).Decode(&updatedTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if !updatedTask.Checked || updatedTask.CompletedBy != createdList.OwnerId {
+			t.Errorf("expected task completed by %s, got %+v", createdList.OwnerId, updatedTask)
+		}
+	})
+
+	t.Run("GetTaskListTasks/Success", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/lists/%s/tasks", testServer.URL, createdList.Id))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var tasks []dtos.TaskResponse
+		if err := json.NewDecoder(res.Body).Decode(&tasks); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		if len(tasks) != 1 || tasks[0].Id != createdTask.Id {
+			t.Errorf("expected only task %s, got %+v", createdTask.Id, tasks)
+		}
+	})
+
+	deleteTable := []struct {
+		name           string
+		url            string
+		expectedStatus int
+	}{
+		{
+			name:           "RemoveTaskListMember/Bad Request (owner)",
+			url:            fmt.Sprintf("%s/lists/%s/members/%s", testServer.URL, createdList.Id, createdList.OwnerId),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "DeleteTaskListTask/Success",
+			url:            fmt.Sprintf("%s/lists/%s/tasks/%s", testServer.URL, createdList.Id, createdTask.Id),
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DeleteTaskList/Success",
+			url:            fmt.Sprintf("%s/lists/%s", testServer.URL, createdList.Id),
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "DeleteTaskList/Not Found",
+			url:            fmt.Sprintf("%s/lists/%s", testServer.URL, createdList.Id),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range deleteTable {
+		t.Run(v.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, v.url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/google/go-cmp/cmp"
@@ -32,6 +35,7 @@ func TestTaskHandlers(t *testing.T) {
 				Name:        "name",
 				Description: "description",
 				Checked:     false,
+				Labels:      []dtos.LabelResponse{},
 			},
 		},
 		{
@@ -44,6 +48,11 @@ func TestTaskHandlers(t *testing.T) {
 			reqBody:        `{"name": "name", "description": ""}`,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "CreateTask/Bad Request (due_at)",
+			reqBody:        `{"name": "name", "description": "description", "due_at": "2025-03-15"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, v := range createTaskTable {
@@ -64,7 +73,7 @@ func TestTaskHandlers(t *testing.T) {
 					t.Fatalf("unexpected response body: %v", res)
 				}
 
-				if diff := cmp.Diff(v.expectedResult, createdTask, cmpopts.IgnoreFields(dtos.TaskResponse{}, "Id")); diff != "" {
+				if diff := cmp.Diff(v.expectedResult, createdTask, cmpopts.IgnoreFields(dtos.TaskResponse{}, "Id", "Position", "CreatedAt")); diff != "" {
 					t.Error(diff)
 				}
 			}
@@ -119,6 +128,9 @@ func TestTaskHandlers(t *testing.T) {
 				Name:        "name changed",
 				Description: createdTask.Description,
 				Checked:     createdTask.Checked,
+				Labels:      []dtos.LabelResponse{},
+				Position:    createdTask.Position,
+				CreatedAt:   createdTask.CreatedAt,
 			},
 		},
 		{
@@ -139,6 +151,12 @@ func TestTaskHandlers(t *testing.T) {
 			reqBody:        `{"description": 1}`,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "UpdateTask/Bad Request (priority)",
+			taskId:         createdTask.Id,
+			reqBody:        `{"priority": 4}`,
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "UpdateTask/Not Found",
 			taskId:         uuid.NewString(),
@@ -215,3 +233,898 @@ func TestTaskHandlers(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskPrayerAnchor(t *testing.T) {
+	ctx := context.TODO()
+	var anchoredTask dtos.TaskResponse
+
+	createTaskTable := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedAnchor *dtos.TaskAnchor
+	}{
+		{
+			name:           "CreateTask/Success (anchored)",
+			reqBody:        `{"name": "name", "description": "description", "anchor": {"prayer": "zuhur", "relation": "after", "offset_in_minutes": 30}}`,
+			expectedStatus: http.StatusCreated,
+			expectedAnchor: &dtos.TaskAnchor{Prayer: "zuhur", Relation: "after", OffsetInMinutes: 30},
+		},
+		{
+			name:           "CreateTask/Bad Request (anchor prayer)",
+			reqBody:        `{"name": "name", "description": "description", "anchor": {"prayer": "dhuha", "relation": "after"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CreateTask/Bad Request (anchor offset)",
+			reqBody:        `{"name": "name", "description": "description", "anchor": {"prayer": "asar", "relation": "between", "offset_in_minutes": 30}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range createTaskTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusCreated {
+				if err := json.NewDecoder(res.Body).Decode(&anchoredTask); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(v.expectedAnchor, anchoredTask.Anchor); diff != "" {
+					t.Error(diff)
+				}
+
+				if anchoredTask.ScheduledAt == "" {
+					t.Error("expected scheduled_at to be set")
+				}
+			}
+		})
+	}
+
+	getTasksTable := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{
+			name:           "GetTasks/Success (group by prayer)",
+			query:          "?group_by=prayer&date=2025-03-15",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GetTasks/Bad Request (date)",
+			query:          "?date=15-03-2025",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GetTasks/Bad Request (group_by)",
+			query:          "?group_by=label",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range getTasksTable {
+		t.Run(v.name, func(t *testing.T) {
+			res, err := testClient.Get(fmt.Sprintf("%s/tasks%s", testServer.URL, v.query))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var groups []dtos.TaskGroupResponse
+				if err = json.NewDecoder(res.Body).Decode(&groups); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if len(groups) != 6 {
+					t.Fatalf("expected 6 groups, got %d", len(groups))
+				}
+
+				var found bool
+				for _, group := range groups {
+					for _, task := range group.Tasks {
+						if task.Id == anchoredTask.Id {
+							found = group.Prayer == "zuhur"
+						}
+					}
+				}
+
+				if !found {
+					t.Error("expected anchored task to be grouped in zuhur")
+				}
+			}
+		})
+	}
+
+	t.Run("UpdateTask/Success (remove anchor)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s", testServer.URL, anchoredTask.Id)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(`{"remove_anchor": true}`)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var updatedTask dtos.TaskResponse
+		if err := json.NewDecoder(res.Body).Decode(&updatedTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		expectedResult := dtos.TaskResponse{
+			Id:          anchoredTask.Id,
+			Name:        anchoredTask.Name,
+			Description: anchoredTask.Description,
+			Checked:     anchoredTask.Checked,
+			Labels:      []dtos.LabelResponse{},
+			Position:    anchoredTask.Position,
+			CreatedAt:   anchoredTask.CreatedAt,
+		}
+
+		if diff := cmp.Diff(expectedResult, updatedTask); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("DeleteTask/Success (anchored)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s", testServer.URL, anchoredTask.Id)
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+		}
+	})
+}
+
+func TestTaskRecurrence(t *testing.T) {
+	ctx := context.TODO()
+	var recurringTask dtos.TaskResponse
+
+	t.Run("CreateTask/Success (recurring)", func(t *testing.T) {
+		reqBody := `{"name": "name", "description": "description", "recurrence": {"rule": "RRULE:FREQ=WEEKLY;BYDAY=FR", "starts_on": "2025-03-03"}, "anchor": {"prayer": "zuhur", "relation": "after"}}`
+		res, err := testClient.Post(fmt.Sprintf("%s/tasks", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&recurringTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		expectedRecurrence := &dtos.TaskRecurrence{Rule: "FREQ=WEEKLY;BYDAY=FR", StartsOn: "2025-03-03"}
+		if diff := cmp.Diff(expectedRecurrence, recurringTask.Recurrence); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("CreateTask/Bad Request (recurrence rule)", func(t *testing.T) {
+		reqBody := `{"name": "name", "description": "description", "recurrence": {"rule": "FREQ=HOURLY", "starts_on": "2025-03-03"}}`
+		res, err := testClient.Post(fmt.Sprintf("%s/tasks", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	updateOccurrenceTable := []struct {
+		name           string
+		method         string
+		taskId         string
+		path           string
+		reqBody        string
+		expectedStatus int
+		expectedResult dtos.TaskOccurrenceResponse
+	}{
+		{
+			name:           "SkipTaskOccurrence/Success",
+			method:         http.MethodPost,
+			taskId:         recurringTask.Id,
+			path:           "2025-03-14/skip",
+			expectedStatus: http.StatusOK,
+			expectedResult: dtos.TaskOccurrenceResponse{
+				TaskId:      recurringTask.Id,
+				Date:        "2025-03-14",
+				Name:        "name",
+				Description: "description",
+				Skipped:     true,
+			},
+		},
+		{
+			name:           "UpdateTaskOccurrence/Success",
+			method:         http.MethodPut,
+			taskId:         recurringTask.Id,
+			path:           "2025-03-07",
+			reqBody:        `{"name": "name changed", "checked": true}`,
+			expectedStatus: http.StatusOK,
+			expectedResult: dtos.TaskOccurrenceResponse{
+				TaskId:      recurringTask.Id,
+				Date:        "2025-03-07",
+				Name:        "name changed",
+				Description: "description",
+				Checked:     true,
+			},
+		},
+		{
+			name:           "UpdateTaskOccurrence/Not Found (not an occurrence)",
+			method:         http.MethodPut,
+			taskId:         recurringTask.Id,
+			path:           "2025-03-08",
+			reqBody:        `{"checked": true}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "UpdateTaskOccurrence/Not Found (task)",
+			method:         http.MethodPut,
+			taskId:         uuid.NewString(),
+			path:           "2025-03-07",
+			reqBody:        `{"checked": true}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range updateOccurrenceTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s/occurrences/%s", testServer.URL, v.taskId, v.path)
+			req, err := http.NewRequestWithContext(ctx, v.method, url, bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus == http.StatusOK {
+				var occurrence dtos.TaskOccurrenceResponse
+				if err := json.NewDecoder(res.Body).Decode(&occurrence); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				ignoreFields := cmpopts.IgnoreFields(dtos.TaskOccurrenceResponse{}, "ScheduledAt", "ScheduledUntil")
+				if diff := cmp.Diff(v.expectedResult, occurrence, ignoreFields); diff != "" {
+					t.Error(diff)
+				}
+
+				if occurrence.ScheduledAt == "" {
+					t.Error("expected scheduled_at to be set")
+				}
+			}
+		})
+	}
+
+	t.Run("GetTaskOccurrences/Success", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s/occurrences?from=2025-03-01&to=2025-03-21", testServer.URL, recurringTask.Id)
+		res, err := testClient.Get(url)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var occurrences []dtos.TaskOccurrenceResponse
+		if err := json.NewDecoder(res.Body).Decode(&occurrences); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		expectedResult := []dtos.TaskOccurrenceResponse{
+			{TaskId: recurringTask.Id, Date: "2025-03-07", Name: "name changed", Description: "description", Checked: true},
+			{TaskId: recurringTask.Id, Date: "2025-03-14", Name: "name", Description: "description", Skipped: true},
+			{TaskId: recurringTask.Id, Date: "2025-03-21", Name: "name", Description: "description"},
+		}
+
+		ignoreFields := cmpopts.IgnoreFields(dtos.TaskOccurrenceResponse{}, "ScheduledAt", "ScheduledUntil")
+		if diff := cmp.Diff(expectedResult, occurrences, ignoreFields); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("GetTaskOccurrences/Bad Request (range)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s/occurrences?from=2025-03-21&to=2025-03-01", testServer.URL, recurringTask.Id)
+		res, err := testClient.Get(url)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("DeleteTask/Success (recurring)", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s", testServer.URL, recurringTask.Id)
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+		}
+	})
+}
+
+func TestTaskMove(t *testing.T) {
+	ctx := context.TODO()
+	movedTasks := make([]dtos.TaskResponse, 3)
+
+	for i := range movedTasks {
+		t.Run(fmt.Sprintf("CreateTask/Success (%d)", i), func(t *testing.T) {
+			reqBody := fmt.Sprintf(`{"name": "task %d", "description": "description"}`, i)
+			res, err := testClient.Post(fmt.Sprintf("%s/tasks", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+			}
+
+			if err := json.NewDecoder(res.Body).Decode(&movedTasks[i]); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+		})
+	}
+
+	moveTaskTable := []struct {
+		name           string
+		taskId         string
+		reqBody        string
+		expectedStatus int
+		expectedOrder  []string
+	}{
+		{
+			name:           "MoveTask/Success (before)",
+			taskId:         movedTasks[2].Id,
+			reqBody:        fmt.Sprintf(`{"before_id": "%s"}`, movedTasks[0].Id),
+			expectedStatus: http.StatusOK,
+			expectedOrder:  []string{movedTasks[2].Id, movedTasks[0].Id, movedTasks[1].Id},
+		},
+		{
+			name:           "MoveTask/Success (after)",
+			taskId:         movedTasks[2].Id,
+			reqBody:        fmt.Sprintf(`{"after_id": "%s"}`, movedTasks[0].Id),
+			expectedStatus: http.StatusOK,
+			expectedOrder:  []string{movedTasks[0].Id, movedTasks[2].Id, movedTasks[1].Id},
+		},
+		{
+			name:           "MoveTask/Bad Request (itself)",
+			taskId:         movedTasks[0].Id,
+			reqBody:        fmt.Sprintf(`{"after_id": "%s"}`, movedTasks[0].Id),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "MoveTask/Bad Request (both targets)",
+			taskId:         movedTasks[0].Id,
+			reqBody:        fmt.Sprintf(`{"before_id": "%s", "after_id": "%s"}`, movedTasks[1].Id, movedTasks[2].Id),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "MoveTask/Not Found (target)",
+			taskId:         movedTasks[0].Id,
+			reqBody:        fmt.Sprintf(`{"after_id": "%s"}`, uuid.NewString()),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, v := range moveTaskTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s/move", testServer.URL, v.taskId)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			res, err = testClient.Get(fmt.Sprintf("%s/tasks", testServer.URL))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			var tasks []dtos.TaskResponse
+			if err := json.NewDecoder(res.Body).Decode(&tasks); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			order := make([]string, 0, len(v.expectedOrder))
+			for _, task := range tasks {
+				if slices.Contains(v.expectedOrder, task.Id) {
+					order = append(order, task.Id)
+				}
+			}
+
+			if diff := cmp.Diff(v.expectedOrder, order); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	for i, movedTask := range movedTasks {
+		t.Run(fmt.Sprintf("DeleteTask/Success (%d)", i), func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s", testServer.URL, movedTask.Id)
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusNoContent {
+				t.Errorf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestTaskTrash(t *testing.T) {
+	ctx := context.TODO()
+	var trashedTask dtos.TaskResponse
+
+	t.Run("CreateTask/Success (trashed)", func(t *testing.T) {
+		reqBody := `{"name": "name", "description": "description"}`
+		res, err := testClient.Post(fmt.Sprintf("%s/tasks", testServer.URL), "application/json", bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&trashedTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+	})
+
+	trashTable := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedTask   *dtos.TaskResponse
+	}{
+		{
+			name:           "DeleteTask/Success (to trash)",
+			method:         http.MethodDelete,
+			path:           "tasks/" + trashedTask.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "UpdateTask/Not Found (trashed)",
+			method:         http.MethodPut,
+			path:           "tasks/" + trashedTask.Id,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "RestoreTask/Success",
+			method:         http.MethodPost,
+			path:           fmt.Sprintf("tasks/%s/restore", trashedTask.Id),
+			expectedStatus: http.StatusOK,
+			expectedTask:   &trashedTask,
+		},
+		{
+			name:           "RestoreTask/Not Found (not trashed)",
+			method:         http.MethodPost,
+			path:           fmt.Sprintf("tasks/%s/restore", trashedTask.Id),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "DeleteTask/Success (to trash again)",
+			method:         http.MethodDelete,
+			path:           "tasks/" + trashedTask.Id,
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, v := range trashTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/%s", testServer.URL, v.path)
+			req, err := http.NewRequestWithContext(ctx, v.method, url, bytes.NewBuffer([]byte(`{"name": "name changed"}`)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedTask != nil {
+				var restoredTask dtos.TaskResponse
+				if err := json.NewDecoder(res.Body).Decode(&restoredTask); err != nil {
+					t.Fatalf("unexpected response body: %v", res)
+				}
+
+				if diff := cmp.Diff(*v.expectedTask, restoredTask); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+
+	t.Run("GetTrashedTasks/Success", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/tasks/trash", testServer.URL))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var tasks []dtos.TaskResponse
+		if err := json.NewDecoder(res.Body).Decode(&tasks); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		index := slices.IndexFunc(tasks, func(task dtos.TaskResponse) bool {
+			return task.Id == trashedTask.Id
+		})
+
+		if index == -1 {
+			t.Fatalf("expected %s to be in the trash", trashedTask.Id)
+		}
+
+		if tasks[index].DeletedAt == "" {
+			t.Error("expected deleted_at to be set")
+		}
+	})
+}
+
+func TestTaskBulk(t *testing.T) {
+	var createdTasks []dtos.TaskResponse
+
+	bulkTable := []struct {
+		name             string
+		reqBody          func() string
+		expectedStatus   int
+		expectedStatuses []string
+	}{
+		{
+			name:           "BulkTasks/Bad Request (missing task Id)",
+			reqBody:        func() string { return `{"operations": [{"op": "delete"}]}` },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "BulkTasks/Success (create)",
+			reqBody: func() string {
+				return `{"operations": [
+					{"op": "create", "create": {"name": "first", "description": "description"}},
+					{"op": "create", "create": {"name": "second", "description": "description", "priority": 2}}
+				]}`
+			},
+			expectedStatus:   http.StatusOK,
+			expectedStatuses: []string{"ok", "ok"},
+		},
+		{
+			name: "BulkTasks/Not Found (rolled back)",
+			reqBody: func() string {
+				return fmt.Sprintf(`{"operations": [
+					{"op": "check", "task_id": "%s"},
+					{"op": "delete", "task_id": "00000000-0000-0000-0000-000000000000"}
+				]}`, createdTasks[0].Id)
+			},
+			expectedStatus:   http.StatusNotFound,
+			expectedStatuses: []string{"rolled_back", "failed"},
+		},
+		{
+			name: "BulkTasks/Bad Request (constraint, rolled back)",
+			reqBody: func() string {
+				return fmt.Sprintf(`{"operations": [
+					{"op": "create", "create": {"name": "third", "description": "description"}},
+					{"op": "update", "task_id": "%s", "update": {"name": "%s"}}
+				]}`, createdTasks[0].Id, strings.Repeat("a", 256))
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectedStatuses: []string{"rolled_back", "failed"},
+		},
+		{
+			name: "BulkTasks/Success (update, check and delete)",
+			reqBody: func() string {
+				return fmt.Sprintf(`{"operations": [
+					{"op": "update", "task_id": "%s", "update": {"name": "renamed"}},
+					{"op": "check", "task_id": "%s"},
+					{"op": "delete", "task_id": "%s"},
+					{"op": "delete", "task_id": "%s"}
+				]}`, createdTasks[0].Id, createdTasks[1].Id, createdTasks[0].Id, createdTasks[1].Id)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedStatuses: []string{"ok", "ok", "ok", "ok"},
+		},
+	}
+
+	for _, v := range bulkTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/bulk", testServer.URL)
+			res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(v.reqBody())))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatuses == nil {
+				return
+			}
+
+			var resBody dtos.BulkTaskResponse
+			if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			statuses := make([]string, 0, len(resBody.Results))
+			for _, result := range resBody.Results {
+				statuses = append(statuses, result.Status)
+				if result.Op == "create" && result.Task != nil {
+					createdTasks = append(createdTasks, *result.Task)
+				}
+			}
+
+			if diff := cmp.Diff(v.expectedStatuses, statuses); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("GetTrashedTasks/Success (rolled back check)", func(t *testing.T) {
+		res, err := testClient.Get(fmt.Sprintf("%s/tasks/trash", testServer.URL))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		var trashedTasks []dtos.TaskResponse
+		if err := json.NewDecoder(res.Body).Decode(&trashedTasks); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+
+		for _, trashedTask := range trashedTasks {
+			if trashedTask.Id == createdTasks[0].Id && (trashedTask.Checked || trashedTask.Name != "renamed") {
+				t.Errorf("expected renamed and unchecked task, got %+v", trashedTask)
+			}
+		}
+	})
+}
+
+func TestTaskStats(t *testing.T) {
+	ctx := context.TODO()
+
+	var createdTask dtos.TaskResponse
+	t.Run("CreateTask/Success", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks", testServer.URL)
+		reqBody := `{"name": "pay bills", "description": "electricity", "due_at": "2099-01-01T12:00:00Z"}`
+		res, err := testClient.Post(url, "application/json", bytes.NewBuffer([]byte(reqBody)))
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&createdTask); err != nil {
+			t.Fatalf("unexpected response body: %v", res)
+		}
+	})
+
+	checkTable := []struct {
+		name    string
+		reqBody string
+	}{
+		{
+			name:    "UpdateTask/Success (checked)",
+			reqBody: `{"checked": true}`,
+		},
+		{
+			name:    "UpdateTask/Success (checked again)",
+			reqBody: `{"checked": true}`,
+		},
+	}
+
+	var completedAt string
+	for _, v := range checkTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/tasks/%s", testServer.URL, createdTask.Id)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+			}
+
+			var updatedTask dtos.TaskResponse
+			if err := json.NewDecoder(res.Body).Decode(&updatedTask); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			if updatedTask.CompletedAt == "" {
+				t.Fatalf("expected completed_at to be set, got %+v", updatedTask)
+			}
+
+			// Checking an already checked task keeps its completion time.
+			if completedAt != "" && updatedTask.CompletedAt != completedAt {
+				t.Errorf("expected completed_at %s, got %s", completedAt, updatedTask.CompletedAt)
+			}
+			completedAt = updatedTask.CompletedAt
+		})
+	}
+
+	// The test user lives in Jakarta and is subscribed to premium, so the
+	// range isn't limited to the free tier.
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	today := time.Now().In(location)
+	statsTable := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedDays   int
+	}{
+		{
+			name:           "GetTaskStats/Success",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedDays:   28,
+		},
+		{
+			name:           "GetTaskStats/Success (extended range)",
+			query:          fmt.Sprintf("?from=%s", today.AddDate(0, 0, -89).Format(time.DateOnly)),
+			expectedStatus: http.StatusOK,
+			expectedDays:   90,
+		},
+		{
+			name:           "GetTaskStats/Bad Request",
+			query:          "?from=2025-03-10&to=2025-03-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GetTaskStats/Bad Request (range)",
+			query:          "?from=2023-01-01&to=2025-01-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range statsTable {
+		t.Run(v.name, func(t *testing.T) {
+			res, err := testClient.Get(fmt.Sprintf("%s/tasks/stats%s", testServer.URL, v.query))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+
+			if v.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var stats dtos.TaskStatsResponse
+			if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+				t.Fatalf("unexpected response body: %v", res)
+			}
+
+			if len(stats.Days) != v.expectedDays || len(stats.PrayerSlots) != 5 {
+				t.Errorf("expected %d days and 5 prayer slots, got %d and %d", v.expectedDays, len(stats.Days), len(stats.PrayerSlots))
+			}
+
+			if stats.Completed < 1 || stats.OnTime < 1 {
+				t.Errorf("expected the checked task to be completed on time, got %+v", stats)
+			}
+
+			if stats.Days[len(stats.Days)-1].Completed < 1 {
+				t.Errorf("expected a completion today, got %+v", stats.Days[len(stats.Days)-1])
+			}
+		})
+	}
+
+	t.Run("DeleteTask/Success", func(t *testing.T) {
+		url := fmt.Sprintf("%s/tasks/%s", testServer.URL, createdTask.Id)
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+
+		res, err := testClient.Do(req)
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d", http.StatusNoContent, res.StatusCode)
+		}
+	})
+}
@@ -2,18 +2,29 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/jwtkeys"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc/oidctest"
+	"github.com/mdayat/demi-masa-backend-service/repository"
 	"github.com/rs/zerolog"
 )
 
 var testServer *httptest.Server
 var testClient *http.Client
+var testGoogleIssuer *oidctest.Server
+
+// testQueries lets tests reset state that requests can't, like the throttle
+// of the test user.
+var testQueries *repository.Queries
 
 func TestMain(m *testing.M) {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
@@ -22,13 +33,49 @@ func TestMain(m *testing.M) {
 		log.Fatal(err)
 	}
 
+	testGoogleIssuer, err = oidctest.NewServer("test-client-id", "test-client-secret")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer testGoogleIssuer.Close()
+
+	googleConfig := testGoogleIssuer.Config()
+	env.GoogleClientID = googleConfig.ClientID
+	env.GoogleClientSecret = googleConfig.ClientSecret
+	env.GoogleIssuer = googleConfig.Issuer
+	env.GoogleTokenURL = googleConfig.TokenURL
+	env.GoogleJWKSURL = googleConfig.JWKSURL
+
+	if env.TOTPEncryptionKey == "" {
+		key := make([]byte, 32)
+		rand.Read(key)
+		env.TOTPEncryptionKey = base64.StdEncoding.EncodeToString(key)
+	}
+
 	ctx := context.TODO()
 	db, err := configs.NewDb(ctx, env.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	testQueries = db.Queries
+
+	// Sign with a key of the ring, like production does.
+	privateKey, err := jwtkeys.GenerateKey("EdDSA")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	keyRing, err := jwtkeys.NewKeyRing([]byte(env.SecretKey), jwtkeys.Key{
+		ID:         "test",
+		ActiveFrom: time.Now().Add(-time.Hour),
+		PrivateKey: privateKey,
+	})
+
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	configs := configs.NewConfigs(env, db)
+	configs := configs.NewConfigs(env, db, keyRing)
 	authenticator := NewTestAuthenticator(configs)
 
 	customMiddleware := NewMiddlewareHandler(configs, authenticator)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -23,6 +24,8 @@ type UserHandler interface {
 	GetUser(res http.ResponseWriter, req *http.Request)
 	DeleteUser(res http.ResponseWriter, req *http.Request)
 	UpdateUser(res http.ResponseWriter, req *http.Request)
+	ChangePassword(res http.ResponseWriter, req *http.Request)
+	UpdateUserRole(res http.ResponseWriter, req *http.Request)
 }
 
 type user struct {
@@ -79,10 +82,15 @@ func (u user) GetUser(res http.ResponseWriter, req *http.Request) {
 		Longitude:    user.Coordinates.P.X,
 		City:         user.City,
 		Timezone:     user.Timezone,
+		Role:         user.Role,
 		CreatedAt:    user.CreatedAt.Time.Format(time.RFC3339),
 		Subscription: userSubscription,
 	}
 
+	if user.EmailVerifiedAt.Valid {
+		resBody.EmailVerifiedAt = user.EmailVerifiedAt.Time.Format(time.RFC3339)
+	}
+
 	params := httputil.SendSuccessResponseParams{
 		StatusCode: http.StatusOK,
 		ResBody:    resBody,
@@ -137,7 +145,7 @@ func (u user) UpdateUser(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if reqBody.Email == "" && reqBody.Password == "" && reqBody.Username == "" && reqBody.Latitude == "" && reqBody.Longitude == "" {
+	if reqBody.Email == "" && reqBody.Username == "" && reqBody.Latitude == "" && reqBody.Longitude == "" {
 		res.WriteHeader(http.StatusNoContent)
 		logger.Info().Int("status_code", http.StatusNoContent).Msg("no update performed")
 		return
@@ -148,11 +156,6 @@ func (u user) UpdateUser(res http.ResponseWriter, req *http.Request) {
 		email = pgtype.Text{String: reqBody.Email, Valid: true}
 	}
 
-	var password pgtype.Text
-	if reqBody.Password != "" {
-		password = pgtype.Text{String: reqBody.Password, Valid: true}
-	}
-
 	var name pgtype.Text
 	if reqBody.Username != "" {
 		name = pgtype.Text{String: reqBody.Username, Valid: true}
@@ -193,7 +196,6 @@ func (u user) UpdateUser(res http.ResponseWriter, req *http.Request) {
 		return u.configs.Db.Queries.UpdateUser(ctx, repository.UpdateUserParams{
 			ID:          pgtype.UUID{Bytes: userUUID, Valid: true},
 			Email:       email,
-			Password:    password,
 			Name:        name,
 			Coordinates: coordinates,
 			City:        city,
@@ -220,9 +222,14 @@ func (u user) UpdateUser(res http.ResponseWriter, req *http.Request) {
 		Longitude: user.Coordinates.P.X,
 		City:      user.City,
 		Timezone:  user.Timezone,
+		Role:      user.Role,
 		CreatedAt: user.CreatedAt.Time.Format(time.RFC3339),
 	}
 
+	if user.EmailVerifiedAt.Valid {
+		resBody.EmailVerifiedAt = user.EmailVerifiedAt.Time.Format(time.RFC3339)
+	}
+
 	params := httputil.SendSuccessResponseParams{
 		StatusCode: http.StatusOK,
 		ResBody:    resBody,
@@ -236,3 +243,138 @@ func (u user) UpdateUser(res http.ResponseWriter, req *http.Request) {
 
 	logger.Info().Int("status_code", http.StatusOK).Msg("successfully updated user")
 }
+
+func (u user) ChangePassword(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	var reqBody dtos.ChangePasswordRequest
+	if err := httputil.DecodeAndValidate(req, u.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	userId := ctx.Value(userIdKey{}).(string)
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse user Id to UUID")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var currentSessionUUID pgtype.UUID
+	if sessionId, _ := ctx.Value(sessionIdKey{}).(string); sessionId != "" {
+		sessionUUID, err := uuid.Parse(sessionId)
+		if err != nil {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to parse session Id to UUID")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		currentSessionUUID = pgtype.UUID{Bytes: sessionUUID, Valid: true}
+	}
+
+	err = u.service.ChangePassword(ctx, services.ChangePasswordParams{
+		UserUUID:           pgtype.UUID{Bytes: userUUID, Valid: true},
+		CurrentPassword:    reqBody.CurrentPassword,
+		NewPassword:        reqBody.NewPassword,
+		CurrentSessionUUID: currentSessionUUID,
+	})
+
+	if err != nil {
+		var throttledErr *services.LoginThrottledError
+		if errors.As(err, &throttledErr) {
+			sendLoginThrottledError(res, req, throttledErr)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("user not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else if errors.Is(err, services.ErrWrongPassword) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusForbidden).Msg("wrong current password")
+			http.Error(res, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to change password")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+	logger.Info().Int("status_code", http.StatusNoContent).Msg("successfully changed password")
+}
+
+// UpdateUserRole changes the role of another user, it applies once their
+// access token is refreshed. Admins can't change their own role, so there is
+// always an admin left.
+func (u user) UpdateUserRole(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.Ctx(ctx).With().Logger()
+
+	userId := chi.URLParam(req, "userId")
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("user not found")
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if userId == ctx.Value(userIdKey{}).(string) {
+		logger.Error().Err(errors.New("can't change own role")).Caller().Int("status_code", http.StatusConflict).Send()
+		http.Error(res, http.StatusText(http.StatusConflict), http.StatusConflict)
+		return
+	}
+
+	var reqBody dtos.UserRoleRequest
+	if err := httputil.DecodeAndValidate(req, u.configs.Validate, &reqBody); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusBadRequest).Msg("invalid request body")
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	user, err := retryutil.RetryWithData(func() (repository.User, error) {
+		return u.configs.Db.Queries.UpdateUserRole(ctx, repository.UpdateUserRoleParams{
+			ID:   pgtype.UUID{Bytes: userUUID, Valid: true},
+			Role: reqBody.Role,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusNotFound).Msg("user not found")
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to update user role")
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resBody := dtos.UserResponse{
+		Id:        user.ID.String(),
+		Email:     user.Email,
+		Name:      user.Name,
+		Latitude:  user.Coordinates.P.Y,
+		Longitude: user.Coordinates.P.X,
+		City:      user.City,
+		Timezone:  user.Timezone,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		resBody.EmailVerifiedAt = user.EmailVerifiedAt.Time.Format(time.RFC3339)
+	}
+
+	params := httputil.SendSuccessResponseParams{
+		StatusCode: http.StatusOK,
+		ResBody:    resBody,
+	}
+
+	if err := httputil.SendSuccessResponse(res, params); err != nil {
+		logger.Error().Err(err).Caller().Int("status_code", http.StatusInternalServerError).Msg("failed to send success response")
+		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info().Int("status_code", http.StatusOK).Str("role", user.Role).Msg("successfully updated user role")
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+func TestUserHandlers(t *testing.T) {
+	ctx := context.TODO()
+
+	// Wrong current passwords are throttled, so earlier runs mustn't count.
+	resetThrottle := func() {
+		err := testQueries.DeleteLoginThrottle(ctx, repository.DeleteLoginThrottleParams{Scope: "email", Subject: "example@gmail.com"})
+		if err != nil {
+			t.Fatalf("wasn't expecting error, got: %v", err)
+		}
+	}
+	resetThrottle()
+	t.Cleanup(resetThrottle)
+
+	userTable := []struct {
+		name           string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "UpdateUser/Bad Request (password)",
+			path:           "users/me",
+			reqBody:        `{"password": "new-password"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ChangePassword/Forbidden (wrong current password)",
+			path:           "users/me/password",
+			reqBody:        `{"current_password": "wrong-password", "new_password": "new-password"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "ChangePassword/Bad Request (new password)",
+			path:           "users/me/password",
+			reqBody:        `{"current_password": "example", "new_password": "short"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ChangePassword/Bad Request (same password)",
+			path:           "users/me/password",
+			reqBody:        `{"current_password": "new-password", "new_password": "new-password"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, v := range userTable {
+		t.Run(v.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/%s", testServer.URL, v.path)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(v.reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != v.expectedStatus {
+				t.Fatalf("expected status %d, got %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("ChangePassword/Too Many Requests (wrong current passwords)", func(t *testing.T) {
+		resetThrottle()
+		reqBody := `{"current_password": "wrong-password", "new_password": "new-password"}`
+		for i := range 6 {
+			url := fmt.Sprintf("%s/users/me/password", testServer.URL)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer([]byte(reqBody)))
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			res, err := testClient.Do(req)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+			res.Body.Close()
+
+			// The free attempts are refused, the next one has to wait.
+			expectedStatus := http.StatusForbidden
+			if i == 5 {
+				expectedStatus = http.StatusTooManyRequests
+			}
+
+			if res.StatusCode != expectedStatus {
+				t.Fatalf("expected status %d on attempt %d, got %d", expectedStatus, i+1, res.StatusCode)
+			}
+		}
+	})
+}
@@ -26,3 +26,17 @@ func SendSuccessResponse(res http.ResponseWriter, params SendSuccessResponsePara
 	res.WriteHeader(params.StatusCode)
 	return json.NewEncoder(res).Encode(params.ResBody)
 }
+
+type SendErrorResponseParams struct {
+	StatusCode int
+	ResBody    interface{}
+}
+
+// SendErrorResponse is for errors that need more detail than the status
+// text sent by http.Error.
+func SendErrorResponse(res http.ResponseWriter, params SendErrorResponseParams) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.WriteHeader(params.StatusCode)
+	return json.NewEncoder(res).Encode(params.ResBody)
+}
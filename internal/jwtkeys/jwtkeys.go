@@ -0,0 +1,318 @@
+// Package jwtkeys signs and verifies the access and refresh tokens with a ring
+// of asymmetric keys. Keys rotate on a schedule: each key signs from its
+// ActiveFrom until the next key becomes active, and verifies until it expires.
+// The public keys are published as a JWKS, so other services can verify access
+// tokens without a shared secret.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc"
+)
+
+// LegacyGracePeriod is how long HS256 tokens are still accepted once the first
+// key becomes active. It is the lifetime of refresh tokens, so switching to
+// the key ring doesn't sign anyone out.
+const LegacyGracePeriod = 30 * 24 * time.Hour
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("unknown or expired key")
+)
+
+type Key struct {
+	ID         string
+	ActiveFrom time.Time
+	// ExpiresAt is when the key stops verifying. It should be at least the
+	// lifetime of refresh tokens after the next key becomes active. Zero
+	// means it never expires.
+	ExpiresAt  time.Time
+	PrivateKey crypto.Signer
+}
+
+func (k Key) method() (jwt.SigningMethod, error) {
+	switch k.PrivateKey.(type) {
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	default:
+		return nil, fmt.Errorf("unsupported type of key %s: %T", k.ID, k.PrivateKey)
+	}
+}
+
+func (k Key) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+type KeyRing struct {
+	// keys are sorted by ActiveFrom.
+	keys []Key
+	// secret signs HS256 tokens while no key is active yet, and verifies them
+	// during the LegacyGracePeriod.
+	secret []byte
+}
+
+// NewKeyRing returns a key ring of the keys. Without keys, tokens are signed
+// with HS256 and the secret, as before the key ring existed.
+func NewKeyRing(secret []byte, keys ...Key) (*KeyRing, error) {
+	keys = slices.Clone(keys)
+	slices.SortFunc(keys, func(a, b Key) int {
+		return a.ActiveFrom.Compare(b.ActiveFrom)
+	})
+
+	keyIds := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("key Id is required")
+		}
+
+		if keyIds[key.ID] {
+			return nil, fmt.Errorf("duplicate key Id: %s", key.ID)
+		}
+		keyIds[key.ID] = true
+
+		if _, err := key.method(); err != nil {
+			return nil, err
+		}
+
+		if !key.ExpiresAt.IsZero() && !key.ExpiresAt.After(key.ActiveFrom) {
+			return nil, fmt.Errorf("key %s expires before it becomes active", key.ID)
+		}
+	}
+
+	return &KeyRing{keys: keys, secret: secret}, nil
+}
+
+// signingKey returns the key that became active last. It returns false when
+// no key is active yet, in which case tokens are signed with the secret.
+func (k *KeyRing) signingKey(now time.Time) (Key, bool, error) {
+	if len(k.keys) == 0 || now.Before(k.keys[0].ActiveFrom) {
+		return Key{}, false, nil
+	}
+
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		key := k.keys[i]
+		if !now.Before(key.ActiveFrom) && !key.expired(now) {
+			return key, true, nil
+		}
+	}
+
+	return Key{}, false, ErrNoSigningKey
+}
+
+// acceptsLegacy reports whether HS256 tokens are still accepted.
+func (k *KeyRing) acceptsLegacy(now time.Time) bool {
+	if len(k.secret) == 0 {
+		return false
+	}
+	return len(k.keys) == 0 || now.Before(k.keys[0].ActiveFrom.Add(LegacyGracePeriod))
+}
+
+// Sign signs the claims with the current key, and sets the kid header to its
+// Id.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	key, ok, err := k.signingKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.secret)
+	}
+
+	method, err := key.method()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// Keyfunc returns the public key of the token's kid for jwt.Parse.
+func (k *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	now := time.Now()
+	if token.Method == jwt.SigningMethodHS256 {
+		if !k.acceptsLegacy(now) {
+			return nil, fmt.Errorf("%w: HS256 isn't accepted anymore", ErrUnknownKey)
+		}
+		return k.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range k.keys {
+		if key.ID != kid || key.expired(now) {
+			continue
+		}
+
+		method, err := key.method()
+		if err != nil {
+			return nil, err
+		}
+
+		if method != token.Method {
+			return nil, fmt.Errorf("key %s doesn't sign with %s", kid, token.Method.Alg())
+		}
+
+		return key.PrivateKey.Public(), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+}
+
+// ValidMethods returns the algorithms tokens may be signed with.
+func (k *KeyRing) ValidMethods() []string {
+	return []string{
+		jwt.SigningMethodEdDSA.Alg(),
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodHS256.Alg(),
+	}
+}
+
+// JWKS returns the public keys that aren't expired, including the ones that
+// aren't active yet, so verifiers already have them once they sign.
+func (k *KeyRing) JWKS() oidc.JSONWebKeySet {
+	now := time.Now()
+	keySet := oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{}}
+	for _, key := range k.keys {
+		if key.expired(now) {
+			continue
+		}
+
+		switch publicKey := key.PrivateKey.Public().(type) {
+		case ed25519.PublicKey:
+			keySet.Keys = append(keySet.Keys, oidc.NewEd25519JSONWebKey(key.ID, publicKey))
+		case *rsa.PublicKey:
+			keySet.Keys = append(keySet.Keys, oidc.NewRSAJSONWebKey(key.ID, publicKey))
+		}
+	}
+
+	return keySet
+}
+
+type keyFile struct {
+	Keys []struct {
+		ID string `json:"kid"`
+		// PrivateKey is a PKCS #8 PEM block. PrivateKeyFile is read instead
+		// when it is empty, relative to the key file.
+		PrivateKey     string    `json:"private_key"`
+		PrivateKeyFile string    `json:"private_key_file"`
+		ActiveFrom     time.Time `json:"active_from"`
+		ExpiresAt      time.Time `json:"expires_at"`
+	} `json:"keys"`
+}
+
+// Load reads the key ring from a JSON file like:
+//
+//	{
+//	  "keys": [
+//	    {
+//	      "kid": "2025-04",
+//	      "private_key_file": "2025-04.pem",
+//	      "active_from": "2025-04-01T00:00:00Z",
+//	      "expires_at": "2025-06-01T00:00:00Z"
+//	    }
+//	  ]
+//	}
+func Load(path string, secret []byte) (*KeyRing, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode key file: %w", err)
+	}
+
+	keys := make([]Key, 0, len(file.Keys))
+	for _, v := range file.Keys {
+		privateKeyPEM := []byte(v.PrivateKey)
+		if v.PrivateKey == "" {
+			privateKeyPath := v.PrivateKeyFile
+			if !filepath.IsAbs(privateKeyPath) {
+				privateKeyPath = filepath.Join(filepath.Dir(path), privateKeyPath)
+			}
+
+			privateKeyPEM, err = os.ReadFile(privateKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read private key of key %s: %w", v.ID, err)
+			}
+		}
+
+		privateKey, err := ParsePrivateKey(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key of key %s: %w", v.ID, err)
+		}
+
+		keys = append(keys, Key{
+			ID:         v.ID,
+			ActiveFrom: v.ActiveFrom,
+			ExpiresAt:  v.ExpiresAt,
+			PrivateKey: privateKey,
+		})
+	}
+
+	return NewKeyRing(secret, keys...)
+}
+
+// GenerateKey generates a private key for the algorithm, EdDSA or RS256.
+func GenerateKey(alg string) (crypto.Signer, error) {
+	switch alg {
+	case jwt.SigningMethodEdDSA.Alg():
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	case jwt.SigningMethodRS256.Alg():
+		return rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+}
+
+// MarshalPrivateKey encodes the private key as a PKCS #8 PEM block.
+func MarshalPrivateKey(privateKey crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKey decodes a PKCS #8 PEM block of an Ed25519 or RSA key.
+func ParsePrivateKey(privateKeyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("expected a PKCS #8 PEM block")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch privateKey := privateKey.(type) {
+	case ed25519.PrivateKey:
+		return privateKey, nil
+	case *rsa.PrivateKey:
+		return privateKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported type of private key: %T", privateKey)
+	}
+}
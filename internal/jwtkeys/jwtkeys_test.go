@@ -0,0 +1,220 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func generateKey(t *testing.T, alg string) crypto.Signer {
+	t.Helper()
+
+	privateKey, err := GenerateKey(alg)
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	return privateKey
+}
+
+func parse(keyRing *KeyRing, tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, keyRing.Keyfunc, jwt.WithValidMethods(keyRing.ValidMethods()))
+}
+
+func TestSign(t *testing.T) {
+	now := time.Now()
+	secret := []byte("secret")
+
+	oldKey := Key{ID: "old", ActiveFrom: now.Add(-48 * time.Hour), PrivateKey: generateKey(t, "RS256")}
+	currentKey := Key{ID: "current", ActiveFrom: now.Add(-time.Hour), PrivateKey: generateKey(t, "EdDSA")}
+	nextKey := Key{ID: "next", ActiveFrom: now.Add(time.Hour), PrivateKey: generateKey(t, "EdDSA")}
+	expiredKey := Key{ID: "expired", ActiveFrom: now.Add(-72 * time.Hour), ExpiresAt: now.Add(-time.Minute), PrivateKey: generateKey(t, "EdDSA")}
+
+	signTable := []struct {
+		name        string
+		keys        []Key
+		expectedAlg string
+		expectedKid string
+		expectedErr bool
+	}{
+		{name: "Sign/Without keys", expectedAlg: "HS256"},
+		{name: "Sign/Before first key is active", keys: []Key{nextKey}, expectedAlg: "HS256"},
+		{name: "Sign/Latest active key", keys: []Key{nextKey, oldKey, currentKey}, expectedAlg: "EdDSA", expectedKid: "current"},
+		{name: "Sign/RS256", keys: []Key{oldKey}, expectedAlg: "RS256", expectedKid: "old"},
+		{name: "Sign/Only expired keys", keys: []Key{expiredKey}, expectedErr: true},
+	}
+
+	for _, v := range signTable {
+		t.Run(v.name, func(t *testing.T) {
+			keyRing, err := NewKeyRing(secret, v.keys...)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			tokenString, err := keyRing.Sign(jwt.RegisteredClaims{Subject: "user"})
+			if v.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			token, err := parse(keyRing, tokenString)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			if token.Method.Alg() != v.expectedAlg {
+				t.Fatalf("expected alg %s, got %s", v.expectedAlg, token.Method.Alg())
+			}
+
+			if kid, _ := token.Header["kid"].(string); kid != v.expectedKid {
+				t.Fatalf("expected kid %q, got %q", v.expectedKid, kid)
+			}
+		})
+	}
+}
+
+func TestKeyfunc(t *testing.T) {
+	now := time.Now()
+	secret := []byte("secret")
+	privateKey := generateKey(t, "EdDSA")
+
+	legacyRing, _ := NewKeyRing(secret)
+	legacyToken, _ := legacyRing.Sign(jwt.RegisteredClaims{Subject: "user"})
+
+	signingRing, _ := NewKeyRing(secret, Key{ID: "key", ActiveFrom: now.Add(-time.Hour), PrivateKey: privateKey})
+	signedToken, _ := signingRing.Sign(jwt.RegisteredClaims{Subject: "user"})
+
+	keyfuncTable := []struct {
+		name        string
+		keys        []Key
+		tokenString string
+		expectedErr bool
+	}{
+		{
+			name:        "Keyfunc/Legacy token within grace period",
+			keys:        []Key{{ID: "key", ActiveFrom: now.Add(-time.Hour), PrivateKey: privateKey}},
+			tokenString: legacyToken,
+		},
+		{
+			name:        "Keyfunc/Legacy token after grace period",
+			keys:        []Key{{ID: "key", ActiveFrom: now.Add(-LegacyGracePeriod - time.Hour), PrivateKey: privateKey}},
+			tokenString: legacyToken,
+			expectedErr: true,
+		},
+		{
+			name:        "Keyfunc/Rotated key still verifies",
+			keys:        []Key{{ID: "key", ActiveFrom: now.Add(-time.Hour), PrivateKey: privateKey}, {ID: "new", ActiveFrom: now.Add(-time.Minute), PrivateKey: generateKey(t, "EdDSA")}},
+			tokenString: signedToken,
+		},
+		{
+			name:        "Keyfunc/Expired key",
+			keys:        []Key{{ID: "key", ActiveFrom: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute), PrivateKey: privateKey}},
+			tokenString: signedToken,
+			expectedErr: true,
+		},
+		{
+			name:        "Keyfunc/Unknown key",
+			keys:        []Key{{ID: "other", ActiveFrom: now.Add(-time.Hour), PrivateKey: generateKey(t, "EdDSA")}},
+			tokenString: signedToken,
+			expectedErr: true,
+		},
+		{
+			name:        "Keyfunc/Same kid with another key",
+			keys:        []Key{{ID: "key", ActiveFrom: now.Add(-time.Hour), PrivateKey: generateKey(t, "EdDSA")}},
+			tokenString: signedToken,
+			expectedErr: true,
+		},
+	}
+
+	for _, v := range keyfuncTable {
+		t.Run(v.name, func(t *testing.T) {
+			keyRing, err := NewKeyRing(secret, v.keys...)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			_, err = parse(keyRing, v.tokenString)
+			if v.expectedErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if !v.expectedErr && err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	now := time.Now()
+	keyRing, err := NewKeyRing(
+		nil,
+		Key{ID: "expired", ActiveFrom: now.Add(-72 * time.Hour), ExpiresAt: now.Add(-time.Minute), PrivateKey: generateKey(t, "EdDSA")},
+		Key{ID: "current", ActiveFrom: now.Add(-time.Hour), PrivateKey: generateKey(t, "RS256")},
+		Key{ID: "next", ActiveFrom: now.Add(time.Hour), PrivateKey: generateKey(t, "EdDSA")},
+	)
+
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	keySet := keyRing.JWKS()
+	if len(keySet.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keySet.Keys))
+	}
+
+	if keySet.Keys[0].Kid != "current" || keySet.Keys[0].Kty != "RSA" || keySet.Keys[0].N == "" {
+		t.Fatalf("unexpected key: %+v", keySet.Keys[0])
+	}
+
+	if keySet.Keys[1].Kid != "next" || keySet.Keys[1].Kty != "OKP" || keySet.Keys[1].X == "" {
+		t.Fatalf("unexpected key: %+v", keySet.Keys[1])
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	privateKeyPEM, err := MarshalPrivateKey(generateKey(t, "EdDSA"))
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "key.pem"), privateKeyPEM, 0o600); err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	keyFile := `{"keys": [{"kid": "key", "private_key_file": "key.pem", "active_from": "2025-04-01T00:00:00Z"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "keys.json"), []byte(keyFile), 0o600); err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	keyRing, err := Load(filepath.Join(dir, "keys.json"), nil)
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	tokenString, err := keyRing.Sign(jwt.RegisteredClaims{Subject: "user"})
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	token, err := parse(keyRing, tokenString)
+	if err != nil {
+		t.Fatalf("wasn't expecting error, got: %v", err)
+	}
+
+	if token.Header["kid"] != "key" {
+		t.Fatalf("expected kid %q, got %v", "key", token.Header["kid"])
+	}
+}
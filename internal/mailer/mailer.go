@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var errInvalidHeader = errors.New("header contains a line break")
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &smtpMailer{
+		config: config,
+	}
+}
+
+func (s smtpMailer) Send(_ context.Context, msg Message) error {
+	for _, header := range []string{s.config.From, msg.To, msg.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return errInvalidHeader
+		}
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := smtp.SendMail(addr, auth, s.config.From, []string{msg.To}, body.Bytes()); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
+
+// logMailer writes mails to the request logger instead of sending them, for
+// development and tests.
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (l logMailer) Send(ctx context.Context, msg Message) error {
+	log.Ctx(ctx).Info().
+		Str("mail_to", msg.To).
+		Str("mail_subject", msg.Subject).
+		Str("mail_body", msg.Body).
+		Msg("mail logged instead of sent")
+	return nil
+}
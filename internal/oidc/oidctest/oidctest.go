@@ -0,0 +1,222 @@
+// Package oidctest provides an OpenID provider that stands in for Google in
+// tests and local development. It signs in whoever it is asked to, so it must
+// never be used in production.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc"
+)
+
+const keyId = "oidctest"
+
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type authorization struct {
+	identity    Identity
+	nonce       string
+	redirectURI string
+}
+
+type Issuer struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func NewIssuer(issuerURL, clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+
+	return &Issuer{
+		URL:          issuerURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]authorization),
+	}, nil
+}
+
+// Config returns the provider config pointing to the issuer.
+func (i *Issuer) Config() oidc.Config {
+	return oidc.Config{
+		Issuer:       i.URL,
+		ClientID:     i.ClientID,
+		ClientSecret: i.ClientSecret,
+		TokenURL:     i.URL + "/token",
+		JWKSURL:      i.URL + "/jwks",
+	}
+}
+
+// IssueCode returns a single-use authorization code that signs in the
+// identity.
+func (i *Issuer) IssueCode(identity Identity, nonce, redirectURI string) string {
+	codeBytes := make([]byte, 16)
+	rand.Read(codeBytes)
+	code := base64.RawURLEncoding.EncodeToString(codeBytes)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.codes[code] = authorization{identity: identity, nonce: nonce, redirectURI: redirectURI}
+
+	return code
+}
+
+func (i *Issuer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /authorize", i.authorize)
+	mux.HandleFunc("POST /token", i.token)
+	mux.HandleFunc("GET /jwks", i.jwks)
+	return mux
+}
+
+// authorize signs in the email of login_hint without asking anything and
+// redirects back with the code.
+func (i *Issuer) authorize(res http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	if query.Get("client_id") != i.ClientID {
+		http.Error(res, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(res, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := query.Get("login_hint")
+	if email == "" {
+		http.Error(res, "login_hint is required", http.StatusBadRequest)
+		return
+	}
+
+	code := i.IssueCode(Identity{
+		Subject:       "oidctest-" + email,
+		Email:         email,
+		EmailVerified: true,
+		Name:          email,
+	}, query.Get("nonce"), redirectURI.String())
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	if state := query.Get("state"); state != "" {
+		values.Set("state", state)
+	}
+	redirectURI.RawQuery = values.Encode()
+
+	http.Redirect(res, req, redirectURI.String(), http.StatusFound)
+}
+
+func (i *Issuer) token(res http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		sendTokenError(res, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	if req.PostForm.Get("client_id") != i.ClientID || req.PostForm.Get("client_secret") != i.ClientSecret {
+		sendTokenError(res, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	code := req.PostForm.Get("code")
+	i.mu.Lock()
+	authorization, ok := i.codes[code]
+	delete(i.codes, code)
+	i.mu.Unlock()
+
+	if !ok || req.PostForm.Get("grant_type") != "authorization_code" || req.PostForm.Get("redirect_uri") != authorization.redirectURI {
+		sendTokenError(res, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := oidc.Claims{
+		Email:         authorization.identity.Email,
+		EmailVerified: authorization.identity.EmailVerified,
+		Name:          authorization.identity.Name,
+		Nonce:         authorization.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.URL,
+			Subject:   authorization.identity.Subject,
+			Audience:  jwt.ClaimStrings{i.ClientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyId
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		sendTokenError(res, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(map[string]any{
+		"access_token": "oidctest",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (i *Issuer) jwks(res http.ResponseWriter, _ *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(oidc.JSONWebKeySet{
+		Keys: []oidc.JSONWebKey{oidc.NewRSAJSONWebKey(keyId, &i.key.PublicKey)},
+	})
+}
+
+func sendTokenError(res http.ResponseWriter, statusCode int, code string) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(statusCode)
+	json.NewEncoder(res).Encode(map[string]string{"error": code})
+}
+
+type Server struct {
+	*Issuer
+	server *httptest.Server
+}
+
+// NewServer starts an issuer on a local port.
+func NewServer(clientID, clientSecret string) (*Server, error) {
+	server := httptest.NewUnstartedServer(nil)
+	issuer, err := NewIssuer("http://"+server.Listener.Addr().String(), clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	server.Config.Handler = issuer.Handler()
+	server.Start()
+
+	return &Server{Issuer: issuer, server: server}, nil
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
@@ -9,3 +9,9 @@ func RetryWithData[T any](f func() (T, error)) (T, error) {
 func RetryWithoutData(f func() error) error {
 	return retry.Do(f, retry.Attempts(3), retry.LastErrorOnly(true))
 }
+
+// Unrecoverable marks err so RetryWithData and RetryWithoutData return it right
+// away instead of retrying. The returned error still unwraps to err.
+func Unrecoverable(err error) error {
+	return retry.Unrecoverable(err)
+}
@@ -0,0 +1,322 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used by
+// recurring tasks: FREQ=DAILY, WEEKLY and MONTHLY together with INTERVAL,
+// BYDAY, BYMONTHDAY, COUNT and UNTIL. Rules operate on whole days, so every
+// occurrence is a date at midnight UTC.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// WeekdayNum is a BYDAY entry. Nth is only meaningful for monthly rules, where
+// 1FR is the first Friday of the month and -1FR the last one. Zero means every
+// matching weekday.
+type WeekdayNum struct {
+	Nth     int
+	Weekday time.Weekday
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxIterations bounds the number of days walked while expanding a rule, which
+// is a little over 100 years.
+const maxIterations = 36600
+
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, errors.New("empty rule")
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("invalid rule part: %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				return Rule{}, fmt.Errorf("unsupported frequency: %s", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("invalid interval: %s", value)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return Rule{}, fmt.Errorf("invalid count: %s", value)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekdayNum, err := parseWeekdayNum(day)
+				if err != nil {
+					return Rule{}, err
+				}
+				rule.ByDay = append(rule.ByDay, weekdayNum)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return Rule{}, fmt.Errorf("invalid month day: %s", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return Rule{}, fmt.Errorf("unsupported week start: %s", value)
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part: %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, errors.New("missing frequency")
+	}
+
+	if rule.Count != 0 && !rule.Until.IsZero() {
+		return Rule{}, errors.New("count and until are mutually exclusive")
+	}
+
+	if rule.Freq != Monthly {
+		for _, weekdayNum := range rule.ByDay {
+			if weekdayNum.Nth != 0 {
+				return Rule{}, errors.New("numbered weekdays are only supported by monthly rules")
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102", "20060102T150405Z", "20060102T150405"} {
+		until, err := time.Parse(layout, value)
+		if err == nil {
+			return truncateToDate(until), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until: %s", value)
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday: %s", value)
+	}
+
+	weekday, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday: %s", value)
+	}
+
+	weekdayNum := WeekdayNum{Weekday: weekday}
+	if nth := value[:len(value)-2]; nth != "" {
+		n, err := strconv.Atoi(nth)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday: %s", value)
+		}
+		weekdayNum.Nth = n
+	}
+
+	return weekdayNum, nil
+}
+
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) != 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, weekdayNum := range r.ByDay {
+			day := strings.ToUpper(weekdayNum.Weekday.String()[:2])
+			if weekdayNum.Nth != 0 {
+				day = strconv.Itoa(weekdayNum.Nth) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) != 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, monthDay := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(monthDay))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count != 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of the rule starting at start that fall
+// within from and to, both inclusive.
+func (r Rule) Between(start, from, to time.Time) []time.Time {
+	start, from, to = truncateToDate(start), truncateToDate(from), truncateToDate(to)
+	if !r.Until.IsZero() && r.Until.Before(to) {
+		to = r.Until
+	}
+
+	var occurrences []time.Time
+	count := 0
+	for day, i := start, 0; !day.After(to) && i < maxIterations; day, i = day.AddDate(0, 0, 1), i+1 {
+		if !r.matches(start, day) {
+			continue
+		}
+
+		count++
+		if r.Count != 0 && count > r.Count {
+			break
+		}
+
+		if !day.Before(from) {
+			occurrences = append(occurrences, day)
+		}
+	}
+
+	return occurrences
+}
+
+// Occurs reports whether date is an occurrence of the rule starting at start.
+func (r Rule) Occurs(start, date time.Time) bool {
+	return len(r.Between(start, date, date)) != 0
+}
+
+func (r Rule) matches(start, day time.Time) bool {
+	switch r.Freq {
+	case Daily:
+		if daysBetween(start, day)%r.Interval != 0 {
+			return false
+		}
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	case Weekly:
+		if daysBetween(startOfWeek(start), startOfWeek(day))/7%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Day() == start.Day()
+		}
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	default:
+		return false
+	}
+}
+
+func (r Rule) matchesByDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+
+	daysInMonth := daysIn(day)
+	return slices.ContainsFunc(r.ByDay, func(weekdayNum WeekdayNum) bool {
+		if weekdayNum.Weekday != day.Weekday() {
+			return false
+		}
+
+		switch {
+		case weekdayNum.Nth > 0:
+			return (day.Day()-1)/7+1 == weekdayNum.Nth
+		case weekdayNum.Nth < 0:
+			return (daysInMonth-day.Day())/7+1 == -weekdayNum.Nth
+		default:
+			return true
+		}
+	})
+}
+
+func (r Rule) matchesByMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+
+	daysInMonth := daysIn(day)
+	return slices.ContainsFunc(r.ByMonthDay, func(monthDay int) bool {
+		if monthDay < 0 {
+			monthDay = daysInMonth + monthDay + 1
+		}
+		return day.Day() == monthDay
+	})
+}
+
+func truncateToDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// startOfWeek returns the monday of the week containing t, matching the
+// default WKST=MO.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
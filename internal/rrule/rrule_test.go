@@ -0,0 +1,133 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	parseTable := []struct {
+		name        string
+		rule        string
+		expectedErr bool
+		expected    string
+	}{
+		{name: "Parse/Daily", rule: "FREQ=DAILY", expected: "FREQ=DAILY"},
+		{name: "Parse/Prefix", rule: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE", expected: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{name: "Parse/Monthly numbered weekday", rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", expected: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{name: "Parse/Until", rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20250401T000000Z", expected: "FREQ=DAILY;INTERVAL=2;UNTIL=20250401"},
+		{name: "Parse/Missing frequency", rule: "INTERVAL=2", expectedErr: true},
+		{name: "Parse/Unsupported frequency", rule: "FREQ=YEARLY", expectedErr: true},
+		{name: "Parse/Invalid weekday", rule: "FREQ=WEEKLY;BYDAY=XX", expectedErr: true},
+		{name: "Parse/Numbered weekday outside monthly", rule: "FREQ=WEEKLY;BYDAY=1FR", expectedErr: true},
+		{name: "Parse/Count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20250401", expectedErr: true},
+	}
+
+	for _, v := range parseTable {
+		t.Run(v.name, func(t *testing.T) {
+			rule, err := Parse(v.rule)
+			if v.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error, got rule: %s", rule)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			if rule.String() != v.expected {
+				t.Errorf("expected %s, got %s", v.expected, rule)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	// 2025-03-03 is a monday.
+	start := date(2025, time.March, 3)
+
+	betweenTable := []struct {
+		name     string
+		rule     string
+		from     time.Time
+		to       time.Time
+		expected []time.Time
+	}{
+		{
+			name:     "Between/Daily with interval",
+			rule:     "FREQ=DAILY;INTERVAL=2",
+			from:     date(2025, time.March, 4),
+			to:       date(2025, time.March, 9),
+			expected: []time.Time{date(2025, time.March, 5), date(2025, time.March, 7), date(2025, time.March, 9)},
+		},
+		{
+			name:     "Between/Weekly on friday",
+			rule:     "FREQ=WEEKLY;BYDAY=FR",
+			from:     start,
+			to:       date(2025, time.March, 21),
+			expected: []time.Time{date(2025, time.March, 7), date(2025, time.March, 14), date(2025, time.March, 21)},
+		},
+		{
+			name:     "Between/Biweekly defaults to start weekday",
+			rule:     "FREQ=WEEKLY;INTERVAL=2",
+			from:     start,
+			to:       date(2025, time.March, 31),
+			expected: []time.Time{date(2025, time.March, 3), date(2025, time.March, 17), date(2025, time.March, 31)},
+		},
+		{
+			name:     "Between/Monthly last day",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1",
+			from:     start,
+			to:       date(2025, time.May, 31),
+			expected: []time.Time{date(2025, time.March, 31), date(2025, time.April, 30), date(2025, time.May, 31)},
+		},
+		{
+			name:     "Between/Monthly first friday",
+			rule:     "FREQ=MONTHLY;BYDAY=1FR",
+			from:     start,
+			to:       date(2025, time.May, 31),
+			expected: []time.Time{date(2025, time.March, 7), date(2025, time.April, 4), date(2025, time.May, 2)},
+		},
+		{
+			name:     "Between/Count counts from start",
+			rule:     "FREQ=DAILY;COUNT=3",
+			from:     date(2025, time.March, 4),
+			to:       date(2025, time.March, 31),
+			expected: []time.Time{date(2025, time.March, 4), date(2025, time.March, 5)},
+		},
+		{
+			name:     "Between/Until",
+			rule:     "FREQ=DAILY;UNTIL=20250305",
+			from:     start,
+			to:       date(2025, time.March, 31),
+			expected: []time.Time{date(2025, time.March, 3), date(2025, time.March, 4), date(2025, time.March, 5)},
+		},
+		{
+			name: "Between/Before start",
+			rule: "FREQ=DAILY",
+			from: date(2025, time.February, 1),
+			to:   date(2025, time.February, 28),
+		},
+	}
+
+	for _, v := range betweenTable {
+		t.Run(v.name, func(t *testing.T) {
+			rule, err := Parse(v.rule)
+			if err != nil {
+				t.Fatalf("wasn't expecting error, got: %v", err)
+			}
+
+			if diff := cmp.Diff(v.expected, rule.Between(start, v.from, v.to)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
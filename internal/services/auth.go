@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
 
 	"time"
 
@@ -15,6 +19,8 @@ import (
 
 	"github.com/mdayat/demi-masa-backend-service/configs"
 	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/mailer"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc"
 	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
 	"github.com/mdayat/demi-masa-backend-service/repository"
 )
@@ -22,20 +28,43 @@ import (
 type AuthServicer interface {
 	CreateRefreshToken(claims RefreshTokenClaims) (string, error)
 	ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error)
+	CreateCSRFToken(refreshToken string) string
+	ValidateCSRFToken(refreshToken, csrfToken string) bool
 	CreateAccessToken(claims AccessTokenClaims) (string, error)
 	ValidateAccessToken(tokenString string) (*AccessTokenClaims, error)
 	RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (rotateRefreshTokenResult, error)
 	RegisterUser(ctx context.Context, arg RegisterUserParams) (registerUserResult, error)
 	AuthenticateUser(ctx context.Context, arg AuthenticateUserParams) (authenticateUserResult, error)
+	SendEmailVerification(ctx context.Context, userUUID pgtype.UUID) error
+	VerifyEmail(ctx context.Context, tokenString string) (repository.User, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, arg ResetPasswordParams) error
+	AuthenticateGoogleUser(ctx context.Context, arg AuthenticateGoogleUserParams) (authenticateUserResult, error)
+	VerifyMFAChallenge(ctx context.Context, arg VerifyMFAChallengeParams) (authenticateUserResult, error)
+	PurgeLoginThrottles(ctx context.Context, now time.Time) (int64, error)
 }
 
 type auth struct {
 	configs configs.Configs
+	// google is nil when Google sign in isn't configured.
+	google *oidc.Provider
 }
 
 func NewAuthService(configs configs.Configs) AuthServicer {
+	var google *oidc.Provider
+	if configs.Env.GoogleClientID != "" {
+		google = oidc.NewProvider(oidc.Config{
+			Issuer:       configs.Env.GoogleIssuer,
+			ClientID:     configs.Env.GoogleClientID,
+			ClientSecret: configs.Env.GoogleClientSecret,
+			TokenURL:     configs.Env.GoogleTokenURL,
+			JWKSURL:      configs.Env.GoogleJWKSURL,
+		})
+	}
+
 	return &auth{
 		configs: configs,
+		google:  google,
 	}
 }
 
@@ -44,26 +73,29 @@ type TokenType int
 const (
 	Refresh TokenType = iota
 	Access
+	EmailVerification
+	MFAChallenge
 )
 
+// RefreshTokenExpiration is how long a session lasts without signing in again.
+// Rotating the refresh token doesn't extend it.
+const RefreshTokenExpiration = 30 * 24 * time.Hour
+
 type RefreshTokenClaims struct {
 	Type TokenType `json:"type"`
 	jwt.RegisteredClaims
 }
 
 func (a auth) CreateRefreshToken(claims RefreshTokenClaims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.configs.Env.SecretKey))
+	return a.configs.KeyRing.Sign(claims)
 }
 
 func (a auth) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&RefreshTokenClaims{},
-		func(_ *jwt.Token) (interface{}, error) {
-			return []byte(a.configs.Env.SecretKey), nil
-		},
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		a.configs.KeyRing.Keyfunc,
+		jwt.WithValidMethods(a.configs.KeyRing.ValidMethods()),
 		jwt.WithIssuer(a.configs.Env.OriginURL),
 		jwt.WithIssuedAt(),
 		jwt.WithExpirationRequired(),
@@ -89,24 +121,56 @@ func (a auth) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, err
 	return claims, nil
 }
 
+// CreateCSRFToken returns the CSRF token that goes with a refresh token sent as
+// a cookie. It is derived from the refresh token, so a token planted by another
+// site or subdomain doesn't match, and it changes on every rotation.
+func (a auth) CreateCSRFToken(refreshToken string) string {
+	mac := hmac.New(sha256.New, []byte(a.configs.Env.SecretKey))
+	mac.Write([]byte("csrf:" + refreshToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (a auth) ValidateCSRFToken(refreshToken, csrfToken string) bool {
+	return hmac.Equal([]byte(a.CreateCSRFToken(refreshToken)), []byte(csrfToken))
+}
+
+// Roles of users. Every user has one, RoleUser unless an admin changed it.
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSupport    = "support"
+	RoleInfluencer = "influencer"
+)
+
 type AccessTokenClaims struct {
 	Type TokenType `json:"type"`
+	// SessionID is the family of the refresh token the access token was
+	// issued with.
+	SessionID string `json:"sid,omitempty"`
+	// Role is the role of the user when the access token was issued, so a
+	// changed role applies once the token is refreshed. Tokens issued before
+	// roles existed have none, which counts as RoleUser.
+	Role string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// SessionClient describes the device a session was signed in from.
+type SessionClient struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
 func (a auth) CreateAccessToken(claims AccessTokenClaims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.configs.Env.SecretKey))
+	return a.configs.KeyRing.Sign(claims)
 }
 
 func (a auth) ValidateAccessToken(tokenString string) (*AccessTokenClaims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&AccessTokenClaims{},
-		func(_ *jwt.Token) (interface{}, error) {
-			return []byte(a.configs.Env.SecretKey), nil
-		},
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		a.configs.KeyRing.Keyfunc,
+		jwt.WithValidMethods(a.configs.KeyRing.ValidMethods()),
 		jwt.WithIssuer(a.configs.Env.OriginURL),
 		jwt.WithIssuedAt(),
 		jwt.WithExpirationRequired(),
@@ -132,26 +196,89 @@ func (a auth) ValidateAccessToken(tokenString string) (*AccessTokenClaims, error
 	return claims, nil
 }
 
+var (
+	ErrRefreshTokenRevoked = errors.New("refresh token is revoked or expired")
+	// ErrRefreshTokenReused means an already rotated refresh token was
+	// presented again, which is how a stolen token shows up.
+	ErrRefreshTokenReused = errors.New("rotated refresh token was reused")
+)
+
+// RefreshTokenReuseError carries the family that was revoked when reuse of a
+// rotated refresh token was detected.
+type RefreshTokenReuseError struct {
+	FamilyID pgtype.UUID
+}
+
+func (e *RefreshTokenReuseError) Error() string {
+	return fmt.Sprintf("%s: revoked family %s", ErrRefreshTokenReused, e.FamilyID.String())
+}
+
+func (e *RefreshTokenReuseError) Unwrap() error {
+	return ErrRefreshTokenReused
+}
+
 type RotateRefreshTokenParams struct {
-	Jti       string
-	UserUUID  pgtype.UUID
-	ExpiresAt time.Time
+	Jti      string
+	UserUUID pgtype.UUID
+	// Client.DeviceName is ignored, the session keeps the name it was signed
+	// in with.
+	Client SessionClient
 }
 
 type rotateRefreshTokenResult struct {
 	RefreshToken string
 	AccessToken  string
+	// reusedFamily is set when the presented token was already rotated. The
+	// family is revoked within the transaction, which has to commit, so the
+	// error is only returned afterwards.
+	reusedFamily pgtype.UUID
 }
 
+// RotateRefreshToken replaces a refresh token with a new one of the same
+// family. Presenting a revoked token revokes its whole family, and returns a
+// RefreshTokenReuseError if any token of the family was still active.
 func (a auth) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (rotateRefreshTokenResult, error) {
+	oldRefreshTokenId, err := uuid.Parse(arg.Jti)
+	if err != nil {
+		return rotateRefreshTokenResult{}, fmt.Errorf("failed to parse old JTI to UUID: %w", err)
+	}
+
 	retryableFunc := func(qtx *repository.Queries) (rotateRefreshTokenResult, error) {
-		oldRefreshTokenId, err := uuid.Parse(arg.Jti)
+		oldRefreshToken, err := qtx.SelectUserRefreshToken(ctx, repository.SelectUserRefreshTokenParams{
+			ID:     pgtype.UUID{Bytes: oldRefreshTokenId, Valid: true},
+			UserID: arg.UserUUID,
+		})
+
 		if err != nil {
-			return rotateRefreshTokenResult{}, fmt.Errorf("failed to parse old JTI to UUID: %w", err)
+			return rotateRefreshTokenResult{}, fmt.Errorf("failed to select user refresh token: %w", err)
+		}
+
+		if oldRefreshToken.Revoked {
+			revokedTokens, err := qtx.RevokeRefreshTokenFamily(ctx, repository.RevokeRefreshTokenFamilyParams{
+				FamilyID: oldRefreshToken.FamilyID,
+				UserID:   arg.UserUUID,
+			})
+
+			if err != nil {
+				return rotateRefreshTokenResult{}, fmt.Errorf("failed to revoke refresh token family: %w", err)
+			}
+
+			// A family without active tokens was logged out, so replaying its
+			// tokens can't be told apart from a client retrying.
+			if revokedTokens == 0 {
+				return rotateRefreshTokenResult{}, ErrRefreshTokenRevoked
+			}
+
+			return rotateRefreshTokenResult{reusedFamily: oldRefreshToken.FamilyID}, nil
+		}
+
+		now := time.Now()
+		if oldRefreshToken.ExpiresAt.Time.Before(now) {
+			return rotateRefreshTokenResult{}, ErrRefreshTokenRevoked
 		}
 
 		_, err = qtx.RevokeUserRefreshToken(ctx, repository.RevokeUserRefreshTokenParams{
-			ID:     pgtype.UUID{Bytes: oldRefreshTokenId, Valid: true},
+			ID:     oldRefreshToken.ID,
 			UserID: arg.UserUUID,
 		})
 
@@ -159,15 +286,17 @@ func (a auth) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenPara
 			return rotateRefreshTokenResult{}, fmt.Errorf("failed to revoke user refresh token: %w", err)
 		}
 
-		userId := arg.UserUUID.String()
-		now := time.Now()
-		remainingExpiration := arg.ExpiresAt.Sub(now)
+		role, err := qtx.SelectUserRole(ctx, arg.UserUUID)
+		if err != nil {
+			return rotateRefreshTokenResult{}, fmt.Errorf("failed to select user role: %w", err)
+		}
 
+		userId := arg.UserUUID.String()
 		refreshTokenClaims := RefreshTokenClaims{
 			Type: Refresh,
 			RegisteredClaims: jwt.RegisteredClaims{
 				ID:        uuid.NewString(),
-				ExpiresAt: jwt.NewNumericDate(now.Add(remainingExpiration)),
+				ExpiresAt: jwt.NewNumericDate(oldRefreshToken.ExpiresAt.Time),
 				IssuedAt:  jwt.NewNumericDate(now),
 				Issuer:    a.configs.Env.OriginURL,
 				Subject:   userId,
@@ -180,7 +309,9 @@ func (a auth) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenPara
 		}
 
 		accessTokenClaims := AccessTokenClaims{
-			Type: Access,
+			Type:      Access,
+			SessionID: oldRefreshToken.FamilyID.String(),
+			Role:      role,
 			RegisteredClaims: jwt.RegisteredClaims{
 				ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
 				IssuedAt:  jwt.NewNumericDate(now),
@@ -200,9 +331,16 @@ func (a auth) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenPara
 		}
 
 		_, err = qtx.InsertUserRefreshToken(ctx, repository.InsertUserRefreshTokenParams{
-			ID:        pgtype.UUID{Bytes: newRefreshTokenId, Valid: true},
-			UserID:    arg.UserUUID,
-			ExpiresAt: pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
+			ID:         pgtype.UUID{Bytes: newRefreshTokenId, Valid: true},
+			UserID:     arg.UserUUID,
+			ExpiresAt:  pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
+			FamilyID:   oldRefreshToken.FamilyID,
+			ParentID:   oldRefreshToken.ID,
+			DeviceName: oldRefreshToken.DeviceName,
+			UserAgent:  arg.Client.UserAgent,
+			IpAddress:  arg.Client.IPAddress,
+			CreatedAt:  oldRefreshToken.CreatedAt,
+			LastUsedAt: pgtype.Timestamptz{Time: now, Valid: true},
 		})
 
 		if err != nil {
@@ -217,7 +355,16 @@ func (a auth) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenPara
 		return rotateRefreshTokenResult, nil
 	}
 
-	return dbutil.RetryableTxWithData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+	result, err := dbutil.RetryableTxWithData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+	if err != nil {
+		return rotateRefreshTokenResult{}, err
+	}
+
+	if result.reusedFamily.Valid {
+		return rotateRefreshTokenResult{}, &RefreshTokenReuseError{FamilyID: result.reusedFamily}
+	}
+
+	return result, nil
 }
 
 type prayerName string
@@ -275,6 +422,7 @@ type RegisterUserParams struct {
 	Username  string
 	UserEmail string
 	Password  string
+	Client    SessionClient
 }
 
 type registerUserResult struct {
@@ -284,178 +432,457 @@ type registerUserResult struct {
 }
 
 func (a auth) RegisterUser(ctx context.Context, arg RegisterUserParams) (registerUserResult, error) {
-	hashedPassword, err := argon2id.CreateHash(arg.Password, argon2id.DefaultParams)
+	hashedPassword, err := argon2id.CreateHash(arg.Password, passwordHashParams)
 	if err != nil {
 		return registerUserResult{}, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	retryableFunc := func(qtx *repository.Queries) (registerUserResult, error) {
-		user, err := qtx.InsertUser(ctx, repository.InsertUserParams{
-			ID:          arg.UserUUID,
-			Name:        arg.Username,
-			Email:       arg.UserEmail,
-			Password:    hashedPassword,
-			Coordinates: pgtype.Point{P: pgtype.Vec2{X: 106.865036, Y: -6.175110}, Valid: true},
-			City:        "Jakarta",
-			Timezone:    "Asia/Jakarta",
+		user, err := a.insertUser(ctx, qtx, repository.InsertUserParams{
+			ID:       arg.UserUUID,
+			Name:     arg.Username,
+			Email:    arg.UserEmail,
+			Password: hashedPassword,
 		})
 
 		if err != nil {
-			return registerUserResult{}, fmt.Errorf("failed to insert user: %w", err)
+			return registerUserResult{}, err
 		}
 
-		insertPrayersParams := a.createInsertPrayersParams(user.ID)
-		_, err = qtx.InsertUserPrayers(ctx, insertPrayersParams)
+		tokens, err := a.startSession(ctx, qtx, user.ID, arg.Client)
 		if err != nil {
-			return registerUserResult{}, fmt.Errorf("failed to insert user prayers: %w", err)
+			return registerUserResult{}, err
 		}
 
-		userId := user.ID.String()
-		now := time.Now()
+		registerUserResult := registerUserResult{
+			User:         user,
+			RefreshToken: tokens.RefreshToken,
+			AccessToken:  tokens.AccessToken,
+		}
 
-		refreshTokenClaims := RefreshTokenClaims{
-			Type: Refresh,
-			RegisteredClaims: jwt.RegisteredClaims{
-				ID:        uuid.NewString(),
-				ExpiresAt: jwt.NewNumericDate(now.Add(30 * 24 * time.Hour)),
-				IssuedAt:  jwt.NewNumericDate(now),
-				Issuer:    a.configs.Env.OriginURL,
-				Subject:   userId,
-			},
+		return registerUserResult, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+}
+
+// insertUser inserts a user located in Jakarta until they set their location,
+// along with their prayers of this month.
+func (a auth) insertUser(ctx context.Context, qtx *repository.Queries, arg repository.InsertUserParams) (repository.User, error) {
+	arg.Coordinates = pgtype.Point{P: pgtype.Vec2{X: 106.865036, Y: -6.175110}, Valid: true}
+	arg.City = "Jakarta"
+	arg.Timezone = "Asia/Jakarta"
+
+	user, err := qtx.InsertUser(ctx, arg)
+	if err != nil {
+		return repository.User{}, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	insertPrayersParams := a.createInsertPrayersParams(user.ID)
+	_, err = qtx.InsertUserPrayers(ctx, insertPrayersParams)
+	if err != nil {
+		return repository.User{}, fmt.Errorf("failed to insert user prayers: %w", err)
+	}
+
+	return user, nil
+}
+
+type sessionTokens struct {
+	RefreshToken string
+	AccessToken  string
+}
+
+// startSession issues the tokens of a new session. Every sign in starts a new
+// family of refresh tokens.
+func (a auth) startSession(ctx context.Context, queries *repository.Queries, userUUID pgtype.UUID, client SessionClient) (sessionTokens, error) {
+	role, err := queries.SelectUserRole(ctx, userUUID)
+	if err != nil {
+		return sessionTokens{}, fmt.Errorf("failed to select user role: %w", err)
+	}
+
+	userId := userUUID.String()
+	now := time.Now()
+
+	refreshTokenClaims := RefreshTokenClaims{
+		Type: Refresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(RefreshTokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    a.configs.Env.OriginURL,
+			Subject:   userId,
+		},
+	}
+
+	refreshToken, err := a.CreateRefreshToken(refreshTokenClaims)
+	if err != nil {
+		return sessionTokens{}, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	accessTokenClaims := AccessTokenClaims{
+		Type:      Access,
+		SessionID: refreshTokenClaims.ID,
+		Role:      role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    a.configs.Env.OriginURL,
+			Subject:   userId,
+		},
+	}
+
+	accessToken, err := a.CreateAccessToken(accessTokenClaims)
+	if err != nil {
+		return sessionTokens{}, fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	refreshTokenUUID, err := uuid.Parse(refreshTokenClaims.ID)
+	if err != nil {
+		return sessionTokens{}, fmt.Errorf("failed to parse JTI to UUID: %w", err)
+	}
+
+	_, err = queries.InsertUserRefreshToken(ctx, repository.InsertUserRefreshTokenParams{
+		ID:         pgtype.UUID{Bytes: refreshTokenUUID, Valid: true},
+		UserID:     userUUID,
+		ExpiresAt:  pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
+		FamilyID:   pgtype.UUID{Bytes: refreshTokenUUID, Valid: true},
+		DeviceName: client.DeviceName,
+		UserAgent:  client.UserAgent,
+		IpAddress:  client.IPAddress,
+		CreatedAt:  pgtype.Timestamptz{Time: now, Valid: true},
+		LastUsedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	})
+
+	if err != nil {
+		return sessionTokens{}, fmt.Errorf("failed to insert user refresh token: %w", err)
+	}
+
+	return sessionTokens{RefreshToken: refreshToken, AccessToken: accessToken}, nil
+}
+
+type AuthenticateUserParams struct {
+	Email    string
+	Password string
+	Client   SessionClient
+}
+
+// authenticateUserResult has either the tokens of the new session, or the
+// MFAToken when the user has to pass an MFA challenge first.
+type authenticateUserResult struct {
+	User         repository.User
+	RefreshToken string
+	AccessToken  string
+	MFAToken     string
+}
+
+// AuthenticateUser signs in with a password. Failed attempts are throttled
+// per email and per address, see LoginThrottledError.
+func (a auth) AuthenticateUser(ctx context.Context, arg AuthenticateUserParams) (authenticateUserResult, error) {
+	throttleKey := loginThrottleKey{Email: arg.Email, IPAddress: arg.Client.IPAddress}
+	attempt := func(qtx *repository.Queries) (authenticateUserResult, error) {
+		user, err := qtx.SelectUserByEmail(ctx, arg.Email)
+		if err != nil {
+			err = fmt.Errorf("failed to select user by email: %w", err)
+			// Unknown emails are throttled too, otherwise they'd stand out.
+			if errors.Is(err, pgx.ErrNoRows) {
+				return authenticateUserResult{}, &loginFailure{Err: err}
+			}
+			return authenticateUserResult{}, err
 		}
 
-		refreshToken, err := a.CreateRefreshToken(refreshTokenClaims)
+		match, err := argon2id.ComparePasswordAndHash(arg.Password, user.Password)
 		if err != nil {
-			return registerUserResult{}, fmt.Errorf("failed to create refresh token: %w", err)
+			return authenticateUserResult{}, fmt.Errorf("failed to compare password: %w", err)
 		}
 
-		accessTokenClaims := AccessTokenClaims{
-			Type: Access,
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
-				IssuedAt:  jwt.NewNumericDate(now),
-				Issuer:    a.configs.Env.OriginURL,
-				Subject:   userId,
-			},
+		if !match {
+			return authenticateUserResult{}, &loginFailure{User: &user, Err: fmt.Errorf("wrong password: %w", pgx.ErrNoRows)}
 		}
 
-		accessToken, err := a.CreateAccessToken(accessTokenClaims)
+		mfaToken, err := a.challengeMFA(ctx, qtx, user.ID, arg.Client)
 		if err != nil {
-			return registerUserResult{}, fmt.Errorf("failed to create access token: %w", err)
+			return authenticateUserResult{}, err
 		}
 
-		refreshTokenUUID, err := uuid.Parse(refreshTokenClaims.ID)
-		if err != nil {
-			return registerUserResult{}, fmt.Errorf("failed to parse JTI to UUID: %w", err)
+		// The failures are kept until the challenge is passed, so guessing the
+		// code can't be reset by signing in again.
+		if mfaToken != "" {
+			return authenticateUserResult{User: user, MFAToken: mfaToken}, nil
 		}
 
-		_, err = qtx.InsertUserRefreshToken(ctx, repository.InsertUserRefreshTokenParams{
-			ID:        pgtype.UUID{Bytes: refreshTokenUUID, Valid: true},
-			UserID:    user.ID,
-			ExpiresAt: pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
-		})
+		if err := a.resetLoginThrottle(ctx, qtx, user.Email); err != nil {
+			return authenticateUserResult{}, err
+		}
 
+		tokens, err := a.startSession(ctx, qtx, user.ID, arg.Client)
 		if err != nil {
-			return registerUserResult{}, fmt.Errorf("failed to insert user refresh token: %w", err)
+			return authenticateUserResult{}, err
 		}
 
-		registerUserResult := registerUserResult{
+		authenticateUserResult := authenticateUserResult{
 			User:         user,
-			RefreshToken: refreshToken,
-			AccessToken:  accessToken,
+			RefreshToken: tokens.RefreshToken,
+			AccessToken:  tokens.AccessToken,
 		}
 
-		return registerUserResult, nil
+		return authenticateUserResult, nil
 	}
 
-	return dbutil.RetryableTxWithData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+	return throttleLogin(ctx, a.configs, throttleKey, attempt)
 }
 
-type AuthenticateUserParams struct {
-	Email    string
-	Password string
+const emailVerificationExpiration = 24 * time.Hour
+
+var (
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrInvalidVerificationToken = errors.New("invalid email verification token")
+)
+
+// EmailVerificationClaims are bound to the email they were sent to, so the
+// token stops working once the user changes their email.
+type EmailVerificationClaims struct {
+	Type  TokenType `json:"type"`
+	Email string    `json:"email"`
+	jwt.RegisteredClaims
 }
 
-type authenticateUserResult struct {
-	User         repository.User
-	RefreshToken string
-	AccessToken  string
+func (a auth) SendEmailVerification(ctx context.Context, userUUID pgtype.UUID) error {
+	user, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return a.configs.Db.Queries.SelectUser(ctx, userUUID)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to select user: %w", err)
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		return ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	claims := EmailVerificationClaims{
+		Type:  EmailVerification,
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(emailVerificationExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    a.configs.Env.OriginURL,
+			Subject:   user.ID.String(),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(a.configs.Env.SecretKey))
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	verificationURL := fmt.Sprintf("%s/verify-email?%s", a.configs.Env.WebAppURL, url.Values{"token": {token}}.Encode())
+	err = a.configs.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to verify your email address. The link expires in 24 hours.\n\n%s\n",
+			user.Name,
+			verificationURL,
+		),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send email verification: %w", err)
+	}
+
+	return nil
 }
 
-func (a auth) AuthenticateUser(ctx context.Context, arg AuthenticateUserParams) (authenticateUserResult, error) {
+// VerifyEmail marks the email in the token as verified. Verifying an already
+// verified email succeeds, so opening the link twice isn't an error.
+func (a auth) VerifyEmail(ctx context.Context, tokenString string) (repository.User, error) {
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&EmailVerificationClaims{},
+		func(_ *jwt.Token) (interface{}, error) {
+			return []byte(a.configs.Env.SecretKey), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(a.configs.Env.OriginURL),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
+
+	if err != nil {
+		return repository.User{}, fmt.Errorf("%w: %w", ErrInvalidVerificationToken, err)
+	}
+
+	claims, ok := token.Claims.(*EmailVerificationClaims)
+	if !ok || !token.Valid || claims.Type != EmailVerification {
+		return repository.User{}, ErrInvalidVerificationToken
+	}
+
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return repository.User{}, fmt.Errorf("%w: %w", ErrInvalidVerificationToken, err)
+	}
+
 	user, err := retryutil.RetryWithData(func() (repository.User, error) {
-		return a.configs.Db.Queries.SelectUserByEmail(ctx, arg.Email)
+		return a.configs.Db.Queries.VerifyUserEmail(ctx, repository.VerifyUserEmailParams{
+			ID:    pgtype.UUID{Bytes: userUUID, Valid: true},
+			Email: claims.Email,
+		})
 	})
 
 	if err != nil {
-		return authenticateUserResult{}, fmt.Errorf("failed to select user by email: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.User{}, fmt.Errorf("%w: email changed or user deleted", ErrInvalidVerificationToken)
+		}
+		return repository.User{}, fmt.Errorf("failed to verify user email: %w", err)
 	}
 
-	match, err := argon2id.ComparePasswordAndHash(arg.Password, user.Password)
+	return user, nil
+}
+
+const mfaChallengeExpiration = 5 * time.Minute
+
+var ErrInvalidMFAToken = errors.New("invalid or expired MFA challenge token")
+
+// MFAChallengeClaims carry the device name the user signed in with over to
+// the session started once the challenge is passed.
+type MFAChallengeClaims struct {
+	Type       TokenType `json:"type"`
+	DeviceName string    `json:"device_name,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// challengeMFA returns an MFA challenge token when the user has TOTP enabled,
+// and an empty string otherwise.
+func (a auth) challengeMFA(ctx context.Context, queries *repository.Queries, userUUID pgtype.UUID, client SessionClient) (string, error) {
+	userTOTP, err := queries.SelectUserTOTP(ctx, userUUID)
 	if err != nil {
-		return authenticateUserResult{}, fmt.Errorf("failed to compare password: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to select user TOTP: %w", err)
 	}
 
-	if !match {
-		return authenticateUserResult{}, fmt.Errorf("wrong password: %w", pgx.ErrNoRows)
+	if !userTOTP.ConfirmedAt.Valid {
+		return "", nil
 	}
 
-	userId := user.ID.String()
 	now := time.Now()
-
-	refreshTokenClaims := RefreshTokenClaims{
-		Type: Refresh,
+	claims := MFAChallengeClaims{
+		Type:       MFAChallenge,
+		DeviceName: client.DeviceName,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.NewString(),
-			ExpiresAt: jwt.NewNumericDate(now.Add(30 * 24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaChallengeExpiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    a.configs.Env.OriginURL,
-			Subject:   userId,
+			Subject:   userUUID.String(),
 		},
 	}
 
-	refreshToken, err := a.CreateRefreshToken(refreshTokenClaims)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(a.configs.Env.SecretKey))
 	if err != nil {
-		return authenticateUserResult{}, fmt.Errorf("failed to create refresh token: %w", err)
+		return "", fmt.Errorf("failed to create MFA challenge token: %w", err)
 	}
 
-	accessTokenClaims := AccessTokenClaims{
-		Type: Access,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			Issuer:    a.configs.Env.OriginURL,
-			Subject:   userId,
+	return token, nil
+}
+
+type VerifyMFAChallengeParams struct {
+	Token string
+	Code  string
+	// Client.DeviceName is ignored, the one sent when signing in is used.
+	Client SessionClient
+}
+
+// VerifyMFAChallenge starts the session of a sign in that was challenged,
+// given a TOTP or recovery code.
+func (a auth) VerifyMFAChallenge(ctx context.Context, arg VerifyMFAChallengeParams) (authenticateUserResult, error) {
+	token, err := jwt.ParseWithClaims(
+		arg.Token,
+		&MFAChallengeClaims{},
+		func(_ *jwt.Token) (interface{}, error) {
+			return []byte(a.configs.Env.SecretKey), nil
 		},
-	}
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(a.configs.Env.OriginURL),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
 
-	accessToken, err := a.CreateAccessToken(accessTokenClaims)
 	if err != nil {
-		return authenticateUserResult{}, fmt.Errorf("failed to create access token: %w", err)
+		return authenticateUserResult{}, fmt.Errorf("%w: %w", ErrInvalidMFAToken, err)
 	}
 
-	refreshTokenUUID, err := uuid.Parse(refreshTokenClaims.ID)
+	claims, ok := token.Claims.(*MFAChallengeClaims)
+	if !ok || !token.Valid || claims.Type != MFAChallenge {
+		return authenticateUserResult{}, ErrInvalidMFAToken
+	}
+
+	userUUID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return authenticateUserResult{}, fmt.Errorf("failed to parse JTI to UUID: %w", err)
+		return authenticateUserResult{}, fmt.Errorf("%w: %w", ErrInvalidMFAToken, err)
 	}
 
-	_, err = retryutil.RetryWithData(func() (repository.RefreshToken, error) {
-		return a.configs.Db.Queries.InsertUserRefreshToken(ctx, repository.InsertUserRefreshTokenParams{
-			ID:        pgtype.UUID{Bytes: refreshTokenUUID, Valid: true},
-			UserID:    user.ID,
-			ExpiresAt: pgtype.Timestamptz{Time: refreshTokenClaims.ExpiresAt.Time, Valid: true},
-		})
+	client := arg.Client
+	client.DeviceName = claims.DeviceName
+
+	selectUserRow, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return a.configs.Db.Queries.SelectUser(ctx, pgtype.UUID{Bytes: userUUID, Valid: true})
 	})
 
 	if err != nil {
-		return authenticateUserResult{}, fmt.Errorf("failed to insert user refresh token: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return authenticateUserResult{}, fmt.Errorf("%w: user deleted", ErrInvalidMFAToken)
+		}
+		return authenticateUserResult{}, fmt.Errorf("failed to select user: %w", err)
+	}
+
+	user := repository.User{
+		ID:              selectUserRow.ID,
+		Email:           selectUserRow.Email,
+		Password:        selectUserRow.Password,
+		Name:            selectUserRow.Name,
+		Coordinates:     selectUserRow.Coordinates,
+		City:            selectUserRow.City,
+		Timezone:        selectUserRow.Timezone,
+		EmailVerifiedAt: selectUserRow.EmailVerifiedAt,
+		Role:            selectUserRow.Role,
+		CreatedAt:       selectUserRow.CreatedAt,
 	}
 
-	authenticateUserResult := authenticateUserResult{
-		User:         user,
-		RefreshToken: refreshToken,
-		AccessToken:  accessToken,
+	// Wrong codes count as failed sign in attempts of the user.
+	throttleKey := loginThrottleKey{Email: user.Email, IPAddress: client.IPAddress}
+	attempt := func(qtx *repository.Queries) (authenticateUserResult, error) {
+		err := verifySecondFactor(ctx, a.configs.Env, qtx, user.ID, arg.Code)
+		if err != nil {
+			if errors.Is(err, ErrInvalidMFACode) {
+				return authenticateUserResult{}, &loginFailure{User: &user, Err: err}
+			}
+
+			// TOTP may have been disabled since the challenge was issued.
+			if errors.Is(err, ErrTOTPNotEnabled) {
+				return authenticateUserResult{}, fmt.Errorf("%w: %w", ErrInvalidMFAToken, err)
+			}
+			return authenticateUserResult{}, err
+		}
+
+		if err := a.resetLoginThrottle(ctx, qtx, user.Email); err != nil {
+			return authenticateUserResult{}, err
+		}
+
+		tokens, err := a.startSession(ctx, qtx, user.ID, client)
+		if err != nil {
+			return authenticateUserResult{}, err
+		}
+
+		authenticateUserResult := authenticateUserResult{
+			User:         user,
+			RefreshToken: tokens.RefreshToken,
+			AccessToken:  tokens.AccessToken,
+		}
+
+		return authenticateUserResult, nil
 	}
 
-	return authenticateUserResult, nil
+	return throttleLogin(ctx, a.configs, throttleKey, attempt)
 }
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+// EntitlementServicer resolves what a user may do from the plan of their
+// active subscription. Users without one are on the free tier.
+type EntitlementServicer interface {
+	GetEntitlements(ctx context.Context, userUUID pgtype.UUID) (Entitlements, error)
+}
+
+type Entitlement string
+
+const (
+	EntitlementUnlimitedTasks Entitlement = "unlimited_tasks"
+	EntitlementFullHistory    Entitlement = "full_history"
+	EntitlementExtendedStats  Entitlement = "extended_stats"
+)
+
+const PlanFree = "free"
+
+// planEntitlements maps a plan type to its features. Features that aren't
+// listed fall back to the free-tier limits.
+var planEntitlements = map[string][]Entitlement{
+	"premium": {EntitlementUnlimitedTasks, EntitlementFullHistory, EntitlementExtendedStats},
+}
+
+const (
+	freeMaxActiveTasks = 50
+	freeHistoryDays    = 30
+	freeMaxStatsDays   = 7
+)
+
+var ErrEntitlementRequired = errors.New("entitlement required")
+
+// EntitlementError tells which entitlement is missing. Limit is the free-tier
+// limit that was reached.
+type EntitlementError struct {
+	Entitlement Entitlement
+	Limit       int64
+	// Subscribed is true when the user has an active subscription whose plan
+	// doesn't include the entitlement.
+	Subscribed bool
+}
+
+func (e *EntitlementError) Error() string {
+	return fmt.Sprintf("%s: %s (limit %d)", ErrEntitlementRequired, e.Entitlement, e.Limit)
+}
+
+func (e *EntitlementError) Unwrap() error {
+	return ErrEntitlementRequired
+}
+
+type Entitlements struct {
+	Plan       string
+	Subscribed bool
+	Features   []Entitlement
+}
+
+func (e Entitlements) Has(entitlement Entitlement) bool {
+	return slices.Contains(e.Features, entitlement)
+}
+
+func (e Entitlements) missing(entitlement Entitlement, limit int64) error {
+	return &EntitlementError{Entitlement: entitlement, Limit: limit, Subscribed: e.Subscribed}
+}
+
+// checkHistory rejects dates further back than the free-tier history depth
+// from today, both in the same location.
+func (e Entitlements) checkHistory(date, today time.Time) error {
+	if e.Has(EntitlementFullHistory) || !date.Before(today.AddDate(0, 0, -freeHistoryDays)) {
+		return nil
+	}
+	return e.missing(EntitlementFullHistory, freeHistoryDays)
+}
+
+// maxStatsDays is the longest range of days stats can be requested for.
+func (e Entitlements) maxStatsDays() int {
+	if e.Has(EntitlementExtendedStats) {
+		return maxTaskStatsDays
+	}
+	return freeMaxStatsDays
+}
+
+type entitlement struct {
+	configs configs.Configs
+}
+
+func NewEntitlementService(configs configs.Configs) EntitlementServicer {
+	return &entitlement{
+		configs: configs,
+	}
+}
+
+func (e entitlement) GetEntitlements(ctx context.Context, userUUID pgtype.UUID) (Entitlements, error) {
+	subscription, err := retryutil.RetryWithData(func() (repository.Subscription, error) {
+		return e.configs.Db.Queries.SelectUserActiveSubscription(ctx, userUUID)
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Entitlements{Plan: PlanFree}, nil
+		}
+		return Entitlements{}, fmt.Errorf("failed to select user active subscription: %w", err)
+	}
+
+	// Deleted plans still apply to the subscriptions already paid for.
+	plan, err := retryutil.RetryWithData(func() (repository.Plan, error) {
+		return e.configs.Db.Queries.SelectSubscribedPlan(ctx, subscription.PlanID)
+	})
+
+	if err != nil {
+		return Entitlements{}, fmt.Errorf("failed to select subscribed plan: %w", err)
+	}
+
+	return Entitlements{
+		Plan:       plan.Type,
+		Subscribed: true,
+		Features:   planEntitlements[plan.Type],
+	}, nil
+}
+
+// limitActiveTasks runs f, which adds, unchecks or restores tasks of the user
+// within the transaction of qtx. It returns an EntitlementError when f leaves
+// the user with more active tasks than before and over the free-tier limit,
+// so the transaction rolls back. Active tasks include the ones the user
+// created in task lists. The user's tasks stay locked until the transaction
+// ends, so concurrent requests can't go over the limit together.
+func limitActiveTasks[T any](
+	ctx context.Context,
+	qtx *repository.Queries,
+	entitlements Entitlements,
+	userUUID pgtype.UUID,
+	f func() (T, error),
+) (T, error) {
+	var zero T
+	if entitlements.Has(EntitlementUnlimitedTasks) {
+		return f()
+	}
+
+	if err := qtx.LockUserTasks(ctx, userUUID); err != nil {
+		return zero, fmt.Errorf("failed to lock user tasks: %w", err)
+	}
+
+	activeTasksBefore, err := qtx.CountUserActiveTasks(ctx, userUUID)
+	if err != nil {
+		return zero, fmt.Errorf("failed to count user active tasks: %w", err)
+	}
+
+	result, err := f()
+	if err != nil {
+		return zero, err
+	}
+
+	activeTasksAfter, err := qtx.CountUserActiveTasks(ctx, userUUID)
+	if err != nil {
+		return zero, fmt.Errorf("failed to count user active tasks: %w", err)
+	}
+
+	if activeTasksAfter > activeTasksBefore && activeTasksAfter > freeMaxActiveTasks {
+		return zero, entitlements.missing(EntitlementUnlimitedTasks, freeMaxActiveTasks)
+	}
+
+	return result, nil
+}
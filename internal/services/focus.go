@@ -0,0 +1,544 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+type FocusServicer interface {
+	StartFocusSession(ctx context.Context, arg StartFocusSessionParams) (FocusSessionResult, error)
+	GetActiveFocusSession(ctx context.Context, userUUID pgtype.UUID) (FocusSessionResult, error)
+	GetFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error)
+	PauseFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error)
+	ResumeFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error)
+	FinishFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error)
+	GetFocusTotals(ctx context.Context, arg GetFocusTotalsParams) (FocusTotals, error)
+	GetFocusPreference(ctx context.Context, userUUID pgtype.UUID) (repository.FocusPreference, error)
+	UpdateFocusPreference(ctx context.Context, arg UpdateFocusPreferenceParams) (repository.FocusPreference, error)
+}
+
+var (
+	ErrFocusSessionActive            = errors.New("user already has an active focus session")
+	ErrInvalidFocusSessionTransition = errors.New("focus session can't transition from its current status")
+	ErrInvalidFocusDay               = errors.New("focus day must start before it ends")
+)
+
+const (
+	FocusSessionRunning  = "running"
+	FocusSessionPaused   = "paused"
+	FocusSessionFinished = "finished"
+)
+
+type focus struct {
+	configs configs.Configs
+}
+
+func NewFocusService(configs configs.Configs) FocusServicer {
+	return &focus{
+		configs: configs,
+	}
+}
+
+type FocusSessionResult struct {
+	Session repository.FocusSession
+	// FocusedSeconds includes the current segment of a running session.
+	FocusedSeconds int64
+	// UpcomingPrayer is the first prayer before the planned end of a running
+	// session, nil when there is none.
+	UpcomingPrayer *PrayerTime
+}
+
+type StartFocusSessionParams struct {
+	UserUUID pgtype.UUID
+	// TaskUUID is optional.
+	TaskUUID                 pgtype.UUID
+	PlannedDurationInMinutes int16
+}
+
+func (f focus) StartFocusSession(ctx context.Context, arg StartFocusSessionParams) (FocusSessionResult, error) {
+	sessionUUID := uuid.New()
+	retryableFunc := func(qtx *repository.Queries) (FocusSessionResult, error) {
+		user, err := qtx.SelectUser(ctx, arg.UserUUID)
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		now := time.Now()
+		activeSession, err := qtx.SelectUserActiveFocusSession(ctx, arg.UserUUID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user active focus session: %w", err)
+		}
+
+		if err == nil {
+			// A session auto-paused for a prayer is still active, so it has to
+			// be finished before another one starts.
+			return FocusSessionResult{}, fmt.Errorf("%w: %s", ErrFocusSessionActive, activeSession.ID.String())
+		}
+
+		if arg.TaskUUID.Valid {
+			_, err := qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+				ID:     arg.TaskUUID,
+				UserID: arg.UserUUID,
+			})
+
+			if err != nil {
+				return FocusSessionResult{}, fmt.Errorf("failed to select user task: %w", err)
+			}
+		}
+
+		session, err := qtx.InsertUserFocusSession(ctx, repository.InsertUserFocusSessionParams{
+			ID:                       pgtype.UUID{Bytes: sessionUUID, Valid: true},
+			UserID:                   arg.UserUUID,
+			TaskID:                   arg.TaskUUID,
+			PlannedDurationInMinutes: arg.PlannedDurationInMinutes,
+			StartedAt:                pgtype.Timestamptz{Time: now, Valid: true},
+		})
+
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to insert user focus session: %w", err)
+		}
+
+		return newFocusSessionResult(session, user, now)
+	}
+
+	return dbutil.RetryableTxWithData(ctx, f.configs.Db.Conn, f.configs.Db.Queries, retryableFunc)
+}
+
+func (f focus) GetActiveFocusSession(ctx context.Context, userUUID pgtype.UUID) (FocusSessionResult, error) {
+	retryableFunc := func(qtx *repository.Queries) (FocusSessionResult, error) {
+		user, err := qtx.SelectUser(ctx, userUUID)
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		session, err := qtx.SelectUserActiveFocusSession(ctx, userUUID)
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user active focus session: %w", err)
+		}
+
+		now := time.Now()
+		session, err = settleFocusSession(ctx, qtx, session, user, now)
+		if err != nil {
+			return FocusSessionResult{}, err
+		}
+
+		return newFocusSessionResult(session, user, now)
+	}
+
+	return dbutil.RetryableTxWithData(ctx, f.configs.Db.Conn, f.configs.Db.Queries, retryableFunc)
+}
+
+type FocusSessionParams struct {
+	UserUUID    pgtype.UUID
+	SessionUUID pgtype.UUID
+}
+
+func (f focus) GetFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error) {
+	retryableFunc := func(qtx *repository.Queries) (FocusSessionResult, error) {
+		user, err := qtx.SelectUser(ctx, arg.UserUUID)
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		session, err := qtx.SelectUserFocusSession(ctx, repository.SelectUserFocusSessionParams{
+			ID:     arg.SessionUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user focus session: %w", err)
+		}
+
+		now := time.Now()
+		session, err = settleFocusSession(ctx, qtx, session, user, now)
+		if err != nil {
+			return FocusSessionResult{}, err
+		}
+
+		return newFocusSessionResult(session, user, now)
+	}
+
+	return dbutil.RetryableTxWithData(ctx, f.configs.Db.Conn, f.configs.Db.Queries, retryableFunc)
+}
+
+func (f focus) PauseFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error) {
+	return f.transitionFocusSession(ctx, arg, func(session repository.FocusSession, now time.Time) (repository.FocusSession, error) {
+		if session.Status != FocusSessionRunning {
+			return repository.FocusSession{}, ErrInvalidFocusSessionTransition
+		}
+
+		session.FocusedSeconds += segmentSeconds(session, now)
+		session.Status = FocusSessionPaused
+		session.ResumedAt = pgtype.Timestamptz{}
+		return session, nil
+	})
+}
+
+func (f focus) ResumeFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error) {
+	return f.transitionFocusSession(ctx, arg, func(session repository.FocusSession, now time.Time) (repository.FocusSession, error) {
+		if session.Status != FocusSessionPaused {
+			return repository.FocusSession{}, ErrInvalidFocusSessionTransition
+		}
+
+		session.Status = FocusSessionRunning
+		session.PausedForPrayer = pgtype.Text{}
+		session.ResumedAt = pgtype.Timestamptz{Time: now, Valid: true}
+		return session, nil
+	})
+}
+
+func (f focus) FinishFocusSession(ctx context.Context, arg FocusSessionParams) (FocusSessionResult, error) {
+	return f.transitionFocusSession(ctx, arg, func(session repository.FocusSession, now time.Time) (repository.FocusSession, error) {
+		if session.Status == FocusSessionFinished {
+			return repository.FocusSession{}, ErrInvalidFocusSessionTransition
+		}
+
+		if session.Status == FocusSessionRunning {
+			session.FocusedSeconds += segmentSeconds(session, now)
+		}
+
+		session.Status = FocusSessionFinished
+		session.ResumedAt = pgtype.Timestamptz{}
+		session.EndedAt = pgtype.Timestamptz{Time: now, Valid: true}
+		return session, nil
+	})
+}
+
+// transitionFocusSession settles any pending prayer pause of the session
+// before applying transition, so a pause or finish never counts time spent
+// after a prayer has started.
+func (f focus) transitionFocusSession(
+	ctx context.Context,
+	arg FocusSessionParams,
+	transition func(session repository.FocusSession, now time.Time) (repository.FocusSession, error),
+) (FocusSessionResult, error) {
+	retryableFunc := func(qtx *repository.Queries) (FocusSessionResult, error) {
+		user, err := qtx.SelectUser(ctx, arg.UserUUID)
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		session, err := qtx.SelectUserFocusSession(ctx, repository.SelectUserFocusSessionParams{
+			ID:     arg.SessionUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to select user focus session: %w", err)
+		}
+
+		now := time.Now()
+		session, err = settleFocusSession(ctx, qtx, session, user, now)
+		if err != nil {
+			return FocusSessionResult{}, err
+		}
+
+		session, err = transition(session, now)
+		if err != nil {
+			return FocusSessionResult{}, err
+		}
+
+		session, err = qtx.UpdateFocusSession(ctx, newUpdateFocusSessionParams(session))
+		if err != nil {
+			return FocusSessionResult{}, fmt.Errorf("failed to update focus session: %w", err)
+		}
+
+		return newFocusSessionResult(session, user, now)
+	}
+
+	return dbutil.RetryableTxWithData(ctx, f.configs.Db.Conn, f.configs.Db.Queries, retryableFunc)
+}
+
+type GetFocusTotalsParams struct {
+	UserUUID pgtype.UUID
+	// Date defaults to today in the user's timezone.
+	Date time.Time
+	// Entitlements limit how far back the date can be.
+	Entitlements Entitlements
+}
+
+type FocusTotals struct {
+	Date          time.Time
+	WeekStartsOn  time.Time
+	DailySeconds  int64
+	WeeklySeconds int64
+}
+
+func (f focus) GetFocusTotals(ctx context.Context, arg GetFocusTotalsParams) (FocusTotals, error) {
+	retryableFunc := func(qtx *repository.Queries) (FocusTotals, error) {
+		user, err := qtx.SelectUser(ctx, arg.UserUUID)
+		if err != nil {
+			return FocusTotals{}, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		location, err := time.LoadLocation(user.Timezone)
+		if err != nil {
+			return FocusTotals{}, fmt.Errorf("failed to load timezone location: %w", err)
+		}
+
+		now := time.Now()
+		activeSession, err := qtx.SelectUserActiveFocusSession(ctx, arg.UserUUID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return FocusTotals{}, fmt.Errorf("failed to select user active focus session: %w", err)
+		}
+
+		// The running segment must stop at a passed prayer before it is
+		// summed up.
+		if err == nil {
+			if _, err := settleFocusSession(ctx, qtx, activeSession, user, now); err != nil {
+				return FocusTotals{}, err
+			}
+		}
+
+		year, month, day := now.In(location).Date()
+		today := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+		totals := FocusTotals{Date: today}
+		if !arg.Date.IsZero() {
+			year, month, day := arg.Date.Date()
+			totals.Date = time.Date(year, month, day, 0, 0, 0, 0, location)
+		}
+
+		if err := arg.Entitlements.checkHistory(totals.Date, today); err != nil {
+			return FocusTotals{}, err
+		}
+		totals.WeekStartsOn = totals.Date.AddDate(0, 0, -((int(totals.Date.Weekday()) + 6) % 7))
+
+		totals.DailySeconds, err = qtx.SelectUserFocusedSeconds(ctx, repository.SelectUserFocusedSecondsParams{
+			UserID:       arg.UserUUID,
+			Now:          pgtype.Timestamptz{Time: now, Valid: true},
+			StartedFrom:  pgtype.Timestamptz{Time: totals.Date, Valid: true},
+			StartedUntil: pgtype.Timestamptz{Time: totals.Date.AddDate(0, 0, 1), Valid: true},
+		})
+
+		if err != nil {
+			return FocusTotals{}, fmt.Errorf("failed to select daily focused seconds: %w", err)
+		}
+
+		totals.WeeklySeconds, err = qtx.SelectUserFocusedSeconds(ctx, repository.SelectUserFocusedSecondsParams{
+			UserID:       arg.UserUUID,
+			Now:          pgtype.Timestamptz{Time: now, Valid: true},
+			StartedFrom:  pgtype.Timestamptz{Time: totals.WeekStartsOn, Valid: true},
+			StartedUntil: pgtype.Timestamptz{Time: totals.WeekStartsOn.AddDate(0, 0, 7), Valid: true},
+		})
+
+		if err != nil {
+			return FocusTotals{}, fmt.Errorf("failed to select weekly focused seconds: %w", err)
+		}
+
+		return totals, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, f.configs.Db.Conn, f.configs.Db.Queries, retryableFunc)
+}
+
+// defaultFocusPreference is used until the user saves their own preference.
+func defaultFocusPreference(userUUID pgtype.UUID) repository.FocusPreference {
+	return repository.FocusPreference{
+		UserID:                       userUUID,
+		DayStartsAt:                  pgtype.Time{Microseconds: (5 * time.Hour).Microseconds(), Valid: true},
+		DayEndsAt:                    pgtype.Time{Microseconds: (22 * time.Hour).Microseconds(), Valid: true},
+		PrayerDurationInMinutes:      20,
+		BreakInMinutes:               5,
+		DefaultTaskDurationInMinutes: 30,
+	}
+}
+
+func selectFocusPreference(ctx context.Context, qtx *repository.Queries, userUUID pgtype.UUID) (repository.FocusPreference, error) {
+	preference, err := qtx.SelectUserFocusPreference(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return defaultFocusPreference(userUUID), nil
+		}
+		return repository.FocusPreference{}, fmt.Errorf("failed to select user focus preference: %w", err)
+	}
+
+	return preference, nil
+}
+
+func (f focus) GetFocusPreference(ctx context.Context, userUUID pgtype.UUID) (repository.FocusPreference, error) {
+	return selectFocusPreference(ctx, f.configs.Db.Queries, userUUID)
+}
+
+// UpdateFocusPreferenceParams only updates the valid fields, the others keep
+// their current value.
+type UpdateFocusPreferenceParams struct {
+	UserUUID                     pgtype.UUID
+	DayStartsAt                  pgtype.Time
+	DayEndsAt                    pgtype.Time
+	PrayerDurationInMinutes      pgtype.Int2
+	BreakInMinutes               pgtype.Int2
+	DefaultTaskDurationInMinutes pgtype.Int2
+}
+
+func (f focus) UpdateFocusPreference(ctx context.Context, arg UpdateFocusPreferenceParams) (repository.FocusPreference, error) {
+	retryableFunc := func(qtx *repository.Queries) (repository.FocusPreference, error) {
+		preference, err := selectFocusPreference(ctx, qtx, arg.UserUUID)
+		if err != nil {
+			return repository.FocusPreference{}, err
+		}
+
+		if arg.DayStartsAt.Valid {
+			preference.DayStartsAt = arg.DayStartsAt
+		}
+
+		if arg.DayEndsAt.Valid {
+			preference.DayEndsAt = arg.DayEndsAt
+		}
+
+		if arg.PrayerDurationInMinutes.Valid {
+			preference.PrayerDurationInMinutes = arg.PrayerDurationInMinutes.Int16
+		}
+
+		if arg.BreakInMinutes.Valid {
+			preference.BreakInMinutes = arg.BreakInMinutes.Int16
+		}
+
+		if arg.DefaultTaskDurationInMinutes.Valid {
+			preference.DefaultTaskDurationInMinutes = arg.DefaultTaskDurationInMinutes.Int16
+		}
+
+		// Only one bound may change, so the range is checked after merging.
+		if preference.DayStartsAt.Microseconds >= preference.DayEndsAt.Microseconds {
+			return repository.FocusPreference{}, ErrInvalidFocusDay
+		}
+
+		preference, err = qtx.UpsertUserFocusPreference(ctx, repository.UpsertUserFocusPreferenceParams{
+			UserID:                       arg.UserUUID,
+			DayStartsAt:                  preference.DayStartsAt,
+			DayEndsAt:                    preference.DayEndsAt,
+			PrayerDurationInMinutes:      preference.PrayerDurationInMinutes,
+			BreakInMinutes:               preference.BreakInMinutes,
+			DefaultTaskDurationInMinutes: preference.DefaultTaskDurationInMinutes,
+		})
+
+		if err != nil {
+			return repository.FocusPreference{}, fmt.Errorf("failed to upsert user focus preference: %w", err)
+		}
+
+		return preference, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, f.configs.Db.Conn, f.configs.Db.Queries, retryableFunc)
+}
+
+func newUpdateFocusSessionParams(session repository.FocusSession) repository.UpdateFocusSessionParams {
+	return repository.UpdateFocusSessionParams{
+		ID:              session.ID,
+		Status:          session.Status,
+		FocusedSeconds:  session.FocusedSeconds,
+		PausedForPrayer: session.PausedForPrayer,
+		ResumedAt:       session.ResumedAt,
+		EndedAt:         session.EndedAt,
+	}
+}
+
+// settleFocusSession pauses a running session at the first prayer time that
+// passed since it was last resumed, counting only the time before the prayer.
+func settleFocusSession(
+	ctx context.Context,
+	qtx *repository.Queries,
+	session repository.FocusSession,
+	user repository.SelectUserRow,
+	now time.Time,
+) (repository.FocusSession, error) {
+	if session.Status != FocusSessionRunning {
+		return session, nil
+	}
+
+	prayerTime, ok, err := firstPrayerTimeBetween(user, session.ResumedAt.Time, now)
+	if err != nil {
+		return repository.FocusSession{}, err
+	}
+
+	if !ok {
+		return session, nil
+	}
+
+	session.FocusedSeconds += segmentSeconds(session, prayerTime.Time)
+	session.Status = FocusSessionPaused
+	session.PausedForPrayer = pgtype.Text{String: prayerTime.Name, Valid: true}
+	session.ResumedAt = pgtype.Timestamptz{}
+
+	session, err = qtx.UpdateFocusSession(ctx, newUpdateFocusSessionParams(session))
+	if err != nil {
+		return repository.FocusSession{}, fmt.Errorf("failed to pause focus session for prayer: %w", err)
+	}
+
+	return session, nil
+}
+
+func newFocusSessionResult(session repository.FocusSession, user repository.SelectUserRow, now time.Time) (FocusSessionResult, error) {
+	result := FocusSessionResult{
+		Session:        session,
+		FocusedSeconds: int64(session.FocusedSeconds),
+	}
+
+	if session.Status != FocusSessionRunning {
+		return result, nil
+	}
+
+	result.FocusedSeconds += int64(segmentSeconds(session, now))
+	remaining := time.Duration(session.PlannedDurationInMinutes)*time.Minute - time.Duration(result.FocusedSeconds)*time.Second
+	if remaining <= 0 {
+		return result, nil
+	}
+
+	prayerTime, ok, err := firstPrayerTimeBetween(user, now, now.Add(remaining))
+	if err != nil {
+		return FocusSessionResult{}, err
+	}
+
+	if ok {
+		result.UpcomingPrayer = &prayerTime
+	}
+
+	return result, nil
+}
+
+// segmentSeconds returns how long a running session has been running since it
+// was last resumed.
+func segmentSeconds(session repository.FocusSession, until time.Time) int32 {
+	seconds := int32(until.Sub(session.ResumedAt.Time).Seconds())
+	return max(seconds, 0)
+}
+
+// firstPrayerTimeBetween returns the first prayer time of the user after from
+// and no later than to.
+func firstPrayerTimeBetween(user repository.SelectUserRow, from, to time.Time) (PrayerTime, bool, error) {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return PrayerTime{}, false, fmt.Errorf("failed to load timezone location: %w", err)
+	}
+
+	year, month, day := from.In(location).Date()
+	for date := time.Date(year, month, day, 0, 0, 0, 0, location); !date.After(to); date = date.AddDate(0, 0, 1) {
+		schedule, err := calculatePrayerSchedule(CalculatePrayerScheduleParams{
+			Date:      date,
+			Latitude:  user.Coordinates.P.Y,
+			Longitude: user.Coordinates.P.X,
+			Timezone:  user.Timezone,
+		})
+
+		if err != nil {
+			return PrayerTime{}, false, fmt.Errorf("failed to calculate prayer schedule: %w", err)
+		}
+
+		for _, prayerTime := range schedule.Times {
+			if prayerTime.Time.After(from) && !prayerTime.Time.After(to) {
+				return prayerTime, true, nil
+			}
+		}
+	}
+
+	return PrayerTime{}, false, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/oidc"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+const googleProvider = "google"
+
+var (
+	ErrGoogleNotConfigured = errors.New("google sign in isn't configured")
+	// ErrEmailNotVerified means the identity provider didn't verify the email,
+	// so it can't be linked to or create an account.
+	ErrEmailNotVerified = errors.New("email isn't verified by the identity provider")
+)
+
+type AuthenticateGoogleUserParams struct {
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	Nonce        string
+	Client       SessionClient
+}
+
+// AuthenticateGoogleUser signs in with a Google authorization code. Unknown
+// Google accounts are linked to the user with the same verified email, or
+// create a new user.
+func (a auth) AuthenticateGoogleUser(ctx context.Context, arg AuthenticateGoogleUserParams) (authenticateUserResult, error) {
+	if a.google == nil {
+		return authenticateUserResult{}, ErrGoogleNotConfigured
+	}
+
+	claims, err := a.google.Exchange(ctx, oidc.ExchangeParams{
+		Code:         arg.Code,
+		RedirectURI:  arg.RedirectURI,
+		CodeVerifier: arg.CodeVerifier,
+		Nonce:        arg.Nonce,
+	})
+
+	if err != nil {
+		return authenticateUserResult{}, fmt.Errorf("failed to exchange google authorization code: %w", err)
+	}
+
+	retryableFunc := func(qtx *repository.Queries) (authenticateUserResult, error) {
+		user, err := qtx.SelectUserByIdentity(ctx, repository.SelectUserByIdentityParams{
+			Provider: googleProvider,
+			Subject:  claims.Subject,
+		})
+
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return authenticateUserResult{}, fmt.Errorf("failed to select user by identity: %w", err)
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			user, err = a.linkGoogleUser(ctx, qtx, claims)
+			if err != nil {
+				return authenticateUserResult{}, err
+			}
+		}
+
+		mfaToken, err := a.challengeMFA(ctx, qtx, user.ID, arg.Client)
+		if err != nil {
+			return authenticateUserResult{}, err
+		}
+
+		if mfaToken != "" {
+			return authenticateUserResult{User: user, MFAToken: mfaToken}, nil
+		}
+
+		tokens, err := a.startSession(ctx, qtx, user.ID, arg.Client)
+		if err != nil {
+			return authenticateUserResult{}, err
+		}
+
+		authenticateUserResult := authenticateUserResult{
+			User:         user,
+			RefreshToken: tokens.RefreshToken,
+			AccessToken:  tokens.AccessToken,
+		}
+
+		return authenticateUserResult, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+}
+
+func (a auth) linkGoogleUser(ctx context.Context, qtx *repository.Queries, claims *oidc.Claims) (repository.User, error) {
+	if !claims.EmailVerified || claims.Email == "" {
+		return repository.User{}, ErrEmailNotVerified
+	}
+
+	user, err := qtx.SelectUserByEmail(ctx, claims.Email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repository.User{}, fmt.Errorf("failed to select user by email: %w", err)
+	}
+
+	// Users without a password sign in with Google, or set one through the
+	// password reset. Hashing it is slow, so it's only done when needed.
+	if errors.Is(err, pgx.ErrNoRows) {
+		unusablePassword, err := newUnusablePassword()
+		if err != nil {
+			return repository.User{}, err
+		}
+
+		name := claims.Name
+		if len(name) < 2 {
+			name, _, _ = strings.Cut(claims.Email, "@")
+		}
+
+		user, err = a.insertUser(ctx, qtx, repository.InsertUserParams{
+			ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Name:     name,
+			Email:    claims.Email,
+			Password: unusablePassword,
+		})
+
+		if err != nil {
+			return repository.User{}, err
+		}
+	} else if !user.EmailVerifiedAt.Valid {
+		// Whoever registered the unverified email may not own it, so they lose
+		// the password they set, their sessions and personal access tokens, and
+		// their second factor, which would otherwise lock the owner out.
+		unusablePassword, err := newUnusablePassword()
+		if err != nil {
+			return repository.User{}, err
+		}
+
+		_, err = qtx.UpdateUserPassword(ctx, repository.UpdateUserPasswordParams{
+			ID:       user.ID,
+			Password: unusablePassword,
+		})
+
+		if err != nil {
+			return repository.User{}, fmt.Errorf("failed to update user password: %w", err)
+		}
+
+		if _, err := qtx.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+			return repository.User{}, fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+		}
+
+		if _, err := qtx.DeleteUserPersonalAccessTokens(ctx, user.ID); err != nil {
+			return repository.User{}, fmt.Errorf("failed to delete user personal access tokens: %w", err)
+		}
+
+		if _, err := qtx.DeleteUserTOTP(ctx, user.ID); err != nil {
+			return repository.User{}, fmt.Errorf("failed to delete user TOTP: %w", err)
+		}
+
+		if err := qtx.DeleteUserRecoveryCodes(ctx, user.ID); err != nil {
+			return repository.User{}, fmt.Errorf("failed to delete user recovery codes: %w", err)
+		}
+	}
+
+	_, err = qtx.InsertUserIdentity(ctx, repository.InsertUserIdentityParams{
+		Provider: googleProvider,
+		Subject:  claims.Subject,
+		UserID:   user.ID,
+		Email:    claims.Email,
+	})
+
+	if err != nil {
+		return repository.User{}, fmt.Errorf("failed to insert user identity: %w", err)
+	}
+
+	user, err = qtx.VerifyUserEmail(ctx, repository.VerifyUserEmailParams{
+		ID:    user.ID,
+		Email: user.Email,
+	})
+
+	if err != nil {
+		return repository.User{}, fmt.Errorf("failed to verify user email: %w", err)
+	}
+
+	return user, nil
+}
+
+// newUnusablePassword hashes a random password nobody knows.
+func newUnusablePassword() (string, error) {
+	passwordBytes := make([]byte, 32)
+	if _, err := rand.Read(passwordBytes); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	hashedPassword, err := argon2id.CreateHash(base64.RawURLEncoding.EncodeToString(passwordBytes), passwordHashParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return hashedPassword, nil
+}
@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/totp"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+// MFAServicer manages the second factors of a user. Signing in with them is
+// done by AuthServicer.
+type MFAServicer interface {
+	GetMFAStatus(ctx context.Context, userUUID pgtype.UUID) (MFAStatus, error)
+	EnrollTOTP(ctx context.Context, userUUID pgtype.UUID) (EnrollTOTPResult, error)
+	ConfirmTOTP(ctx context.Context, arg VerifySecondFactorParams) ([]string, error)
+	DisableTOTP(ctx context.Context, arg VerifySecondFactorParams) error
+	RegenerateRecoveryCodes(ctx context.Context, arg VerifySecondFactorParams) ([]string, error)
+}
+
+type mfa struct {
+	configs configs.Configs
+}
+
+func NewMFAService(configs configs.Configs) MFAServicer {
+	return &mfa{
+		configs: configs,
+	}
+}
+
+const (
+	totpIssuer         = "Demi Masa"
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 8
+)
+
+var (
+	ErrMFANotConfigured   = errors.New("two-factor authentication isn't configured")
+	ErrTOTPAlreadyEnabled = errors.New("TOTP is already enabled")
+	ErrTOTPNotEnabled     = errors.New("TOTP isn't enabled")
+	ErrInvalidMFACode     = errors.New("invalid, used or expired two-factor code")
+	// ErrMFAEmailNotVerified means the user has to verify their email before
+	// enrolling, otherwise whoever registered someone else's email could lock
+	// the owner out with a second factor the owner doesn't have.
+	ErrMFAEmailNotVerified = errors.New("email has to be verified to enable two-factor authentication")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpEncryptionKey returns the key TOTP secrets are encrypted with.
+// LoadEnv already checked that it decodes to 32 bytes.
+func totpEncryptionKey(env configs.Env) ([]byte, error) {
+	if env.TOTPEncryptionKey == "" {
+		return nil, ErrMFANotConfigured
+	}
+	return base64.StdEncoding.DecodeString(env.TOTPEncryptionKey)
+}
+
+// encryptTOTPSecret seals the secret with AES-GCM. The user id is authenticated
+// along with it, so a secret can't be copied over to another user.
+func encryptTOTPSecret(key []byte, userUUID pgtype.UUID, secret []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, secret, userUUID.Bytes[:])
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptTOTPSecret(key []byte, userUUID pgtype.UUID, encryptedSecret string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode TOTP secret: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("failed to decrypt TOTP secret: too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, ciphertext, userUUID.Bytes[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// normalizeRecoveryCode lets recovery codes be typed in any case, with or
+// without the dash.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// hashRecoveryCode hashes recovery codes before they are stored. Like reset
+// tokens, they are random, so a fast hash is enough.
+func hashRecoveryCode(code string) string {
+	hash := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(hash[:])
+}
+
+// replaceRecoveryCodes invalidates the recovery codes of the user and returns
+// new ones, formatted as xxxx-xxxx.
+func replaceRecoveryCodes(ctx context.Context, qtx *repository.Queries, userUUID pgtype.UUID) ([]string, error) {
+	if err := qtx.DeleteUserRecoveryCodes(ctx, userUUID); err != nil {
+		return nil, fmt.Errorf("failed to delete user recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	insertParams := make([]repository.InsertUserRecoveryCodesParams, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		codeBytes := make([]byte, 5)
+		if _, err := rand.Read(codeBytes); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(codeBytes))
+		code = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+
+		codes = append(codes, code)
+		insertParams = append(insertParams, repository.InsertUserRecoveryCodesParams{
+			ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			UserID:   userUUID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+
+	if _, err := qtx.InsertUserRecoveryCodes(ctx, insertParams); err != nil {
+		return nil, fmt.Errorf("failed to insert user recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// isTOTPCode tells TOTP codes apart from recovery codes, which are longer.
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != 6 {
+		return false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verifySecondFactor accepts a TOTP code or an unused recovery code of the
+// user, and uses it up. It returns ErrTOTPNotEnabled when the user has no
+// confirmed TOTP.
+func verifySecondFactor(ctx context.Context, env configs.Env, queries *repository.Queries, userUUID pgtype.UUID, code string) error {
+	userTOTP, err := queries.SelectUserTOTP(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTOTPNotEnabled
+		}
+		return fmt.Errorf("failed to select user TOTP: %w", err)
+	}
+
+	if !userTOTP.ConfirmedAt.Valid {
+		return ErrTOTPNotEnabled
+	}
+
+	if !isTOTPCode(code) {
+		usedCodes, err := queries.UseRecoveryCode(ctx, repository.UseRecoveryCodeParams{
+			UserID:   userUUID,
+			CodeHash: hashRecoveryCode(code),
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to use recovery code: %w", err)
+		}
+
+		if usedCodes == 0 {
+			return ErrInvalidMFACode
+		}
+
+		return nil
+	}
+
+	key, err := totpEncryptionKey(env)
+	if err != nil {
+		return err
+	}
+
+	secret, err := decryptTOTPSecret(key, userUUID, userTOTP.EncryptedSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), userTOTP.LastUsedStep)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	// The step only moves forward, so a code accepted concurrently can't be
+	// accepted again.
+	usedSteps, err := queries.UseUserTOTPStep(ctx, repository.UseUserTOTPStepParams{
+		UserID:       userUUID,
+		LastUsedStep: step,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to use user TOTP step: %w", err)
+	}
+
+	if usedSteps == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+type MFAStatus struct {
+	TOTPEnabled            bool
+	RecoveryCodesRemaining int64
+}
+
+func (m mfa) GetMFAStatus(ctx context.Context, userUUID pgtype.UUID) (MFAStatus, error) {
+	userTOTP, err := retryutil.RetryWithData(func() (repository.UserTotp, error) {
+		return m.configs.Db.Queries.SelectUserTOTP(ctx, userUUID)
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MFAStatus{}, nil
+		}
+		return MFAStatus{}, fmt.Errorf("failed to select user TOTP: %w", err)
+	}
+
+	if !userTOTP.ConfirmedAt.Valid {
+		return MFAStatus{}, nil
+	}
+
+	recoveryCodes, err := retryutil.RetryWithData(func() (int64, error) {
+		return m.configs.Db.Queries.CountUserRecoveryCodes(ctx, userUUID)
+	})
+
+	if err != nil {
+		return MFAStatus{}, fmt.Errorf("failed to count user recovery codes: %w", err)
+	}
+
+	return MFAStatus{TOTPEnabled: true, RecoveryCodesRemaining: recoveryCodes}, nil
+}
+
+type EnrollTOTPResult struct {
+	Secret string
+	URI    string
+}
+
+// EnrollTOTP generates the secret of a TOTP that is enabled once a code of it
+// is confirmed. Enrolling again before confirming replaces the secret. Only
+// users with a verified email can enrol.
+func (m mfa) EnrollTOTP(ctx context.Context, userUUID pgtype.UUID) (EnrollTOTPResult, error) {
+	key, err := totpEncryptionKey(m.configs.Env)
+	if err != nil {
+		return EnrollTOTPResult{}, err
+	}
+
+	user, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return m.configs.Db.Queries.SelectUser(ctx, userUUID)
+	})
+
+	if err != nil {
+		return EnrollTOTPResult{}, fmt.Errorf("failed to select user: %w", err)
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		return EnrollTOTPResult{}, ErrMFAEmailNotVerified
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return EnrollTOTPResult{}, err
+	}
+
+	encryptedSecret, err := encryptTOTPSecret(key, userUUID, secret)
+	if err != nil {
+		return EnrollTOTPResult{}, err
+	}
+
+	_, err = retryutil.RetryWithData(func() (repository.UserTotp, error) {
+		return m.configs.Db.Queries.UpsertUserTOTP(ctx, repository.UpsertUserTOTPParams{
+			UserID:          userUUID,
+			EncryptedSecret: encryptedSecret,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return EnrollTOTPResult{}, ErrTOTPAlreadyEnabled
+		}
+		return EnrollTOTPResult{}, fmt.Errorf("failed to upsert user TOTP: %w", err)
+	}
+
+	enrollTOTPResult := EnrollTOTPResult{
+		Secret: totp.EncodeSecret(secret),
+		URI: totp.URI(totp.URIParams{
+			Issuer:      totpIssuer,
+			AccountName: user.Email,
+			Secret:      secret,
+		}),
+	}
+
+	return enrollTOTPResult, nil
+}
+
+type VerifySecondFactorParams struct {
+	UserUUID pgtype.UUID
+	Code     string
+}
+
+// ConfirmTOTP enables the enrolled TOTP with a code of it and returns the
+// recovery codes, which are only ever shown here.
+func (m mfa) ConfirmTOTP(ctx context.Context, arg VerifySecondFactorParams) ([]string, error) {
+	key, err := totpEncryptionKey(m.configs.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	retryableFunc := func(qtx *repository.Queries) ([]string, error) {
+		// Enrolments made before enrolling required a verified email.
+		user, err := qtx.SelectUser(ctx, arg.UserUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		if !user.EmailVerifiedAt.Valid {
+			return nil, ErrMFAEmailNotVerified
+		}
+
+		userTOTP, err := qtx.SelectUserTOTP(ctx, arg.UserUUID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrTOTPNotEnabled
+			}
+			return nil, fmt.Errorf("failed to select user TOTP: %w", err)
+		}
+
+		if userTOTP.ConfirmedAt.Valid {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+
+		secret, err := decryptTOTPSecret(key, arg.UserUUID, userTOTP.EncryptedSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		step, ok := totp.Validate(secret, arg.Code, time.Now(), userTOTP.LastUsedStep)
+		if !ok {
+			return nil, ErrInvalidMFACode
+		}
+
+		_, err = qtx.ConfirmUserTOTP(ctx, repository.ConfirmUserTOTPParams{
+			UserID:       arg.UserUUID,
+			LastUsedStep: step,
+		})
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrTOTPAlreadyEnabled
+			}
+			return nil, fmt.Errorf("failed to confirm user TOTP: %w", err)
+		}
+
+		return replaceRecoveryCodes(ctx, qtx, arg.UserUUID)
+	}
+
+	return dbutil.RetryableTxWithData(ctx, m.configs.Db.Conn, m.configs.Db.Queries, retryableFunc)
+}
+
+// DisableTOTP turns two-factor authentication off with a TOTP or recovery
+// code, and deletes the recovery codes.
+func (m mfa) DisableTOTP(ctx context.Context, arg VerifySecondFactorParams) error {
+	retryableFunc := func(qtx *repository.Queries) error {
+		if err := verifySecondFactor(ctx, m.configs.Env, qtx, arg.UserUUID, arg.Code); err != nil {
+			return err
+		}
+
+		if _, err := qtx.DeleteUserTOTP(ctx, arg.UserUUID); err != nil {
+			return fmt.Errorf("failed to delete user TOTP: %w", err)
+		}
+
+		if err := qtx.DeleteUserRecoveryCodes(ctx, arg.UserUUID); err != nil {
+			return fmt.Errorf("failed to delete user recovery codes: %w", err)
+		}
+
+		return nil
+	}
+
+	return dbutil.RetryableTxWithoutData(ctx, m.configs.Db.Conn, m.configs.Db.Queries, retryableFunc)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after verifying a TOTP
+// or recovery code.
+func (m mfa) RegenerateRecoveryCodes(ctx context.Context, arg VerifySecondFactorParams) ([]string, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]string, error) {
+		if err := verifySecondFactor(ctx, m.configs.Env, qtx, arg.UserUUID, arg.Code); err != nil {
+			return nil, err
+		}
+
+		return replaceRecoveryCodes(ctx, qtx, arg.UserUUID)
+	}
+
+	return dbutil.RetryableTxWithData(ctx, m.configs.Db.Conn, m.configs.Db.Queries, retryableFunc)
+}
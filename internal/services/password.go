@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/mailer"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+const passwordResetExpiration = 30 * time.Minute
+
+// passwordHashParams are used for every password stored, whether it is set on
+// registration, reset or change.
+var passwordHashParams = argon2id.DefaultParams
+
+var ErrInvalidResetToken = errors.New("invalid, used or expired password reset token")
+
+// hashResetToken hashes reset tokens before they are stored, so a leaked
+// table can't be used to reset passwords. The tokens are random, a fast hash
+// is enough.
+func hashResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// ForgotPassword mails a password reset link to the user with the email. It
+// doesn't tell whether the user exists, so emails can't be enumerated.
+// Requesting another link invalidates the previous ones.
+func (a auth) ForgotPassword(ctx context.Context, email string) error {
+	user, err := retryutil.RetryWithData(func() (repository.User, error) {
+		return a.configs.Db.Queries.SelectUserByEmail(ctx, email)
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to select user by email: %w", err)
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	retryableFunc := func(qtx *repository.Queries) error {
+		if err := qtx.DeleteUserPasswordResetTokens(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to delete user password reset tokens: %w", err)
+		}
+
+		_, err := qtx.InsertUserPasswordResetToken(ctx, repository.InsertUserPasswordResetTokenParams{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			UserID:    user.ID,
+			TokenHash: hashResetToken(token),
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(passwordResetExpiration), Valid: true},
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to insert user password reset token: %w", err)
+		}
+
+		return nil
+	}
+
+	err = dbutil.RetryableTxWithoutData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+	if err != nil {
+		return err
+	}
+
+	resetURL := fmt.Sprintf("%s/reset-password?%s", a.configs.Env.WebAppURL, url.Values{"token": {token}}.Encode())
+	err = a.configs.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to choose a new password. The link expires in 30 minutes and can only be used once.\n\n%s\n\nIf you didn't ask to reset your password, you can ignore this mail.\n",
+			user.Name,
+			resetURL,
+		),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send password reset: %w", err)
+	}
+
+	return nil
+}
+
+type ResetPasswordParams struct {
+	Token    string
+	Password string
+}
+
+// ResetPassword sets a new password with a reset token, signs the user out of
+// every session, and revokes their personal access tokens.
+func (a auth) ResetPassword(ctx context.Context, arg ResetPasswordParams) error {
+	hashedPassword, err := argon2id.CreateHash(arg.Password, passwordHashParams)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	retryableFunc := func(qtx *repository.Queries) error {
+		passwordResetToken, err := qtx.UsePasswordResetToken(ctx, hashResetToken(arg.Token))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidResetToken
+			}
+			return fmt.Errorf("failed to use password reset token: %w", err)
+		}
+
+		_, err = qtx.UpdateUserPassword(ctx, repository.UpdateUserPasswordParams{
+			ID:       passwordResetToken.UserID,
+			Password: hashedPassword,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to update user password: %w", err)
+		}
+
+		if _, err := qtx.RevokeUserRefreshTokens(ctx, passwordResetToken.UserID); err != nil {
+			return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+		}
+
+		if _, err := qtx.DeleteUserPersonalAccessTokens(ctx, passwordResetToken.UserID); err != nil {
+			return fmt.Errorf("failed to delete user personal access tokens: %w", err)
+		}
+
+		return nil
+	}
+
+	return dbutil.RetryableTxWithoutData(ctx, a.configs.Db.Conn, a.configs.Db.Queries, retryableFunc)
+}
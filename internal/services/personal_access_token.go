@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+// PersonalAccessTokenServicer manages the tokens users create for scripts and
+// integrations. Unlike access tokens they are opaque, only valid for their
+// scopes, and last until they expire or are revoked.
+//
+// Resetting the password revokes them along with every session, since it is
+// how users take back an account someone else got into, as does a Google
+// sign in taking over an unverified account. Changing the password keeps
+// them: the user is signed in, keeps their current session too, and can
+// review and revoke the tokens themselves.
+type PersonalAccessTokenServicer interface {
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (CreatePersonalAccessTokenResult, error)
+	ValidatePersonalAccessToken(ctx context.Context, tokenString string) (repository.PersonalAccessToken, error)
+}
+
+type personalAccessToken struct {
+	configs configs.Configs
+}
+
+func NewPersonalAccessTokenService(configs configs.Configs) PersonalAccessTokenServicer {
+	return &personalAccessToken{
+		configs: configs,
+	}
+}
+
+const (
+	// PersonalAccessTokenPrefix tells personal access tokens apart from
+	// access tokens, and makes leaked ones easy to scan for.
+	PersonalAccessTokenPrefix = "dmpat_"
+	// personalAccessTokenPrefixLength is how much of a token is stored in
+	// plain, so users can recognize their tokens.
+	personalAccessTokenPrefixLength = len(PersonalAccessTokenPrefix) + 6
+	maxPersonalAccessTokens         = 20
+)
+
+var (
+	ErrTooManyPersonalAccessTokens = errors.New("too many personal access tokens")
+	ErrInvalidPersonalAccessToken  = errors.New("invalid, revoked or expired personal access token")
+)
+
+// hashPersonalAccessToken hashes tokens before they are stored. The tokens are
+// random, a fast hash is enough.
+func hashPersonalAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+type CreatePersonalAccessTokenParams struct {
+	UserUUID pgtype.UUID
+	Name     string
+	Scopes   []string
+	// ExpiresAt is zero for tokens that don't expire.
+	ExpiresAt time.Time
+}
+
+type CreatePersonalAccessTokenResult struct {
+	PersonalAccessToken repository.PersonalAccessToken
+	// Token is only known when it is created, afterwards only its hash is.
+	Token string
+}
+
+func (p personalAccessToken) CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (CreatePersonalAccessTokenResult, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return CreatePersonalAccessTokenResult{}, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	token := PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	var expiresAt pgtype.Timestamptz
+	if !arg.ExpiresAt.IsZero() {
+		expiresAt = pgtype.Timestamptz{Time: arg.ExpiresAt, Valid: true}
+	}
+
+	retryableFunc := func(qtx *repository.Queries) (repository.PersonalAccessToken, error) {
+		// Counting within the transaction isn't exact under concurrency, but
+		// the limit only keeps the list manageable.
+		tokenCount, err := qtx.CountUserPersonalAccessTokens(ctx, arg.UserUUID)
+		if err != nil {
+			return repository.PersonalAccessToken{}, fmt.Errorf("failed to count user personal access tokens: %w", err)
+		}
+
+		if tokenCount >= maxPersonalAccessTokens {
+			return repository.PersonalAccessToken{}, ErrTooManyPersonalAccessTokens
+		}
+
+		personalAccessToken, err := qtx.InsertPersonalAccessToken(ctx, repository.InsertPersonalAccessTokenParams{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			UserID:      arg.UserUUID,
+			Name:        arg.Name,
+			TokenPrefix: token[:personalAccessTokenPrefixLength],
+			TokenHash:   hashPersonalAccessToken(token),
+			Scopes:      arg.Scopes,
+			ExpiresAt:   expiresAt,
+		})
+
+		if err != nil {
+			return repository.PersonalAccessToken{}, fmt.Errorf("failed to insert personal access token: %w", err)
+		}
+
+		return personalAccessToken, nil
+	}
+
+	personalAccessToken, err := dbutil.RetryableTxWithData(ctx, p.configs.Db.Conn, p.configs.Db.Queries, retryableFunc)
+	if err != nil {
+		return CreatePersonalAccessTokenResult{}, err
+	}
+
+	return CreatePersonalAccessTokenResult{PersonalAccessToken: personalAccessToken, Token: token}, nil
+}
+
+// ValidatePersonalAccessToken returns the token if it exists and didn't expire,
+// and records that it was used.
+func (p personalAccessToken) ValidatePersonalAccessToken(ctx context.Context, tokenString string) (repository.PersonalAccessToken, error) {
+	if !strings.HasPrefix(tokenString, PersonalAccessTokenPrefix) {
+		return repository.PersonalAccessToken{}, ErrInvalidPersonalAccessToken
+	}
+
+	personalAccessToken, err := retryutil.RetryWithData(func() (repository.PersonalAccessToken, error) {
+		return p.configs.Db.Queries.UsePersonalAccessToken(ctx, hashPersonalAccessToken(tokenString))
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.PersonalAccessToken{}, ErrInvalidPersonalAccessToken
+		}
+		return repository.PersonalAccessToken{}, fmt.Errorf("failed to use personal access token: %w", err)
+	}
+
+	return personalAccessToken, nil
+}
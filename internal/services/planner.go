@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+type PlannerServicer interface {
+	PlanDay(ctx context.Context, arg PlanDayParams) (DayPlan, error)
+}
+
+const (
+	// UnscheduledNoFreeSlot means no free slot left in the day is long enough
+	// for the task.
+	UnscheduledNoFreeSlot = "no_free_slot"
+	// UnscheduledNoFreeSlotBeforeDue means the task only fits after its due
+	// time.
+	UnscheduledNoFreeSlotBeforeDue = "no_free_slot_before_due"
+	// UnscheduledNoFreeSlotNearAnchor means the task doesn't fit between the
+	// time it is anchored to and the next prayer.
+	UnscheduledNoFreeSlotNearAnchor = "no_free_slot_near_anchor"
+)
+
+type planner struct {
+	configs configs.Configs
+}
+
+func NewPlannerService(configs configs.Configs) PlannerServicer {
+	return &planner{
+		configs: configs,
+	}
+}
+
+type PlanDayParams struct {
+	UserUUID pgtype.UUID
+	// Date defaults to today in the user's timezone.
+	Date time.Time
+}
+
+type PlannedPrayer struct {
+	Name     string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// PlannedTask is a task placed into the day. For recurring tasks it is the
+// occurrence on the date, with the name and description of the occurrence.
+type PlannedTask struct {
+	Task     repository.Task
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+type UnscheduledTask struct {
+	Task              repository.Task
+	DurationInMinutes int16
+	Reason            string
+}
+
+// plannableTask is a task to place into the day, with the window its anchor
+// allows. The window is zero for tasks that aren't anchored.
+type plannableTask struct {
+	task              repository.Task
+	durationInMinutes int16
+	window            timeSlot
+}
+
+type DayPlan struct {
+	Date        time.Time
+	DayStartsAt time.Time
+	DayEndsAt   time.Time
+	Prayers     []PlannedPrayer
+	Tasks       []PlannedTask
+	Unscheduled []UnscheduledTask
+}
+
+// timeSlot is a free range of the day, start moves forward as tasks are
+// placed into it.
+type timeSlot struct {
+	start time.Time
+	end   time.Time
+}
+
+// PlanDay places the unchecked tasks of the date into the free time between
+// prayers. These are the tasks due on the date, the occurrences of recurring
+// tasks on it, and the tasks anchored to a prayer without a due time. Anchored
+// tasks are placed first, as close after their resolved time as the free time
+// allows. The rest are placed first fit in the order of priority and due time.
+// A task takes its estimated duration, or the default one of the user's focus
+// preference, followed by a break.
+func (p planner) PlanDay(ctx context.Context, arg PlanDayParams) (DayPlan, error) {
+	retryableFunc := func(qtx *repository.Queries) (DayPlan, error) {
+		user, err := qtx.SelectUser(ctx, arg.UserUUID)
+		if err != nil {
+			return DayPlan{}, fmt.Errorf("failed to select user: %w", err)
+		}
+
+		location, err := time.LoadLocation(user.Timezone)
+		if err != nil {
+			return DayPlan{}, fmt.Errorf("failed to load timezone location: %w", err)
+		}
+
+		preference, err := selectFocusPreference(ctx, qtx, arg.UserUUID)
+		if err != nil {
+			return DayPlan{}, err
+		}
+
+		now := time.Now()
+		date := arg.Date
+		if date.IsZero() {
+			date = now.In(location)
+		}
+
+		year, month, day := date.Date()
+		midnight := time.Date(year, month, day, 0, 0, 0, 0, location)
+		plan := DayPlan{
+			Date:        midnight,
+			DayStartsAt: midnight.Add(time.Duration(preference.DayStartsAt.Microseconds) * time.Microsecond),
+			DayEndsAt:   midnight.Add(time.Duration(preference.DayEndsAt.Microseconds) * time.Microsecond),
+		}
+
+		schedule, err := calculatePrayerSchedule(CalculatePrayerScheduleParams{
+			Date:      midnight,
+			Latitude:  user.Coordinates.P.Y,
+			Longitude: user.Coordinates.P.X,
+			Timezone:  user.Timezone,
+		})
+
+		if err != nil {
+			return DayPlan{}, fmt.Errorf("failed to calculate prayer schedule: %w", err)
+		}
+
+		prayerDuration := time.Duration(preference.PrayerDurationInMinutes) * time.Minute
+		for _, prayerTime := range schedule.Times {
+			plan.Prayers = append(plan.Prayers, PlannedPrayer{
+				Name:     prayerTime.Name,
+				StartsAt: prayerTime.Time,
+				EndsAt:   prayerTime.Time.Add(prayerDuration),
+			})
+		}
+
+		tasks, err := selectPlannableTasks(ctx, qtx, arg.UserUUID, midnight)
+		if err != nil {
+			return DayPlan{}, err
+		}
+
+		taskSchedules, err := resolveTaskSchedules(user, midnight, tasks)
+		if err != nil {
+			return DayPlan{}, err
+		}
+
+		var anchoredTasks, otherTasks []plannableTask
+		for _, task := range tasks {
+			plannable := plannableTask{task: task, durationInMinutes: preference.DefaultTaskDurationInMinutes}
+			if task.EstimatedDurationInMinutes.Valid {
+				plannable.durationInMinutes = task.EstimatedDurationInMinutes.Int16
+			}
+
+			taskSchedule, ok := taskSchedules.TaskSchedules[task.ID]
+			if !ok {
+				otherTasks = append(otherTasks, plannable)
+				continue
+			}
+
+			plannable.window = timeSlot{start: taskSchedule.StartsAt, end: taskSchedule.EndsAt}
+			if plannable.window.end.IsZero() {
+				plannable.window.end = nextPrayerTime(schedule, taskSchedule.StartsAt, plan.DayEndsAt)
+			}
+			anchoredTasks = append(anchoredTasks, plannable)
+		}
+
+		// Time that already passed today can't be planned anymore.
+		start := plan.DayStartsAt
+		if now.After(start) {
+			start = now.Truncate(time.Minute).Add(time.Minute)
+		}
+
+		slots := freeTimeSlots(start, plan.DayEndsAt, plan.Prayers)
+		breakDuration := time.Duration(preference.BreakInMinutes) * time.Minute
+
+		for _, plannable := range anchoredTasks {
+			duration := time.Duration(plannable.durationInMinutes) * time.Minute
+			index, startsAt := firstFittingTimeSlotWithin(slots, duration, plannable.window)
+			if index == -1 {
+				plan.Unscheduled = append(plan.Unscheduled, UnscheduledTask{
+					Task:              plannable.task,
+					DurationInMinutes: plannable.durationInMinutes,
+					Reason:            UnscheduledNoFreeSlotNearAnchor,
+				})
+				continue
+			}
+
+			plannedTask := PlannedTask{
+				Task:     plannable.task,
+				StartsAt: startsAt,
+				EndsAt:   startsAt.Add(duration),
+			}
+
+			plan.Tasks = append(plan.Tasks, plannedTask)
+			slots = splitTimeSlot(slots, index, plannedTask.StartsAt, plannedTask.EndsAt.Add(breakDuration))
+		}
+
+		for _, plannable := range otherTasks {
+			dueAt := plan.DayEndsAt
+			if plannable.task.DueAt.Valid {
+				dueAt = plannable.task.DueAt.Time
+			}
+
+			duration := time.Duration(plannable.durationInMinutes) * time.Minute
+			index, reason := firstFittingTimeSlot(slots, duration, dueAt)
+			if reason != "" {
+				plan.Unscheduled = append(plan.Unscheduled, UnscheduledTask{
+					Task:              plannable.task,
+					DurationInMinutes: plannable.durationInMinutes,
+					Reason:            reason,
+				})
+				continue
+			}
+
+			plannedTask := PlannedTask{
+				Task:     plannable.task,
+				StartsAt: slots[index].start,
+				EndsAt:   slots[index].start.Add(duration),
+			}
+
+			plan.Tasks = append(plan.Tasks, plannedTask)
+			slots[index].start = plannedTask.EndsAt.Add(breakDuration)
+		}
+
+		slices.SortStableFunc(plan.Tasks, func(a, b PlannedTask) int {
+			return a.StartsAt.Compare(b.StartsAt)
+		})
+
+		return plan, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, p.configs.Db.Conn, p.configs.Db.Queries, retryableFunc)
+}
+
+// selectPlannableTasks selects the unchecked tasks of the day at midnight. A
+// recurring task is only kept when it occurs on the day and its occurrence is
+// neither checked nor skipped, with the name and description of the
+// occurrence.
+func selectPlannableTasks(ctx context.Context, qtx *repository.Queries, userUUID pgtype.UUID, midnight time.Time) ([]repository.Task, error) {
+	tasks, err := qtx.SelectUserPlannableTasks(ctx, repository.SelectUserPlannableTasksParams{
+		UserID:   userUUID,
+		Date:     pgtype.Date{Time: midnight, Valid: true},
+		DueFrom:  pgtype.Timestamptz{Time: midnight, Valid: true},
+		DueUntil: pgtype.Timestamptz{Time: midnight.AddDate(0, 0, 1), Valid: true},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select user plannable tasks: %w", err)
+	}
+
+	var recurringTaskIDs []pgtype.UUID
+	for _, task := range tasks {
+		if task.RecurrenceRule.Valid {
+			recurringTaskIDs = append(recurringTaskIDs, task.ID)
+		}
+	}
+
+	if len(recurringTaskIDs) == 0 {
+		return tasks, nil
+	}
+
+	overrides, err := qtx.SelectTasksOccurrencesOn(ctx, repository.SelectTasksOccurrencesOnParams{
+		TaskIds:        recurringTaskIDs,
+		OccurrenceDate: pgtype.Date{Time: midnight, Valid: true},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks occurrences: %w", err)
+	}
+
+	overridesByTaskID := make(map[pgtype.UUID]repository.TaskOccurrence, len(overrides))
+	for _, override := range overrides {
+		overridesByTaskID[override.TaskID] = override
+	}
+
+	plannableTasks := tasks[:0]
+	for _, task := range tasks {
+		if !task.RecurrenceRule.Valid {
+			plannableTasks = append(plannableTasks, task)
+			continue
+		}
+
+		rule, err := parseTaskRecurrence(task)
+		if err != nil {
+			return nil, err
+		}
+
+		if !rule.Occurs(task.RecurrenceStart.Time, midnight) {
+			continue
+		}
+
+		occurrence := newTaskOccurrence(task, midnight, overridesByTaskID[task.ID])
+		if occurrence.Checked || occurrence.Skipped {
+			continue
+		}
+
+		task.Name = occurrence.Name
+		task.Description = occurrence.Description
+		task.Checked = false
+		plannableTasks = append(plannableTasks, task)
+	}
+
+	return plannableTasks, nil
+}
+
+// nextPrayerTime returns the first prayer time after t, or else fallback.
+func nextPrayerTime(schedule PrayerSchedule, t, fallback time.Time) time.Time {
+	for _, prayerTime := range schedule.Times {
+		if prayerTime.Time.After(t) {
+			return prayerTime.Time
+		}
+	}
+	return fallback
+}
+
+// freeTimeSlots splits the range from start to end around the prayers, which
+// are ordered by their time.
+func freeTimeSlots(start, end time.Time, prayers []PlannedPrayer) []timeSlot {
+	slots := make([]timeSlot, 0, len(prayers)+1)
+	for _, prayer := range prayers {
+		if prayer.StartsAt.After(start) {
+			slotEnd := prayer.StartsAt
+			if slotEnd.After(end) {
+				slotEnd = end
+			}
+			slots = append(slots, timeSlot{start: start, end: slotEnd})
+		}
+
+		if prayer.EndsAt.After(start) {
+			start = prayer.EndsAt
+		}
+	}
+
+	slots = append(slots, timeSlot{start: start, end: end})
+
+	freeSlots := slots[:0]
+	for _, slot := range slots {
+		if slot.end.After(slot.start) {
+			freeSlots = append(freeSlots, slot)
+		}
+	}
+
+	return freeSlots
+}
+
+// firstFittingTimeSlotWithin returns the index of the first slot that fits
+// duration within window, along with the earliest start there. The index is -1
+// when there is none.
+func firstFittingTimeSlotWithin(slots []timeSlot, duration time.Duration, window timeSlot) (int, time.Time) {
+	for i, slot := range slots {
+		start := slot.start
+		if window.start.After(start) {
+			start = window.start
+		}
+
+		end := start.Add(duration)
+		if end.After(slot.end) || end.After(window.end) {
+			continue
+		}
+
+		return i, start
+	}
+
+	return -1, time.Time{}
+}
+
+// splitTimeSlot takes the range from start to end out of the slot at index,
+// keeping the free time left on either side of it.
+func splitTimeSlot(slots []timeSlot, index int, start, end time.Time) []timeSlot {
+	slot := slots[index]
+	var remaining []timeSlot
+	if start.After(slot.start) {
+		remaining = append(remaining, timeSlot{start: slot.start, end: start})
+	}
+
+	if slot.end.After(end) {
+		remaining = append(remaining, timeSlot{start: end, end: slot.end})
+	}
+
+	return slices.Replace(slots, index, index+1, remaining...)
+}
+
+// firstFittingTimeSlot returns the index of the first slot that fits duration
+// and ends no later than dueAt. The reason is set when there is none.
+func firstFittingTimeSlot(slots []timeSlot, duration time.Duration, dueAt time.Time) (int, string) {
+	reason := UnscheduledNoFreeSlot
+	for i, slot := range slots {
+		end := slot.start.Add(duration)
+		if end.After(slot.end) {
+			continue
+		}
+
+		if end.After(dueAt) {
+			reason = UnscheduledNoFreeSlotBeforeDue
+			continue
+		}
+
+		return i, ""
+	}
+
+	return -1, reason
+}
@@ -3,13 +3,16 @@ package services
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"time"
 
 	"github.com/mdayat/demi-masa-backend-service/configs"
 )
 
 type PrayerServicer interface {
 	ValidateYearAndMonthParams(yearString, monthString string) (int, int, error)
+	CalculatePrayerSchedule(arg CalculatePrayerScheduleParams) (PrayerSchedule, error)
 }
 
 type prayer struct {
@@ -43,3 +46,183 @@ func (p prayer) ValidateYearAndMonthParams(yearString, monthString string) (int,
 
 	return year, month, nil
 }
+
+var prayerNames = []prayerName{subuh, zuhur, asar, magrib, isya}
+
+type PrayerTime struct {
+	Name string
+	Time time.Time
+}
+
+// PrayerSchedule holds the five daily prayer times of a single day, ordered
+// from subuh to isya.
+type PrayerSchedule struct {
+	Date  time.Time
+	Times []PrayerTime
+}
+
+func (p PrayerSchedule) Time(name string) (time.Time, bool) {
+	for _, prayerTime := range p.Times {
+		if prayerTime.Name == name {
+			return prayerTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Slot returns the name of the prayer whose window contains t. A prayer window
+// starts at its own time and ends at the next prayer, so anything before subuh
+// falls into the subuh slot.
+func (p PrayerSchedule) Slot(t time.Time) string {
+	slot := string(subuh)
+	for _, prayerTime := range p.Times {
+		if prayerTime.Time.After(t) {
+			break
+		}
+		slot = prayerTime.Name
+	}
+	return slot
+}
+
+type CalculatePrayerScheduleParams struct {
+	Date      time.Time
+	Latitude  float64
+	Longitude float64
+	Timezone  string
+}
+
+// The calculation follows the parameters used by the Indonesian Ministry of
+// Religious Affairs (Kemenag): 20° for subuh, 18° for isya, a shadow factor of
+// one for asar, and a two minute precautionary offset (ihtiyat).
+const (
+	subuhAngle       = 20.0
+	isyaAngle        = 18.0
+	sunsetAngle      = 0.833
+	asarShadowFactor = 1.0
+	ihtiyat          = 2 * time.Minute
+)
+
+func (p prayer) CalculatePrayerSchedule(arg CalculatePrayerScheduleParams) (PrayerSchedule, error) {
+	return calculatePrayerSchedule(arg)
+}
+
+func calculatePrayerSchedule(arg CalculatePrayerScheduleParams) (PrayerSchedule, error) {
+	location, err := time.LoadLocation(arg.Timezone)
+	if err != nil {
+		return PrayerSchedule{}, fmt.Errorf("failed to load timezone location: %w", err)
+	}
+
+	year, month, day := arg.Date.Date()
+	julianDate := toJulianDate(year, int(month), day) - arg.Longitude/(15*24)
+
+	// Each time is first estimated from a default hour and then refined once
+	// using the sun position at that estimate.
+	hours := map[prayerName]float64{
+		subuh:  sunAngleTime(julianDate, arg.Latitude, subuhAngle, 5.0/24, true),
+		zuhur:  midDay(julianDate, 12.0/24),
+		asar:   asarTime(julianDate, arg.Latitude, asarShadowFactor, 13.0/24),
+		magrib: sunAngleTime(julianDate, arg.Latitude, sunsetAngle, 18.0/24, false),
+		isya:   sunAngleTime(julianDate, arg.Latitude, isyaAngle, 18.0/24, false),
+	}
+
+	utcMidnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	schedule := PrayerSchedule{
+		Date:  time.Date(year, month, day, 0, 0, 0, 0, location),
+		Times: make([]PrayerTime, 0, len(prayerNames)),
+	}
+
+	for _, name := range prayerNames {
+		hour := hours[name]
+		if math.IsNaN(hour) {
+			return PrayerSchedule{}, fmt.Errorf("%s time is undefined at latitude %f", name, arg.Latitude)
+		}
+
+		utcHour := hour - arg.Longitude/15
+		prayerTime := utcMidnight.Add(time.Duration(utcHour * float64(time.Hour))).Add(ihtiyat)
+
+		schedule.Times = append(schedule.Times, PrayerTime{
+			Name: string(name),
+			Time: prayerTime.Truncate(time.Minute).In(location),
+		})
+	}
+
+	return schedule, nil
+}
+
+func toJulianDate(year, month, day int) float64 {
+	if month <= 2 {
+		year--
+		month += 12
+	}
+
+	a := math.Floor(float64(year) / 100)
+	b := 2 - a + math.Floor(a/4)
+	return math.Floor(365.25*float64(year+4716)) + math.Floor(30.6001*float64(month+1)) + float64(day) + b - 1524.5
+}
+
+func degreeToRadian(degree float64) float64 {
+	return degree * math.Pi / 180
+}
+
+func radianToDegree(radian float64) float64 {
+	return radian * 180 / math.Pi
+}
+
+func fixAngle(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle
+}
+
+func fixHour(hour float64) float64 {
+	hour = math.Mod(hour, 24)
+	if hour < 0 {
+		hour += 24
+	}
+	return hour
+}
+
+// sunPosition returns the declination of the sun (in degrees) and the
+// equation of time (in hours) for the given julian date.
+func sunPosition(julianDate float64) (float64, float64) {
+	d := julianDate - 2451545.0
+	g := fixAngle(357.529 + 0.98560028*d)
+	q := fixAngle(280.459 + 0.98564736*d)
+	l := fixAngle(q + 1.915*math.Sin(degreeToRadian(g)) + 0.020*math.Sin(degreeToRadian(2*g)))
+	e := 23.439 - 0.00000036*d
+
+	rightAscension := radianToDegree(math.Atan2(math.Cos(degreeToRadian(e))*math.Sin(degreeToRadian(l)), math.Cos(degreeToRadian(l)))) / 15
+	equationOfTime := q/15 - fixHour(rightAscension)
+	declination := radianToDegree(math.Asin(math.Sin(degreeToRadian(e)) * math.Sin(degreeToRadian(l))))
+
+	return declination, equationOfTime
+}
+
+func midDay(julianDate, dayPortion float64) float64 {
+	_, equationOfTime := sunPosition(julianDate + dayPortion)
+	return fixHour(12 - equationOfTime)
+}
+
+// sunAngleTime returns the hour at which the sun reaches the given angle below
+// the horizon, either before (beforeNoon) or after solar noon.
+func sunAngleTime(julianDate, latitude, angle, dayPortion float64, beforeNoon bool) float64 {
+	declination, _ := sunPosition(julianDate + dayPortion)
+	noon := midDay(julianDate, dayPortion)
+
+	cosHourAngle := (-math.Sin(degreeToRadian(angle)) - math.Sin(degreeToRadian(declination))*math.Sin(degreeToRadian(latitude))) /
+		(math.Cos(degreeToRadian(declination)) * math.Cos(degreeToRadian(latitude)))
+	hourAngle := radianToDegree(math.Acos(cosHourAngle)) / 15
+
+	if beforeNoon {
+		return noon - hourAngle
+	}
+	return noon + hourAngle
+}
+
+func asarTime(julianDate, latitude, shadowFactor, dayPortion float64) float64 {
+	declination, _ := sunPosition(julianDate + dayPortion)
+	angle := -radianToDegree(math.Atan(1 / (shadowFactor + math.Tan(degreeToRadian(math.Abs(latitude-declination))))))
+	return sunAngleTime(julianDate, latitude, angle, dayPortion, false)
+}
@@ -0,0 +1,1093 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/rrule"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+type TaskServicer interface {
+	ResolveTaskSchedules(ctx context.Context, arg ResolveTaskSchedulesParams) (TaskSchedulesResult, error)
+	ValidateRecurrence(ruleString, startsOnString string) (rrule.Rule, time.Time, error)
+	ExpandTaskOccurrences(ctx context.Context, arg ExpandTaskOccurrencesParams) ([]TaskOccurrence, error)
+	UpdateTaskOccurrence(ctx context.Context, arg UpdateTaskOccurrenceParams) (TaskOccurrence, error)
+	ResolveTaskProgress(ctx context.Context, tasks []repository.Task) (map[pgtype.UUID]TaskProgress, error)
+	ResolveTaskLabels(ctx context.Context, tasks []repository.Task) (map[pgtype.UUID][]repository.Label, error)
+	ParseListTasksQuery(query url.Values) (ListTasksParams, error)
+	ListTasks(ctx context.Context, arg ListTasksParams) (ListTasksResult, error)
+	CreateTask(ctx context.Context, arg CreateTaskParams) (repository.Task, error)
+	UpdateTask(ctx context.Context, arg UpdateTaskParams) (repository.Task, error)
+	RestoreTask(ctx context.Context, arg RestoreTaskParams) (repository.Task, error)
+	MoveTask(ctx context.Context, arg MoveTaskParams) (repository.Task, error)
+	PurgeTrashedTasks(ctx context.Context, now time.Time) (int64, error)
+	BulkTasks(ctx context.Context, arg BulkTasksParams) ([]repository.Task, error)
+	GetTaskStats(ctx context.Context, arg GetTaskStatsParams) (TaskStats, error)
+}
+
+var (
+	ErrTaskNotRecurring  = errors.New("task is not recurring")
+	ErrNotTaskOccurrence = errors.New("date is not an occurrence of the task")
+	ErrInvalidTaskMove   = errors.New("task can't be moved relative to itself")
+	ErrInvalidStatsRange = errors.New("stats range must start before it ends and span at most a year")
+)
+
+type task struct {
+	configs configs.Configs
+}
+
+func NewTaskService(configs configs.Configs) TaskServicer {
+	return &task{
+		configs: configs,
+	}
+}
+
+type anchorRelation string
+
+const (
+	anchorBefore  anchorRelation = "before"
+	anchorAfter   anchorRelation = "after"
+	anchorBetween anchorRelation = "between"
+)
+
+// PrayerSlot is the window of a prayer, starting at its own time and ending at
+// the next prayer. The isya slot ends at subuh of the following day.
+type PrayerSlot struct {
+	Name     string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// TaskSchedule is the resolved time of a prayer-anchored task on a specific
+// day. EndsAt is only set for tasks anchored between two prayers.
+type TaskSchedule struct {
+	Slot     string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+type ResolveTaskSchedulesParams struct {
+	UserUUID pgtype.UUID
+	// Date is the day to resolve anchors against. Only its year, month, and day
+	// are used; the zero value means today in the user's timezone.
+	Date  time.Time
+	Tasks []repository.Task
+}
+
+type TaskSchedulesResult struct {
+	PrayerSlots   []PrayerSlot
+	TaskSchedules map[pgtype.UUID]TaskSchedule
+}
+
+func (t task) ResolveTaskSchedules(ctx context.Context, arg ResolveTaskSchedulesParams) (TaskSchedulesResult, error) {
+	user, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return t.configs.Db.Queries.SelectUser(ctx, arg.UserUUID)
+	})
+
+	if err != nil {
+		return TaskSchedulesResult{}, fmt.Errorf("failed to select user: %w", err)
+	}
+
+	date := arg.Date
+	if date.IsZero() {
+		location, err := time.LoadLocation(user.Timezone)
+		if err != nil {
+			return TaskSchedulesResult{}, fmt.Errorf("failed to load timezone location: %w", err)
+		}
+		date = time.Now().In(location)
+	}
+
+	return resolveTaskSchedules(user, date, arg.Tasks)
+}
+
+func resolveTaskSchedules(user repository.SelectUserRow, date time.Time, tasks []repository.Task) (TaskSchedulesResult, error) {
+	scheduleParams := CalculatePrayerScheduleParams{
+		Date:      date,
+		Latitude:  user.Coordinates.P.Y,
+		Longitude: user.Coordinates.P.X,
+		Timezone:  user.Timezone,
+	}
+
+	schedule, err := calculatePrayerSchedule(scheduleParams)
+	if err != nil {
+		return TaskSchedulesResult{}, fmt.Errorf("failed to calculate prayer schedule: %w", err)
+	}
+
+	scheduleParams.Date = date.AddDate(0, 0, 1)
+	nextSchedule, err := calculatePrayerSchedule(scheduleParams)
+	if err != nil {
+		return TaskSchedulesResult{}, fmt.Errorf("failed to calculate next day prayer schedule: %w", err)
+	}
+
+	result := TaskSchedulesResult{
+		PrayerSlots:   make([]PrayerSlot, 0, len(schedule.Times)),
+		TaskSchedules: make(map[pgtype.UUID]TaskSchedule, len(tasks)),
+	}
+
+	for i, prayerTime := range schedule.Times {
+		endsAt := nextSchedule.Times[0].Time
+		if i+1 < len(schedule.Times) {
+			endsAt = schedule.Times[i+1].Time
+		}
+
+		result.PrayerSlots = append(result.PrayerSlots, PrayerSlot{
+			Name:     prayerTime.Name,
+			StartsAt: prayerTime.Time,
+			EndsAt:   endsAt,
+		})
+	}
+
+	for _, task := range tasks {
+		if !task.AnchorPrayer.Valid {
+			continue
+		}
+
+		taskSchedule, err := resolveTaskAnchor(schedule, result.PrayerSlots, task)
+		if err != nil {
+			return TaskSchedulesResult{}, fmt.Errorf("failed to resolve anchor of task %s: %w", task.ID.String(), err)
+		}
+		result.TaskSchedules[task.ID] = taskSchedule
+	}
+
+	return result, nil
+}
+
+func resolveTaskAnchor(schedule PrayerSchedule, slots []PrayerSlot, task repository.Task) (TaskSchedule, error) {
+	var slot PrayerSlot
+	for _, prayerSlot := range slots {
+		if prayerSlot.Name == task.AnchorPrayer.String {
+			slot = prayerSlot
+			break
+		}
+	}
+
+	if slot.Name == "" {
+		return TaskSchedule{}, fmt.Errorf("unknown anchor prayer: %s", task.AnchorPrayer.String)
+	}
+
+	offset := time.Duration(task.AnchorOffsetInMinutes) * time.Minute
+	var taskSchedule TaskSchedule
+
+	switch anchorRelation(task.AnchorRelation.String) {
+	case anchorBefore:
+		taskSchedule.StartsAt = slot.StartsAt.Add(-offset)
+	case anchorAfter:
+		taskSchedule.StartsAt = slot.StartsAt.Add(offset)
+	case anchorBetween:
+		taskSchedule.StartsAt = slot.StartsAt
+		taskSchedule.EndsAt = slot.EndsAt
+	default:
+		return TaskSchedule{}, fmt.Errorf("unknown anchor relation: %s", task.AnchorRelation.String)
+	}
+
+	taskSchedule.Slot = schedule.Slot(taskSchedule.StartsAt)
+	return taskSchedule, nil
+}
+
+func (t task) ValidateRecurrence(ruleString, startsOnString string) (rrule.Rule, time.Time, error) {
+	rule, err := rrule.Parse(ruleString)
+	if err != nil {
+		return rrule.Rule{}, time.Time{}, fmt.Errorf("failed to parse recurrence rule: %w", err)
+	}
+
+	startsOn, err := time.Parse(time.DateOnly, startsOnString)
+	if err != nil {
+		return rrule.Rule{}, time.Time{}, fmt.Errorf("failed to parse recurrence start: %w", err)
+	}
+
+	return rule, startsOn, nil
+}
+
+// TaskOccurrence is a single day of a recurring task. Name, description, and
+// completion state fall back to the task unless the occurrence overrides them.
+type TaskOccurrence struct {
+	TaskID      pgtype.UUID
+	Date        time.Time
+	Name        string
+	Description string
+	Checked     bool
+	Skipped     bool
+	Schedule    TaskSchedule
+}
+
+func newTaskOccurrence(task repository.Task, date time.Time, override repository.TaskOccurrence) TaskOccurrence {
+	occurrence := TaskOccurrence{
+		TaskID:      task.ID,
+		Date:        date,
+		Name:        task.Name,
+		Description: task.Description,
+		Checked:     override.Checked,
+		Skipped:     override.Skipped,
+	}
+
+	if override.Name.Valid {
+		occurrence.Name = override.Name.String
+	}
+
+	if override.Description.Valid {
+		occurrence.Description = override.Description.String
+	}
+
+	return occurrence
+}
+
+func parseTaskRecurrence(task repository.Task) (rrule.Rule, error) {
+	if !task.RecurrenceRule.Valid || !task.RecurrenceStart.Valid {
+		return rrule.Rule{}, ErrTaskNotRecurring
+	}
+
+	rule, err := rrule.Parse(task.RecurrenceRule.String)
+	if err != nil {
+		return rrule.Rule{}, fmt.Errorf("failed to parse recurrence rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// resolveOccurrenceSchedules resolves the prayer anchor of the task for every
+// occurrence, since prayer times shift from one day to the next.
+func (t task) resolveOccurrenceSchedules(ctx context.Context, task repository.Task, occurrences []TaskOccurrence) error {
+	if !task.AnchorPrayer.Valid || len(occurrences) == 0 {
+		return nil
+	}
+
+	user, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return t.configs.Db.Queries.SelectUser(ctx, task.UserID)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to select user: %w", err)
+	}
+
+	for i := range occurrences {
+		result, err := resolveTaskSchedules(user, occurrences[i].Date, []repository.Task{task})
+		if err != nil {
+			return err
+		}
+		occurrences[i].Schedule = result.TaskSchedules[task.ID]
+	}
+
+	return nil
+}
+
+type ExpandTaskOccurrencesParams struct {
+	UserUUID pgtype.UUID
+	TaskUUID pgtype.UUID
+	From     time.Time
+	To       time.Time
+}
+
+func (t task) ExpandTaskOccurrences(ctx context.Context, arg ExpandTaskOccurrencesParams) ([]TaskOccurrence, error) {
+	task, err := retryutil.RetryWithData(func() (repository.Task, error) {
+		return t.configs.Db.Queries.SelectUserTask(ctx, repository.SelectUserTaskParams{
+			ID:     arg.TaskUUID,
+			UserID: arg.UserUUID,
+		})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select user task: %w", err)
+	}
+
+	rule, err := parseTaskRecurrence(task)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := retryutil.RetryWithData(func() ([]repository.TaskOccurrence, error) {
+		return t.configs.Db.Queries.SelectTaskOccurrences(ctx, repository.SelectTaskOccurrencesParams{
+			TaskID:   task.ID,
+			FromDate: pgtype.Date{Time: arg.From, Valid: true},
+			ToDate:   pgtype.Date{Time: arg.To, Valid: true},
+		})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select task occurrences: %w", err)
+	}
+
+	overridesByDate := make(map[time.Time]repository.TaskOccurrence, len(overrides))
+	for _, override := range overrides {
+		overridesByDate[override.OccurrenceDate.Time] = override
+	}
+
+	dates := rule.Between(task.RecurrenceStart.Time, arg.From, arg.To)
+	occurrences := make([]TaskOccurrence, 0, len(dates))
+	for _, date := range dates {
+		occurrences = append(occurrences, newTaskOccurrence(task, date, overridesByDate[date]))
+	}
+
+	if err := t.resolveOccurrenceSchedules(ctx, task, occurrences); err != nil {
+		return nil, err
+	}
+
+	return occurrences, nil
+}
+
+type UpdateTaskOccurrenceParams struct {
+	UserUUID    pgtype.UUID
+	TaskUUID    pgtype.UUID
+	Date        time.Time
+	Name        pgtype.Text
+	Description pgtype.Text
+	Checked     pgtype.Bool
+	Skipped     pgtype.Bool
+}
+
+func (t task) UpdateTaskOccurrence(ctx context.Context, arg UpdateTaskOccurrenceParams) (TaskOccurrence, error) {
+	var task repository.Task
+	retryableFunc := func(qtx *repository.Queries) (TaskOccurrence, error) {
+		var err error
+		task, err = qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+			ID:     arg.TaskUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return TaskOccurrence{}, fmt.Errorf("failed to select user task: %w", err)
+		}
+
+		rule, err := parseTaskRecurrence(task)
+		if err != nil {
+			return TaskOccurrence{}, err
+		}
+
+		if !rule.Occurs(task.RecurrenceStart.Time, arg.Date) {
+			return TaskOccurrence{}, ErrNotTaskOccurrence
+		}
+
+		override, err := qtx.UpsertTaskOccurrence(ctx, repository.UpsertTaskOccurrenceParams{
+			TaskID:         task.ID,
+			OccurrenceDate: pgtype.Date{Time: arg.Date, Valid: true},
+			Name:           arg.Name,
+			Description:    arg.Description,
+			Checked:        arg.Checked,
+			Skipped:        arg.Skipped,
+		})
+
+		if err != nil {
+			return TaskOccurrence{}, fmt.Errorf("failed to upsert task occurrence: %w", err)
+		}
+
+		return newTaskOccurrence(task, override.OccurrenceDate.Time, override), nil
+	}
+
+	occurrence, err := dbutil.RetryableTxWithData(ctx, t.configs.Db.Conn, t.configs.Db.Queries, retryableFunc)
+	if err != nil {
+		return TaskOccurrence{}, err
+	}
+
+	occurrences := []TaskOccurrence{occurrence}
+	if err := t.resolveOccurrenceSchedules(ctx, task, occurrences); err != nil {
+		return TaskOccurrence{}, err
+	}
+
+	return occurrences[0], nil
+}
+
+// TaskProgress is derived from the checklist items of a task. Tasks without
+// items have no progress entry.
+type TaskProgress struct {
+	Total   int64
+	Checked int64
+}
+
+func (t task) ResolveTaskProgress(ctx context.Context, tasks []repository.Task) (map[pgtype.UUID]TaskProgress, error) {
+	taskIDs := make([]pgtype.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	rows, err := retryutil.RetryWithData(func() ([]repository.SelectTasksItemProgressRow, error) {
+		return t.configs.Db.Queries.SelectTasksItemProgress(ctx, taskIDs)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks item progress: %w", err)
+	}
+
+	progresses := make(map[pgtype.UUID]TaskProgress, len(rows))
+	for _, row := range rows {
+		progresses[row.TaskID] = TaskProgress{Total: row.Total, Checked: row.Checked}
+	}
+
+	return progresses, nil
+}
+
+func (t task) ResolveTaskLabels(ctx context.Context, tasks []repository.Task) (map[pgtype.UUID][]repository.Label, error) {
+	taskIDs := make([]pgtype.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	rows, err := retryutil.RetryWithData(func() ([]repository.SelectTasksLabelsRow, error) {
+		return t.configs.Db.Queries.SelectTasksLabels(ctx, taskIDs)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks labels: %w", err)
+	}
+
+	labels := make(map[pgtype.UUID][]repository.Label, len(tasks))
+	for _, row := range rows {
+		labels[row.TaskID] = append(labels[row.TaskID], row.Label)
+	}
+
+	return labels, nil
+}
+
+const (
+	defaultTaskListLimit = 50
+	maxTaskListLimit     = 100
+)
+
+var taskSortFields = []string{"position", "created_at", "due_at", "priority"}
+
+type ListTasksParams struct {
+	UserUUID   pgtype.UUID
+	LabelUUID  pgtype.UUID
+	Checked    pgtype.Bool
+	DueBefore  pgtype.Timestamptz
+	DueAfter   pgtype.Timestamptz
+	Priority   pgtype.Int2
+	SortBy     string
+	Descending bool
+	Limit      int
+	Cursor     string
+}
+
+// ParseListTasksQuery validates the filter, sort, and pagination query params
+// of the task list. The returned params have no user set.
+func (t task) ParseListTasksQuery(query url.Values) (ListTasksParams, error) {
+	arg := ListTasksParams{
+		SortBy: "position",
+		Limit:  defaultTaskListLimit,
+		Cursor: query.Get("cursor"),
+	}
+
+	if label := query.Get("label"); label != "" {
+		labelUUID, err := uuid.Parse(label)
+		if err != nil {
+			return ListTasksParams{}, fmt.Errorf("failed to parse label to UUID: %w", err)
+		}
+		arg.LabelUUID = pgtype.UUID{Bytes: labelUUID, Valid: true}
+	}
+
+	if checkedString := query.Get("checked"); checkedString != "" {
+		checked, err := strconv.ParseBool(checkedString)
+		if err != nil {
+			return ListTasksParams{}, fmt.Errorf("failed to convert checked string to bool: %w", err)
+		}
+		arg.Checked = pgtype.Bool{Bool: checked, Valid: true}
+	}
+
+	if dueBefore := query.Get("due_before"); dueBefore != "" {
+		dueBeforeTime, err := time.Parse(time.RFC3339, dueBefore)
+		if err != nil {
+			return ListTasksParams{}, fmt.Errorf("failed to parse due_before: %w", err)
+		}
+		arg.DueBefore = pgtype.Timestamptz{Time: dueBeforeTime, Valid: true}
+	}
+
+	if dueAfter := query.Get("due_after"); dueAfter != "" {
+		dueAfterTime, err := time.Parse(time.RFC3339, dueAfter)
+		if err != nil {
+			return ListTasksParams{}, fmt.Errorf("failed to parse due_after: %w", err)
+		}
+		arg.DueAfter = pgtype.Timestamptz{Time: dueAfterTime, Valid: true}
+	}
+
+	if priorityString := query.Get("priority"); priorityString != "" {
+		priority, err := strconv.Atoi(priorityString)
+		if err != nil || priority < 0 || priority > 3 {
+			return ListTasksParams{}, fmt.Errorf("invalid priority: %s", priorityString)
+		}
+		arg.Priority = pgtype.Int2{Int16: int16(priority), Valid: true}
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		arg.Descending = strings.HasPrefix(sort, "-")
+		arg.SortBy = strings.TrimPrefix(sort, "-")
+		if !slices.Contains(taskSortFields, arg.SortBy) {
+			return ListTasksParams{}, fmt.Errorf("invalid sort: %s", sort)
+		}
+	}
+
+	if limitString := query.Get("limit"); limitString != "" {
+		limit, err := strconv.Atoi(limitString)
+		if err != nil || limit < 1 || limit > maxTaskListLimit {
+			return ListTasksParams{}, fmt.Errorf("invalid limit: %s", limitString)
+		}
+		arg.Limit = limit
+	}
+
+	if arg.Cursor != "" {
+		if _, _, err := decodeTaskCursor(arg.Cursor); err != nil {
+			return ListTasksParams{}, err
+		}
+	}
+
+	return arg, nil
+}
+
+// A task cursor points at the last task of a page by its sort key and id, so
+// the next page starts right after it regardless of inserts and deletes.
+func encodeTaskCursor(sortKey float64, taskID pgtype.UUID) string {
+	raw := strconv.FormatFloat(sortKey, 'g', -1, 64) + "," + taskID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTaskCursor(cursor string) (float64, pgtype.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, pgtype.UUID{}, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+
+	sortKeyString, taskId, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return 0, pgtype.UUID{}, errors.New("malformed cursor")
+	}
+
+	sortKey, err := strconv.ParseFloat(sortKeyString, 64)
+	if err != nil {
+		return 0, pgtype.UUID{}, fmt.Errorf("failed to parse cursor sort key: %w", err)
+	}
+
+	taskUUID, err := uuid.Parse(taskId)
+	if err != nil {
+		return 0, pgtype.UUID{}, fmt.Errorf("failed to parse cursor task Id to UUID: %w", err)
+	}
+
+	return sortKey, pgtype.UUID{Bytes: taskUUID, Valid: true}, nil
+}
+
+type ListTasksResult struct {
+	Tasks []repository.Task
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+func (t task) ListTasks(ctx context.Context, arg ListTasksParams) (ListTasksResult, error) {
+	params := repository.SelectUserTasksParams{
+		UserID:     arg.UserUUID,
+		SortBy:     arg.SortBy,
+		LabelID:    arg.LabelUUID,
+		Checked:    arg.Checked,
+		DueBefore:  arg.DueBefore,
+		DueAfter:   arg.DueAfter,
+		Priority:   arg.Priority,
+		Descending: arg.Descending,
+		// One extra row tells whether there is a next page.
+		RowLimit: int32(arg.Limit + 1),
+	}
+
+	if arg.Cursor != "" {
+		sortKey, taskUUID, err := decodeTaskCursor(arg.Cursor)
+		if err != nil {
+			return ListTasksResult{}, err
+		}
+		params.CursorSortKey = pgtype.Float8{Float64: sortKey, Valid: true}
+		params.CursorID = taskUUID
+	}
+
+	rows, err := retryutil.RetryWithData(func() ([]repository.SelectUserTasksRow, error) {
+		return t.configs.Db.Queries.SelectUserTasks(ctx, params)
+	})
+
+	if err != nil {
+		return ListTasksResult{}, fmt.Errorf("failed to select user tasks: %w", err)
+	}
+
+	var result ListTasksResult
+	if len(rows) > arg.Limit {
+		rows = rows[:arg.Limit]
+		lastRow := rows[len(rows)-1]
+		result.NextCursor = encodeTaskCursor(lastRow.SortKey, lastRow.Task.ID)
+	}
+
+	result.Tasks = make([]repository.Task, 0, len(rows))
+	for _, row := range rows {
+		result.Tasks = append(result.Tasks, row.Task)
+	}
+
+	return result, nil
+}
+
+type CreateTaskParams struct {
+	Insert repository.InsertUserTaskParams
+	// Entitlements limit how many active tasks the user can have.
+	Entitlements Entitlements
+}
+
+func (t task) CreateTask(ctx context.Context, arg CreateTaskParams) (repository.Task, error) {
+	retryableFunc := func(qtx *repository.Queries) (repository.Task, error) {
+		return limitActiveTasks(ctx, qtx, arg.Entitlements, arg.Insert.UserID, func() (repository.Task, error) {
+			task, err := qtx.InsertUserTask(ctx, arg.Insert)
+			if err != nil {
+				return repository.Task{}, fmt.Errorf("failed to insert user task: %w", err)
+			}
+			return task, nil
+		})
+	}
+
+	return dbutil.RetryableTxWithData(ctx, t.configs.Db.Conn, t.configs.Db.Queries, retryableFunc)
+}
+
+type UpdateTaskParams struct {
+	Update repository.UpdateUserTaskParams
+	// Entitlements limit how many active tasks the user can have, which
+	// unchecking a task adds to.
+	Entitlements Entitlements
+}
+
+func (t task) UpdateTask(ctx context.Context, arg UpdateTaskParams) (repository.Task, error) {
+	retryableFunc := func(qtx *repository.Queries) (repository.Task, error) {
+		updateTask := func() (repository.Task, error) {
+			task, err := qtx.UpdateUserTask(ctx, arg.Update)
+			if err != nil {
+				return repository.Task{}, fmt.Errorf("failed to update user task: %w", err)
+			}
+			return task, nil
+		}
+
+		if arg.Update.Checked.Valid && !arg.Update.Checked.Bool {
+			return limitActiveTasks(ctx, qtx, arg.Entitlements, arg.Update.UserID, updateTask)
+		}
+		return updateTask()
+	}
+
+	return dbutil.RetryableTxWithData(ctx, t.configs.Db.Conn, t.configs.Db.Queries, retryableFunc)
+}
+
+type RestoreTaskParams struct {
+	UserUUID pgtype.UUID
+	TaskUUID pgtype.UUID
+	// Entitlements limit how many active tasks the user can have, which
+	// restoring an unchecked task adds to.
+	Entitlements Entitlements
+}
+
+func (t task) RestoreTask(ctx context.Context, arg RestoreTaskParams) (repository.Task, error) {
+	retryableFunc := func(qtx *repository.Queries) (repository.Task, error) {
+		return limitActiveTasks(ctx, qtx, arg.Entitlements, arg.UserUUID, func() (repository.Task, error) {
+			task, err := qtx.RestoreUserTask(ctx, repository.RestoreUserTaskParams{
+				ID:     arg.TaskUUID,
+				UserID: arg.UserUUID,
+			})
+
+			if err != nil {
+				return repository.Task{}, fmt.Errorf("failed to restore user task: %w", err)
+			}
+			return task, nil
+		})
+	}
+
+	return dbutil.RetryableTxWithData(ctx, t.configs.Db.Conn, t.configs.Db.Queries, retryableFunc)
+}
+
+// taskPositionGap is the distance between neighbouring tasks after an insert or
+// a rebalance, matching InsertUserTask and RebalanceUserTaskPositions. Moving a
+// task takes the midpoint of its new neighbours, so only the moved task is
+// written until a gap runs out.
+const taskPositionGap = 1024
+
+type MoveTaskParams struct {
+	UserUUID pgtype.UUID
+	TaskUUID pgtype.UUID
+	// TargetUUID is the task to move next to, before it when Before is set
+	// and after it otherwise.
+	TargetUUID pgtype.UUID
+	Before     bool
+}
+
+func (t task) MoveTask(ctx context.Context, arg MoveTaskParams) (repository.Task, error) {
+	if arg.TaskUUID == arg.TargetUUID {
+		return repository.Task{}, ErrInvalidTaskMove
+	}
+
+	retryableFunc := func(qtx *repository.Queries) (repository.Task, error) {
+		_, err := qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+			ID:     arg.TaskUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return repository.Task{}, fmt.Errorf("failed to select user task: %w", err)
+		}
+
+		position, ok, err := resolveMovedTaskPosition(ctx, qtx, arg)
+		if err != nil {
+			return repository.Task{}, err
+		}
+
+		if !ok {
+			if err := qtx.RebalanceUserTaskPositions(ctx, arg.UserUUID); err != nil {
+				return repository.Task{}, fmt.Errorf("failed to rebalance user task positions: %w", err)
+			}
+
+			// Every neighbour is taskPositionGap apart after a rebalance, so
+			// there is always room for the midpoint.
+			position, _, err = resolveMovedTaskPosition(ctx, qtx, arg)
+			if err != nil {
+				return repository.Task{}, err
+			}
+		}
+
+		task, err := qtx.UpdateUserTaskPosition(ctx, repository.UpdateUserTaskPositionParams{
+			ID:       arg.TaskUUID,
+			UserID:   arg.UserUUID,
+			Position: position,
+		})
+
+		if err != nil {
+			return repository.Task{}, fmt.Errorf("failed to update user task position: %w", err)
+		}
+
+		return task, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, t.configs.Db.Conn, t.configs.Db.Queries, retryableFunc)
+}
+
+// resolveMovedTaskPosition returns the midpoint between the target and its
+// neighbour on the side the task moves to. It reports false when the two are
+// adjacent and the positions have to be rebalanced first.
+func resolveMovedTaskPosition(ctx context.Context, qtx *repository.Queries, arg MoveTaskParams) (int64, bool, error) {
+	target, err := qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+		ID:     arg.TargetUUID,
+		UserID: arg.UserUUID,
+	})
+
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to select target user task: %w", err)
+	}
+
+	var neighbour int64
+	if arg.Before {
+		neighbour, err = qtx.SelectPreviousTaskPosition(ctx, repository.SelectPreviousTaskPositionParams{
+			UserID:     arg.UserUUID,
+			ExcludedID: arg.TaskUUID,
+			Position:   target.Position,
+		})
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return target.Position - taskPositionGap, true, nil
+		}
+	} else {
+		neighbour, err = qtx.SelectNextTaskPosition(ctx, repository.SelectNextTaskPositionParams{
+			UserID:     arg.UserUUID,
+			ExcludedID: arg.TaskUUID,
+			Position:   target.Position,
+		})
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return target.Position + taskPositionGap, true, nil
+		}
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to select neighbour task position: %w", err)
+	}
+
+	if neighbour-target.Position < 2 && target.Position-neighbour < 2 {
+		return 0, false, nil
+	}
+
+	return (neighbour + target.Position) / 2, true, nil
+}
+
+// PurgeTrashedTasks permanently deletes tasks that have been in the trash for
+// longer than the configured retention.
+func (t task) PurgeTrashedTasks(ctx context.Context, now time.Time) (int64, error) {
+	deletedBefore := now.AddDate(0, 0, -t.configs.Env.TaskTrashRetentionDays)
+	purgedTasks, err := retryutil.RetryWithData(func() (int64, error) {
+		return t.configs.Db.Queries.PurgeTrashedTasks(ctx, pgtype.Timestamptz{Time: deletedBefore, Valid: true})
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trashed tasks: %w", err)
+	}
+
+	return purgedTasks, nil
+}
+
+const (
+	BulkTaskCreate = "create"
+	BulkTaskUpdate = "update"
+	BulkTaskDelete = "delete"
+)
+
+// BulkTaskOperation is a single operation of BulkTasks. Kind decides which of
+// Insert, Update or TaskUUID is used. The user Id of Insert and Update is
+// always overwritten with the user of the bulk request.
+type BulkTaskOperation struct {
+	Kind     string
+	Insert   repository.InsertUserTaskParams
+	Update   repository.UpdateUserTaskParams
+	TaskUUID pgtype.UUID
+}
+
+type BulkTasksParams struct {
+	UserUUID   pgtype.UUID
+	Operations []BulkTaskOperation
+	// Entitlements limit how many active tasks the user can have once every
+	// operation is applied.
+	Entitlements Entitlements
+}
+
+// BulkTaskError reports the operation that made the whole bulk request roll
+// back.
+type BulkTaskError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkTaskError) Error() string {
+	return fmt.Sprintf("bulk task operation %d failed: %v", e.Index, e.Err)
+}
+
+func (e *BulkTaskError) Unwrap() error {
+	return e.Err
+}
+
+// BulkTasks executes every operation in a single transaction, so either all
+// of them are applied or none is. It returns the resulting task of each
+// operation in order, with the zero value for deletes.
+func (t task) BulkTasks(ctx context.Context, arg BulkTasksParams) ([]repository.Task, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]repository.Task, error) {
+		return limitActiveTasks(ctx, qtx, arg.Entitlements, arg.UserUUID, func() ([]repository.Task, error) {
+			tasks := make([]repository.Task, 0, len(arg.Operations))
+			for i, operation := range arg.Operations {
+				task, err := executeBulkTaskOperation(ctx, qtx, arg.UserUUID, operation)
+				if err != nil {
+					return nil, &BulkTaskError{Index: i, Err: err}
+				}
+				tasks = append(tasks, task)
+			}
+
+			return tasks, nil
+		})
+	}
+
+	return dbutil.RetryableTxWithData(ctx, t.configs.Db.Conn, t.configs.Db.Queries, retryableFunc)
+}
+
+func executeBulkTaskOperation(
+	ctx context.Context,
+	qtx *repository.Queries,
+	userUUID pgtype.UUID,
+	operation BulkTaskOperation,
+) (repository.Task, error) {
+	switch operation.Kind {
+	case BulkTaskCreate:
+		operation.Insert.UserID = userUUID
+		task, err := qtx.InsertUserTask(ctx, operation.Insert)
+		if err != nil {
+			return repository.Task{}, fmt.Errorf("failed to insert user task: %w", err)
+		}
+		return task, nil
+	case BulkTaskUpdate:
+		operation.Update.UserID = userUUID
+		task, err := qtx.UpdateUserTask(ctx, operation.Update)
+		if err != nil {
+			return repository.Task{}, fmt.Errorf("failed to update user task: %w", err)
+		}
+		return task, nil
+	case BulkTaskDelete:
+		affectedRows, err := qtx.DeleteUserTask(ctx, repository.DeleteUserTaskParams{
+			ID:     operation.TaskUUID,
+			UserID: userUUID,
+		})
+
+		if err != nil {
+			return repository.Task{}, fmt.Errorf("failed to delete user task: %w", err)
+		}
+
+		if affectedRows == 0 {
+			return repository.Task{}, fmt.Errorf("failed to delete user task: %w", pgx.ErrNoRows)
+		}
+		return repository.Task{}, nil
+	default:
+		return repository.Task{}, fmt.Errorf("unknown bulk task operation: %s", operation.Kind)
+	}
+}
+
+const (
+	defaultTaskStatsDays = 28
+	maxTaskStatsDays     = 366
+)
+
+type GetTaskStatsParams struct {
+	UserUUID pgtype.UUID
+	// From and To are inclusive dates in the user's timezone. To defaults to
+	// today and From to four weeks before it.
+	From time.Time
+	To   time.Time
+	// Entitlements limit how long and how far back the range can be.
+	Entitlements Entitlements
+}
+
+type TaskStatsPeriod struct {
+	StartsOn  time.Time
+	Completed int64
+}
+
+type TaskStatsPrayerSlot struct {
+	Name      string
+	Completed int64
+}
+
+type TaskStats struct {
+	From time.Time
+	To   time.Time
+	// Days has every day of the range and Weeks every week overlapping it,
+	// starting on Monday, including the ones without completed tasks.
+	Days           []TaskStatsPeriod
+	Weeks          []TaskStatsPeriod
+	Completed      int64
+	OnTime         int64
+	Overdue        int64
+	WithoutDueDate int64
+	// AverageCompletionLatency is the average time from creating a task to
+	// completing it.
+	AverageCompletionLatency time.Duration
+	// PrayerSlots counts completions by the prayer slot they happened in,
+	// ordered from subuh to isya.
+	PrayerSlots []TaskStatsPrayerSlot
+}
+
+// GetTaskStats summarizes the tasks the user completed within the range. Only
+// tasks completed since completion times are tracked are counted, and
+// occurrences of recurring tasks aren't.
+func (t task) GetTaskStats(ctx context.Context, arg GetTaskStatsParams) (TaskStats, error) {
+	user, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return t.configs.Db.Queries.SelectUser(ctx, arg.UserUUID)
+	})
+
+	if err != nil {
+		return TaskStats{}, fmt.Errorf("failed to select user: %w", err)
+	}
+
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return TaskStats{}, fmt.Errorf("failed to load timezone location: %w", err)
+	}
+
+	year, month, day := time.Now().In(location).Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+	to := today
+	if !arg.To.IsZero() {
+		year, month, day := arg.To.Date()
+		to = time.Date(year, month, day, 0, 0, 0, 0, location)
+	}
+
+	maxDays := arg.Entitlements.maxStatsDays()
+	from := to.AddDate(0, 0, -(min(defaultTaskStatsDays, maxDays) - 1))
+	if !arg.From.IsZero() {
+		year, month, day := arg.From.Date()
+		from = time.Date(year, month, day, 0, 0, 0, 0, location)
+	}
+
+	if from.After(to) || from.AddDate(0, 0, maxTaskStatsDays-1).Before(to) {
+		return TaskStats{}, ErrInvalidStatsRange
+	}
+
+	if from.AddDate(0, 0, maxDays-1).Before(to) {
+		return TaskStats{}, arg.Entitlements.missing(EntitlementExtendedStats, int64(maxDays))
+	}
+
+	if err := arg.Entitlements.checkHistory(from, today); err != nil {
+		return TaskStats{}, err
+	}
+
+	completedTasks, err := retryutil.RetryWithData(func() ([]repository.SelectUserCompletedTasksRow, error) {
+		return t.configs.Db.Queries.SelectUserCompletedTasks(ctx, repository.SelectUserCompletedTasksParams{
+			UserID:         arg.UserUUID,
+			CompletedFrom:  pgtype.Timestamptz{Time: from, Valid: true},
+			CompletedUntil: pgtype.Timestamptz{Time: to.AddDate(0, 0, 1), Valid: true},
+		})
+	})
+
+	if err != nil {
+		return TaskStats{}, fmt.Errorf("failed to select user completed tasks: %w", err)
+	}
+
+	stats := TaskStats{From: from, To: to, PrayerSlots: make([]TaskStatsPrayerSlot, 0, len(prayerNames))}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		stats.Days = append(stats.Days, TaskStatsPeriod{StartsOn: date})
+	}
+
+	weekStartsOn := from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	for ; !weekStartsOn.After(to); weekStartsOn = weekStartsOn.AddDate(0, 0, 7) {
+		stats.Weeks = append(stats.Weeks, TaskStatsPeriod{StartsOn: weekStartsOn})
+	}
+
+	for _, name := range prayerNames {
+		stats.PrayerSlots = append(stats.PrayerSlots, TaskStatsPrayerSlot{Name: string(name)})
+	}
+
+	var totalLatency time.Duration
+	schedules := make(map[time.Time]PrayerSchedule)
+
+	for _, completedTask := range completedTasks {
+		completedAt := completedTask.CompletedAt.Time.In(location)
+		year, month, day := completedAt.Date()
+		date := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+		// Days are compared by their date, as a day isn't always 24 hours long.
+		dayIndex := int(date.Sub(from).Round(24*time.Hour) / (24 * time.Hour))
+		stats.Days[dayIndex].Completed++
+		stats.Weeks[(dayIndex+(int(from.Weekday())+6)%7)/7].Completed++
+		stats.Completed++
+
+		switch {
+		case !completedTask.DueAt.Valid:
+			stats.WithoutDueDate++
+		case completedAt.After(completedTask.DueAt.Time):
+			stats.Overdue++
+		default:
+			stats.OnTime++
+		}
+
+		totalLatency += completedAt.Sub(completedTask.CreatedAt.Time)
+
+		schedule, ok := schedules[date]
+		if !ok {
+			schedule, err = calculatePrayerSchedule(CalculatePrayerScheduleParams{
+				Date:      date,
+				Latitude:  user.Coordinates.P.Y,
+				Longitude: user.Coordinates.P.X,
+				Timezone:  user.Timezone,
+			})
+
+			if err != nil {
+				return TaskStats{}, fmt.Errorf("failed to calculate prayer schedule: %w", err)
+			}
+			schedules[date] = schedule
+		}
+
+		slotIndex := slices.Index(prayerNames, prayerName(schedule.Slot(completedAt)))
+		stats.PrayerSlots[slotIndex].Completed++
+	}
+
+	if stats.Completed > 0 {
+		stats.AverageCompletionLatency = totalLatency / time.Duration(stats.Completed)
+	}
+
+	return stats, nil
+}
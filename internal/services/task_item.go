@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+type TaskItemServicer interface {
+	GetTaskItems(ctx context.Context, arg GetTaskItemsParams) ([]repository.TaskItem, error)
+	AddTaskItem(ctx context.Context, arg AddTaskItemParams) (repository.TaskItem, error)
+	ReorderTaskItems(ctx context.Context, arg ReorderTaskItemsParams) ([]repository.TaskItem, error)
+}
+
+var ErrInvalidItemOrder = errors.New("item order must contain every item of the task exactly once")
+
+type taskItem struct {
+	configs configs.Configs
+}
+
+func NewTaskItemService(configs configs.Configs) TaskItemServicer {
+	return &taskItem{
+		configs: configs,
+	}
+}
+
+type GetTaskItemsParams struct {
+	UserUUID pgtype.UUID
+	TaskUUID pgtype.UUID
+}
+
+func (ti taskItem) GetTaskItems(ctx context.Context, arg GetTaskItemsParams) ([]repository.TaskItem, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]repository.TaskItem, error) {
+		_, err := qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+			ID:     arg.TaskUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to select user task: %w", err)
+		}
+
+		items, err := qtx.SelectTaskItems(ctx, arg.TaskUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select task items: %w", err)
+		}
+
+		return items, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, ti.configs.Db.Conn, ti.configs.Db.Queries, retryableFunc)
+}
+
+type AddTaskItemParams struct {
+	UserUUID pgtype.UUID
+	TaskUUID pgtype.UUID
+	Name     string
+}
+
+func (ti taskItem) AddTaskItem(ctx context.Context, arg AddTaskItemParams) (repository.TaskItem, error) {
+	itemUUID := uuid.New()
+	retryableFunc := func(qtx *repository.Queries) (repository.TaskItem, error) {
+		_, err := qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+			ID:     arg.TaskUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return repository.TaskItem{}, fmt.Errorf("failed to select user task: %w", err)
+		}
+
+		item, err := qtx.InsertTaskItem(ctx, repository.InsertTaskItemParams{
+			ID:     pgtype.UUID{Bytes: itemUUID, Valid: true},
+			TaskID: arg.TaskUUID,
+			Name:   arg.Name,
+		})
+
+		if err != nil {
+			return repository.TaskItem{}, fmt.Errorf("failed to insert task item: %w", err)
+		}
+
+		return item, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, ti.configs.Db.Conn, ti.configs.Db.Queries, retryableFunc)
+}
+
+type ReorderTaskItemsParams struct {
+	UserUUID  pgtype.UUID
+	TaskUUID  pgtype.UUID
+	ItemUUIDs []pgtype.UUID
+}
+
+func (ti taskItem) ReorderTaskItems(ctx context.Context, arg ReorderTaskItemsParams) ([]repository.TaskItem, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]repository.TaskItem, error) {
+		_, err := qtx.SelectUserTask(ctx, repository.SelectUserTaskParams{
+			ID:     arg.TaskUUID,
+			UserID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to select user task: %w", err)
+		}
+
+		items, err := qtx.SelectTaskItems(ctx, arg.TaskUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select task items: %w", err)
+		}
+
+		if len(items) != len(arg.ItemUUIDs) {
+			return nil, ErrInvalidItemOrder
+		}
+
+		remaining := make(map[pgtype.UUID]struct{}, len(items))
+		for _, item := range items {
+			remaining[item.ID] = struct{}{}
+		}
+
+		for _, itemUUID := range arg.ItemUUIDs {
+			if _, ok := remaining[itemUUID]; !ok {
+				return nil, ErrInvalidItemOrder
+			}
+			delete(remaining, itemUUID)
+		}
+
+		err = qtx.UpdateTaskItemPositions(ctx, repository.UpdateTaskItemPositionsParams{
+			TaskID:  arg.TaskUUID,
+			ItemIds: arg.ItemUUIDs,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to update task item positions: %w", err)
+		}
+
+		items, err = qtx.SelectTaskItems(ctx, arg.TaskUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select task items: %w", err)
+		}
+
+		return items, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, ti.configs.Db.Conn, ti.configs.Db.Queries, retryableFunc)
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+// TaskListServicer manages task lists shared between users. Every method
+// checks that the acting user is a member of the list, and the ones changing
+// the list itself or its members require the owner.
+type TaskListServicer interface {
+	GetTaskLists(ctx context.Context, userUUID pgtype.UUID) ([]repository.TaskList, error)
+	CreateTaskList(ctx context.Context, arg CreateTaskListParams) (repository.TaskList, error)
+	DeleteTaskList(ctx context.Context, arg TaskListParams) error
+	GetTaskListMembers(ctx context.Context, arg TaskListParams) ([]repository.SelectTaskListMembersRow, error)
+	InviteTaskListMember(ctx context.Context, arg InviteTaskListMemberParams) (repository.SelectTaskListMembersRow, error)
+	RemoveTaskListMember(ctx context.Context, arg RemoveTaskListMemberParams) error
+	GetTaskListTasks(ctx context.Context, arg TaskListParams) ([]repository.Task, error)
+	CreateTaskListTask(ctx context.Context, arg CreateTaskListTaskParams) (repository.Task, error)
+	UpdateTaskListTask(ctx context.Context, arg UpdateTaskListTaskParams) (repository.Task, error)
+	DeleteTaskListTask(ctx context.Context, arg DeleteTaskListTaskParams) error
+}
+
+var (
+	ErrTaskListForbidden  = errors.New("user isn't allowed to perform this action on the task list")
+	ErrAssigneeNotMember  = errors.New("assignee isn't a member of the task list")
+	ErrTaskListOwnerLeave = errors.New("owner can't leave the task list")
+)
+
+const (
+	TaskListOwner        = "owner"
+	TaskListCollaborator = "collaborator"
+)
+
+type taskList struct {
+	configs configs.Configs
+}
+
+func NewTaskListService(configs configs.Configs) TaskListServicer {
+	return &taskList{
+		configs: configs,
+	}
+}
+
+// selectTaskListMember returns pgx.ErrNoRows when the user isn't a member, so
+// lists of other users look the same as lists that don't exist.
+func selectTaskListMember(ctx context.Context, qtx *repository.Queries, listUUID, userUUID pgtype.UUID) (repository.TaskListMember, error) {
+	member, err := qtx.SelectTaskListMember(ctx, repository.SelectTaskListMemberParams{
+		TaskListID: listUUID,
+		UserID:     userUUID,
+	})
+
+	if err != nil {
+		return repository.TaskListMember{}, fmt.Errorf("failed to select task list member: %w", err)
+	}
+
+	return member, nil
+}
+
+// validateAssignee makes sure tasks are only assigned to members of the list.
+func validateAssignee(ctx context.Context, qtx *repository.Queries, listUUID, assigneeUUID pgtype.UUID) error {
+	if !assigneeUUID.Valid {
+		return nil
+	}
+
+	_, err := selectTaskListMember(ctx, qtx, listUUID, assigneeUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAssigneeNotMember
+	}
+
+	return err
+}
+
+func (tl taskList) GetTaskLists(ctx context.Context, userUUID pgtype.UUID) ([]repository.TaskList, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]repository.TaskList, error) {
+		lists, err := qtx.SelectUserTaskLists(ctx, userUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select user task lists: %w", err)
+		}
+
+		return lists, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type CreateTaskListParams struct {
+	UserUUID pgtype.UUID
+	Name     string
+}
+
+func (tl taskList) CreateTaskList(ctx context.Context, arg CreateTaskListParams) (repository.TaskList, error) {
+	listUUID := uuid.New()
+	retryableFunc := func(qtx *repository.Queries) (repository.TaskList, error) {
+		list, err := qtx.InsertTaskList(ctx, repository.InsertTaskListParams{
+			ID:      pgtype.UUID{Bytes: listUUID, Valid: true},
+			OwnerID: arg.UserUUID,
+			Name:    arg.Name,
+		})
+
+		if err != nil {
+			return repository.TaskList{}, fmt.Errorf("failed to insert task list: %w", err)
+		}
+
+		_, err = qtx.InsertTaskListMember(ctx, repository.InsertTaskListMemberParams{
+			TaskListID: list.ID,
+			UserID:     arg.UserUUID,
+			Role:       TaskListOwner,
+		})
+
+		if err != nil {
+			return repository.TaskList{}, fmt.Errorf("failed to insert task list owner: %w", err)
+		}
+
+		return list, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type TaskListParams struct {
+	UserUUID pgtype.UUID
+	ListUUID pgtype.UUID
+}
+
+func (tl taskList) DeleteTaskList(ctx context.Context, arg TaskListParams) error {
+	retryableFunc := func(qtx *repository.Queries) error {
+		member, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID)
+		if err != nil {
+			return err
+		}
+
+		if member.Role != TaskListOwner {
+			return ErrTaskListForbidden
+		}
+
+		_, err = qtx.DeleteTaskList(ctx, repository.DeleteTaskListParams{
+			ID:      arg.ListUUID,
+			OwnerID: arg.UserUUID,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to delete task list: %w", err)
+		}
+
+		return nil
+	}
+
+	return dbutil.RetryableTxWithoutData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+func (tl taskList) GetTaskListMembers(ctx context.Context, arg TaskListParams) ([]repository.SelectTaskListMembersRow, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]repository.SelectTaskListMembersRow, error) {
+		if _, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID); err != nil {
+			return nil, err
+		}
+
+		members, err := qtx.SelectTaskListMembers(ctx, arg.ListUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select task list members: %w", err)
+		}
+
+		return members, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type InviteTaskListMemberParams struct {
+	UserUUID pgtype.UUID
+	ListUUID pgtype.UUID
+	Email    string
+}
+
+// InviteTaskListMember adds the user registered with the email as a
+// collaborator. It returns pgx.ErrNoRows when nobody is registered with it.
+func (tl taskList) InviteTaskListMember(ctx context.Context, arg InviteTaskListMemberParams) (repository.SelectTaskListMembersRow, error) {
+	retryableFunc := func(qtx *repository.Queries) (repository.SelectTaskListMembersRow, error) {
+		member, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID)
+		if err != nil {
+			return repository.SelectTaskListMembersRow{}, err
+		}
+
+		if member.Role != TaskListOwner {
+			return repository.SelectTaskListMembersRow{}, ErrTaskListForbidden
+		}
+
+		invitee, err := qtx.SelectUserByEmail(ctx, arg.Email)
+		if err != nil {
+			return repository.SelectTaskListMembersRow{}, fmt.Errorf("failed to select user by email: %w", err)
+		}
+
+		invitedMember, err := qtx.InsertTaskListMember(ctx, repository.InsertTaskListMemberParams{
+			TaskListID: arg.ListUUID,
+			UserID:     invitee.ID,
+			Role:       TaskListCollaborator,
+		})
+
+		if err != nil {
+			return repository.SelectTaskListMembersRow{}, fmt.Errorf("failed to insert task list member: %w", err)
+		}
+
+		return repository.SelectTaskListMembersRow{
+			TaskListMember: invitedMember,
+			Email:          invitee.Email,
+			Name:           invitee.Name,
+		}, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type RemoveTaskListMemberParams struct {
+	UserUUID   pgtype.UUID
+	ListUUID   pgtype.UUID
+	MemberUUID pgtype.UUID
+}
+
+// RemoveTaskListMember lets the owner remove collaborators and collaborators
+// leave the list. Tasks assigned to the removed member become unassigned.
+func (tl taskList) RemoveTaskListMember(ctx context.Context, arg RemoveTaskListMemberParams) error {
+	retryableFunc := func(qtx *repository.Queries) error {
+		member, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID)
+		if err != nil {
+			return err
+		}
+
+		if member.Role != TaskListOwner && member.UserID != arg.MemberUUID {
+			return ErrTaskListForbidden
+		}
+
+		if member.Role == TaskListOwner && member.UserID == arg.MemberUUID {
+			return ErrTaskListOwnerLeave
+		}
+
+		affectedRows, err := qtx.DeleteTaskListMember(ctx, repository.DeleteTaskListMemberParams{
+			TaskListID: arg.ListUUID,
+			UserID:     arg.MemberUUID,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to delete task list member: %w", err)
+		}
+
+		if affectedRows == 0 {
+			return fmt.Errorf("failed to delete task list member: %w", pgx.ErrNoRows)
+		}
+
+		err = qtx.UnassignTaskListMemberTasks(ctx, repository.UnassignTaskListMemberTasksParams{
+			ListID:     arg.ListUUID,
+			AssigneeID: arg.MemberUUID,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to unassign task list member tasks: %w", err)
+		}
+
+		return nil
+	}
+
+	return dbutil.RetryableTxWithoutData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+func (tl taskList) GetTaskListTasks(ctx context.Context, arg TaskListParams) ([]repository.Task, error) {
+	retryableFunc := func(qtx *repository.Queries) ([]repository.Task, error) {
+		if _, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID); err != nil {
+			return nil, err
+		}
+
+		tasks, err := qtx.SelectTaskListTasks(ctx, arg.ListUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select task list tasks: %w", err)
+		}
+
+		return tasks, nil
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type CreateTaskListTaskParams struct {
+	UserUUID     pgtype.UUID
+	ListUUID     pgtype.UUID
+	Name         string
+	Description  string
+	AssigneeUUID pgtype.UUID
+	DueAt        pgtype.Timestamptz
+	Priority     int16
+	// Entitlements limit how many active tasks the user can have, including
+	// the ones they create in task lists.
+	Entitlements Entitlements
+}
+
+func (tl taskList) CreateTaskListTask(ctx context.Context, arg CreateTaskListTaskParams) (repository.Task, error) {
+	taskUUID := uuid.New()
+	retryableFunc := func(qtx *repository.Queries) (repository.Task, error) {
+		if _, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID); err != nil {
+			return repository.Task{}, err
+		}
+
+		if err := validateAssignee(ctx, qtx, arg.ListUUID, arg.AssigneeUUID); err != nil {
+			return repository.Task{}, err
+		}
+
+		return limitActiveTasks(ctx, qtx, arg.Entitlements, arg.UserUUID, func() (repository.Task, error) {
+			task, err := qtx.InsertTaskListTask(ctx, repository.InsertTaskListTaskParams{
+				ID:          pgtype.UUID{Bytes: taskUUID, Valid: true},
+				UserID:      arg.UserUUID,
+				ListID:      arg.ListUUID,
+				Name:        arg.Name,
+				Description: arg.Description,
+				AssigneeID:  arg.AssigneeUUID,
+				DueAt:       arg.DueAt,
+				Priority:    arg.Priority,
+			})
+
+			if err != nil {
+				return repository.Task{}, fmt.Errorf("failed to insert task list task: %w", err)
+			}
+
+			return task, nil
+		})
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type UpdateTaskListTaskParams struct {
+	UserUUID pgtype.UUID
+	Params   repository.UpdateTaskListTaskParams
+	// Entitlements limit how many active tasks the user can have, which
+	// unchecking a task they created adds to.
+	Entitlements Entitlements
+}
+
+// UpdateTaskListTask records the acting user as the one who completed the
+// task when it gets checked. Unchecking a task only counts against the limit
+// of active tasks of the acting user when they created it.
+func (tl taskList) UpdateTaskListTask(ctx context.Context, arg UpdateTaskListTaskParams) (repository.Task, error) {
+	retryableFunc := func(qtx *repository.Queries) (repository.Task, error) {
+		if _, err := selectTaskListMember(ctx, qtx, arg.Params.ListID, arg.UserUUID); err != nil {
+			return repository.Task{}, err
+		}
+
+		if err := validateAssignee(ctx, qtx, arg.Params.ListID, arg.Params.AssigneeID); err != nil {
+			return repository.Task{}, err
+		}
+
+		arg.Params.UpdatedBy = arg.UserUUID
+		updateTask := func() (repository.Task, error) {
+			task, err := qtx.UpdateTaskListTask(ctx, arg.Params)
+			if err != nil {
+				return repository.Task{}, fmt.Errorf("failed to update task list task: %w", err)
+			}
+			return task, nil
+		}
+
+		if arg.Params.Checked.Valid && !arg.Params.Checked.Bool {
+			return limitActiveTasks(ctx, qtx, arg.Entitlements, arg.UserUUID, updateTask)
+		}
+		return updateTask()
+	}
+
+	return dbutil.RetryableTxWithData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
+
+type DeleteTaskListTaskParams struct {
+	UserUUID pgtype.UUID
+	ListUUID pgtype.UUID
+	TaskUUID pgtype.UUID
+}
+
+// DeleteTaskListTask only lets the owner of the list or the creator of the
+// task delete it.
+func (tl taskList) DeleteTaskListTask(ctx context.Context, arg DeleteTaskListTaskParams) error {
+	retryableFunc := func(qtx *repository.Queries) error {
+		member, err := selectTaskListMember(ctx, qtx, arg.ListUUID, arg.UserUUID)
+		if err != nil {
+			return err
+		}
+
+		task, err := qtx.SelectTaskListTask(ctx, repository.SelectTaskListTaskParams{
+			ID:     arg.TaskUUID,
+			ListID: arg.ListUUID,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to select task list task: %w", err)
+		}
+
+		if member.Role != TaskListOwner && task.UserID != arg.UserUUID {
+			return ErrTaskListForbidden
+		}
+
+		_, err = qtx.DeleteTaskListTask(ctx, repository.DeleteTaskListTaskParams{
+			ID:     arg.TaskUUID,
+			ListID: arg.ListUUID,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to delete task list task: %w", err)
+		}
+
+		return nil
+	}
+
+	return dbutil.RetryableTxWithoutData(ctx, tl.configs.Db.Conn, tl.configs.Db.Queries, retryableFunc)
+}
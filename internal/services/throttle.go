@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mdayat/demi-masa-backend-service/configs"
+	"github.com/mdayat/demi-masa-backend-service/internal/dbutil"
+	"github.com/mdayat/demi-masa-backend-service/internal/mailer"
+	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
+)
+
+// loginThrottlePolicy limits failed sign in attempts of a scope. After
+// FreeAttempts failures each attempt waits twice as long as the previous one,
+// and after LockoutAttempts failures the scope is locked out. Failures older
+// than Window are forgotten.
+//
+// The address is the one chiMiddleware.RealIP takes from headers such as
+// X-Forwarded-For, which clients can set themselves. The limit of the ip
+// scope only holds when the service runs behind a trusted proxy that
+// overwrites those headers; otherwise only the email scope can be relied on.
+type loginThrottlePolicy struct {
+	Scope           string
+	FreeAttempts    int32
+	LockoutAttempts int32
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+const (
+	loginThrottleBaseDelay = time.Second
+	loginThrottleMaxDelay  = time.Minute
+)
+
+var (
+	// emailLoginThrottle protects a single account from being guessed.
+	emailLoginThrottle = loginThrottlePolicy{
+		Scope:           "email",
+		FreeAttempts:    5,
+		LockoutAttempts: 10,
+		LockoutDuration: 15 * time.Minute,
+		Window:          time.Hour,
+	}
+	// ipLoginThrottle allows more failures, since people share addresses, but
+	// stops a client from spraying passwords across accounts.
+	ipLoginThrottle = loginThrottlePolicy{
+		Scope:           "ip",
+		FreeAttempts:    20,
+		LockoutAttempts: 100,
+		LockoutDuration: 15 * time.Minute,
+		Window:          time.Hour,
+	}
+)
+
+var ErrLoginThrottled = errors.New("too many failed sign in attempts")
+
+// LoginThrottledError tells when signing in may be tried again. Locked is true
+// when the account or address is locked out, rather than only delayed.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrLoginThrottled, e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// retryAt returns when the next attempt is allowed.
+func (p loginThrottlePolicy) retryAt(throttle repository.LoginThrottle) (time.Time, bool) {
+	if throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(time.Now()) {
+		return throttle.LockedUntil.Time, true
+	}
+
+	if throttle.FailedAttempts < p.FreeAttempts || throttle.LastFailedAt.Time.Before(time.Now().Add(-p.Window)) {
+		return time.Time{}, false
+	}
+
+	exponent := float64(throttle.FailedAttempts - p.FreeAttempts)
+	delay := time.Duration(math.Min(
+		float64(loginThrottleBaseDelay)*math.Pow(2, exponent),
+		float64(loginThrottleMaxDelay),
+	))
+
+	return throttle.LastFailedAt.Time.Add(delay), false
+}
+
+type loginThrottleKey struct {
+	Email     string
+	IPAddress string
+}
+
+type loginThrottleSubject struct {
+	Policy  loginThrottlePolicy
+	Subject string
+}
+
+// subjects returns the email and the address, always in that order so
+// concurrent transactions lock the rows in the same order.
+func (k loginThrottleKey) subjects() []loginThrottleSubject {
+	// The email is normalized, so changing its case doesn't get around the
+	// throttle.
+	subjects := []loginThrottleSubject{{Policy: emailLoginThrottle, Subject: strings.ToLower(k.Email)}}
+	// Clients without an address are only throttled by email.
+	if k.IPAddress != "" {
+		subjects = append(subjects, loginThrottleSubject{Policy: ipLoginThrottle, Subject: k.IPAddress})
+	}
+	return subjects
+}
+
+// lockLoginThrottles locks the throttles of the email and the address until the
+// transaction of qtx ends, creating them when missing, so parallel attempts
+// are checked and counted one after another. It returns a LoginThrottledError
+// when either has to wait before trying again, marked as unrecoverable.
+func lockLoginThrottles(ctx context.Context, qtx *repository.Queries, key loginThrottleKey) error {
+	var throttledErr *LoginThrottledError
+	for _, v := range key.subjects() {
+		err := qtx.InsertLoginThrottle(ctx, repository.InsertLoginThrottleParams{
+			Scope:   v.Policy.Scope,
+			Subject: v.Subject,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to insert login throttle: %w", err)
+		}
+
+		throttle, err := qtx.SelectLoginThrottle(ctx, repository.SelectLoginThrottleParams{
+			Scope:   v.Policy.Scope,
+			Subject: v.Subject,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to select login throttle: %w", err)
+		}
+
+		retryAt, locked := v.Policy.retryAt(throttle)
+		retryAfter := time.Until(retryAt)
+		if retryAfter <= 0 {
+			continue
+		}
+
+		if throttledErr == nil || retryAfter > throttledErr.RetryAfter {
+			throttledErr = &LoginThrottledError{RetryAfter: retryAfter, Locked: locked}
+		}
+	}
+
+	// Retrying would only lock the throttles again and delay the response of
+	// an attempt that has to wait anyway.
+	if throttledErr != nil {
+		return retryutil.Unrecoverable(throttledErr)
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt of the email and the address, and
+// locks out those that reached their limit. It reports whether the email got
+// locked out by this attempt.
+func recordLoginFailure(ctx context.Context, qtx *repository.Queries, key loginThrottleKey) (bool, error) {
+	now := time.Now()
+	emailLocked := false
+	for _, v := range key.subjects() {
+		policy := v.Policy
+		throttle, err := qtx.IncrementLoginThrottle(ctx, repository.IncrementLoginThrottleParams{
+			Scope:          policy.Scope,
+			Subject:        v.Subject,
+			LastFailedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			WindowStartsAt: pgtype.Timestamptz{Time: now.Add(-policy.Window), Valid: true},
+		})
+
+		if err != nil {
+			return false, fmt.Errorf("failed to increment login throttle: %w", err)
+		}
+
+		if throttle.FailedAttempts < policy.LockoutAttempts {
+			continue
+		}
+
+		_, err = qtx.LockLoginThrottle(ctx, repository.LockLoginThrottleParams{
+			Scope:       policy.Scope,
+			Subject:     v.Subject,
+			LockedUntil: pgtype.Timestamptz{Time: now.Add(policy.LockoutDuration), Valid: true},
+		})
+
+		if err != nil {
+			return false, fmt.Errorf("failed to lock login throttle: %w", err)
+		}
+
+		if policy.Scope == emailLoginThrottle.Scope {
+			emailLocked = true
+		}
+	}
+
+	return emailLocked, nil
+}
+
+// loginFailure is returned by a sign in attempt that counts as failed. User is
+// nil when the email doesn't belong to anyone.
+type loginFailure struct {
+	User *repository.User
+	Err  error
+}
+
+func (e *loginFailure) Error() string {
+	return e.Err.Error()
+}
+
+func (e *loginFailure) Unwrap() error {
+	return e.Err
+}
+
+// throttleLogin runs attempt in a transaction holding the throttles of the
+// email and the address, and records the failure the attempt returns as a
+// loginFailure in the same transaction. The user, when known, is mailed once
+// their account gets locked out.
+func throttleLogin[T any](
+	ctx context.Context,
+	configs configs.Configs,
+	key loginThrottleKey,
+	attempt func(qtx *repository.Queries) (T, error),
+) (T, error) {
+	var failure *loginFailure
+	var emailLocked bool
+	retryableFunc := func(qtx *repository.Queries) (T, error) {
+		var zero T
+		failure, emailLocked = nil, false
+		if err := lockLoginThrottles(ctx, qtx, key); err != nil {
+			return zero, err
+		}
+
+		result, err := attempt(qtx)
+		if !errors.As(err, &failure) {
+			return result, err
+		}
+
+		// The failure is committed, unlike the rest of a failed attempt.
+		emailLocked, err = recordLoginFailure(ctx, qtx, key)
+		return zero, err
+	}
+
+	result, err := dbutil.RetryableTxWithData(ctx, configs.Db.Conn, configs.Db.Queries, retryableFunc)
+	if failure == nil {
+		return result, err
+	}
+
+	if err != nil {
+		return result, errors.Join(failure.Err, err)
+	}
+
+	if emailLocked && failure.User != nil {
+		if mailErr := sendLockoutEmail(ctx, configs, *failure.User); mailErr != nil {
+			return result, errors.Join(failure.Err, mailErr)
+		}
+	}
+
+	return result, failure.Err
+}
+
+// resetLoginThrottle forgets the failed attempts of the email after a
+// successful sign in. The address keeps its failures, otherwise signing in to
+// an own account would reset the throttle of an attacker.
+func (a auth) resetLoginThrottle(ctx context.Context, queries *repository.Queries, email string) error {
+	err := queries.DeleteLoginThrottle(ctx, repository.DeleteLoginThrottleParams{
+		Scope:   emailLoginThrottle.Scope,
+		Subject: strings.ToLower(email),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete login throttle: %w", err)
+	}
+
+	return nil
+}
+
+// sendLockoutEmail tells the user their account got locked out, which means
+// someone is trying to sign in as them.
+func sendLockoutEmail(ctx context.Context, configs configs.Configs, user repository.User) error {
+	err := configs.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your account was temporarily locked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThere were too many failed attempts to sign in to your account, so signing in is locked for %d minutes.\n\n"+
+				"If this wasn't you, someone may be trying to guess your password. Consider changing it:\n\n%s/forgot-password\n",
+			user.Name,
+			int(emailLoginThrottle.LockoutDuration.Minutes()),
+			configs.Env.WebAppURL,
+		),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send lockout email: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeLoginThrottles deletes the throttles whose failures are forgotten and
+// that aren't locked out anymore.
+func (a auth) PurgeLoginThrottles(ctx context.Context, now time.Time) (int64, error) {
+	window := max(emailLoginThrottle.Window, ipLoginThrottle.Window)
+	purgedThrottles, err := retryutil.RetryWithData(func() (int64, error) {
+		return a.configs.Db.Queries.DeleteStaleLoginThrottles(ctx, pgtype.Timestamptz{Time: now.Add(-window), Valid: true})
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale login throttles: %w", err)
+	}
+
+	return purgedThrottles, nil
+}
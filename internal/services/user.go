@@ -7,14 +7,18 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/alexedwards/argon2id"
 	"github.com/goccy/go-json"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mdayat/demi-masa-backend-service/configs"
 	"github.com/mdayat/demi-masa-backend-service/internal/retryutil"
+	"github.com/mdayat/demi-masa-backend-service/repository"
 )
 
 type UserServicer interface {
 	ReverseGeocode(ctx context.Context, latitude, longitude string) (reverseGeocodeResult, error)
 	ParseStringCoordinates(latitudeString, longitudeString string) (float64, float64, error)
+	ChangePassword(ctx context.Context, arg ChangePasswordParams) error
 }
 
 type user struct {
@@ -94,3 +98,75 @@ func (u user) ParseStringCoordinates(latitudeString, longitudeString string) (fl
 
 	return latitude, longitude, nil
 }
+
+var ErrWrongPassword = errors.New("wrong password")
+
+type ChangePasswordParams struct {
+	UserUUID        pgtype.UUID
+	CurrentPassword string
+	NewPassword     string
+	// CurrentSessionUUID is the session that stays signed in, the other
+	// sessions are revoked.
+	CurrentSessionUUID pgtype.UUID
+}
+
+// ChangePassword counts wrong current passwords as failed sign in attempts of
+// the email, so holding an access token isn't enough to guess the password.
+func (u user) ChangePassword(ctx context.Context, arg ChangePasswordParams) error {
+	selectUserRow, err := retryutil.RetryWithData(func() (repository.SelectUserRow, error) {
+		return u.configs.Db.Queries.SelectUser(ctx, arg.UserUUID)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to select user: %w", err)
+	}
+
+	hashedPassword, err := argon2id.CreateHash(arg.NewPassword, passwordHashParams)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	attempt := func(qtx *repository.Queries) (struct{}, error) {
+		user, err := qtx.SelectUserByEmail(ctx, selectUserRow.Email)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to select user by email: %w", err)
+		}
+
+		match, err := argon2id.ComparePasswordAndHash(arg.CurrentPassword, user.Password)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to compare password: %w", err)
+		}
+
+		if !match {
+			return struct{}{}, &loginFailure{User: &user, Err: ErrWrongPassword}
+		}
+
+		_, err = qtx.UpdateUserPassword(ctx, repository.UpdateUserPasswordParams{
+			ID:       arg.UserUUID,
+			Password: hashedPassword,
+		})
+
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to update user password: %w", err)
+		}
+
+		_, err = qtx.RevokeOtherRefreshTokenFamilies(ctx, repository.RevokeOtherRefreshTokenFamiliesParams{
+			UserID:   arg.UserUUID,
+			FamilyID: arg.CurrentSessionUUID,
+		})
+
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to revoke other refresh token families: %w", err)
+		}
+
+		// Reset links sent before the change shouldn't undo it.
+		if err := qtx.DeleteUserPasswordResetTokens(ctx, arg.UserUUID); err != nil {
+			return struct{}{}, fmt.Errorf("failed to delete user password reset tokens: %w", err)
+		}
+
+		return struct{}{}, nil
+	}
+
+	_, err = throttleLogin(ctx, u.configs, loginThrottleKey{Email: selectUserRow.Email}, attempt)
+	return err
+}
@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret of the test vectors in RFC 6238 appendix B.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// The RFC vectors have 8 digits, the last 6 are the 6-digit codes.
+	codeTable := []struct {
+		name     string
+		unix     int64
+		expected string
+	}{
+		{name: "Code/59", unix: 59, expected: "287082"},
+		{name: "Code/1111111109", unix: 1111111109, expected: "081804"},
+		{name: "Code/1111111111", unix: 1111111111, expected: "050471"},
+		{name: "Code/1234567890", unix: 1234567890, expected: "005924"},
+		{name: "Code/2000000000", unix: 2000000000, expected: "279037"},
+		{name: "Code/20000000000", unix: 20000000000, expected: "353130"},
+	}
+
+	for _, v := range codeTable {
+		t.Run(v.name, func(t *testing.T) {
+			code := Code(rfcSecret, time.Unix(v.unix, 0))
+			if code != v.expected {
+				t.Fatalf("expected code %s, got %s", v.expected, code)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	validateTable := []struct {
+		name         string
+		code         string
+		lastStep     int64
+		expectedOk   bool
+		expectedStep int64
+	}{
+		{name: "Validate/Current", code: Code(rfcSecret, now), expectedOk: true, expectedStep: step},
+		{name: "Validate/Spaces", code: "050 471", expectedOk: true, expectedStep: step},
+		{name: "Validate/Previous period", code: Code(rfcSecret, now.Add(-period*time.Second)), expectedOk: true, expectedStep: step - 1},
+		{name: "Validate/Next period", code: Code(rfcSecret, now.Add(period*time.Second)), expectedOk: true, expectedStep: step + 1},
+		{name: "Validate/Too old", code: Code(rfcSecret, now.Add(-2*period*time.Second))},
+		{name: "Validate/Already used", code: Code(rfcSecret, now), lastStep: step},
+		{name: "Validate/Wrong code", code: "000000"},
+		{name: "Validate/Wrong length", code: "05047"},
+	}
+
+	for _, v := range validateTable {
+		t.Run(v.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, v.code, now, v.lastStep)
+			if ok != v.expectedOk {
+				t.Fatalf("expected ok %t, got %t", v.expectedOk, ok)
+			}
+
+			if ok && step != v.expectedStep {
+				t.Fatalf("expected step %d, got %d", v.expectedStep, step)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI(URIParams{Issuer: "Demi Masa", AccountName: "example@gmail.com", Secret: rfcSecret})
+
+	expectedPrefix := "otpauth://totp/Demi%20Masa:example@gmail.com?"
+	if !strings.HasPrefix(uri, expectedPrefix) {
+		t.Fatalf("expected URI to start with %q, got %q", expectedPrefix, uri)
+	}
+
+	expectedSecret := "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	if !strings.Contains(uri, expectedSecret) {
+		t.Fatalf("expected URI to contain %q, got %q", expectedSecret, uri)
+	}
+}
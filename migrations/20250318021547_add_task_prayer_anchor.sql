@@ -0,0 +1,2 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "anchor_prayer" character varying(16) NULL, ADD COLUMN "anchor_relation" character varying(16) NULL, ADD COLUMN "anchor_offset_in_minutes" smallint NOT NULL DEFAULT 0, ADD CONSTRAINT "chk_task_anchor" CHECK ((anchor_prayer IS NULL) = (anchor_relation IS NULL)), ADD CONSTRAINT "task_anchor_offset_in_minutes_check" CHECK (anchor_offset_in_minutes >= 0), ADD CONSTRAINT "task_anchor_prayer_check" CHECK ((anchor_prayer)::text = ANY ((ARRAY['subuh'::character varying, 'zuhur'::character varying, 'asar'::character varying, 'magrib'::character varying, 'isya'::character varying])::text[])), ADD CONSTRAINT "task_anchor_relation_check" CHECK ((anchor_relation)::text = ANY ((ARRAY['before'::character varying, 'after'::character varying, 'between'::character varying])::text[]));
@@ -0,0 +1,13 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "recurrence_rule" character varying(255) NULL, ADD COLUMN "recurrence_start" date NULL, ADD CONSTRAINT "chk_task_recurrence" CHECK ((recurrence_rule IS NULL) = (recurrence_start IS NULL));
+-- Create "task_occurrence" table
+CREATE TABLE "task_occurrence" (
+  "task_id" uuid NOT NULL,
+  "occurrence_date" date NOT NULL,
+  "name" character varying(255) NULL,
+  "description" text NULL,
+  "checked" boolean NOT NULL DEFAULT false,
+  "skipped" boolean NOT NULL DEFAULT false,
+  PRIMARY KEY ("task_id", "occurrence_date"),
+  CONSTRAINT "fk_task_occurrence_task_id" FOREIGN KEY ("task_id") REFERENCES "task" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
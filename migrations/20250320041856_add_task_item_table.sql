@@ -0,0 +1,13 @@
+-- Create "task_item" table
+CREATE TABLE "task_item" (
+  "id" uuid NOT NULL,
+  "task_id" uuid NOT NULL,
+  "name" character varying(255) NOT NULL,
+  "checked" boolean NOT NULL DEFAULT false,
+  "position" integer NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_task_item_task_id" FOREIGN KEY ("task_id") REFERENCES "task" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "task_item_position_check" CHECK ("position" >= 0)
+);
+-- Create index "idx_task_item_task_id" to table: "task_item"
+CREATE INDEX "idx_task_item_task_id" ON "task_item" ("task_id", "position");
@@ -0,0 +1,24 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "due_at" timestamptz NULL, ADD COLUMN "priority" smallint NOT NULL DEFAULT 0, ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP, ADD CONSTRAINT "task_priority_check" CHECK ((priority >= 0) AND (priority <= 3));
+-- Create "label" table
+CREATE TABLE "label" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "name" character varying(64) NOT NULL,
+  "color" character varying(7) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "label_user_id_name_key" UNIQUE ("user_id", "name"),
+  CONSTRAINT "fk_label_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "label_color_check" CHECK ((color)::text ~ '^#[0-9a-fA-F]{6}$'::text)
+);
+-- Create "task_label" table
+CREATE TABLE "task_label" (
+  "task_id" uuid NOT NULL,
+  "label_id" uuid NOT NULL,
+  PRIMARY KEY ("task_id", "label_id"),
+  CONSTRAINT "fk_task_label_label_id" FOREIGN KEY ("label_id") REFERENCES "label" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_task_label_task_id" FOREIGN KEY ("task_id") REFERENCES "task" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- Create index "idx_task_label_label_id" to table: "task_label"
+CREATE INDEX "idx_task_label_label_id" ON "task_label" ("label_id");
@@ -0,0 +1,4 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "search_vector" tsvector NULL GENERATED ALWAYS AS ((((setweight(to_tsvector('indonesian'::regconfig, (name)::text), 'A'::"char") || setweight(to_tsvector('english'::regconfig, (name)::text), 'A'::"char")) || setweight(to_tsvector('indonesian'::regconfig, description), 'B'::"char")) || setweight(to_tsvector('english'::regconfig, description), 'B'::"char"))) STORED;
+-- Create index "idx_task_search_vector" to table: "task"
+CREATE INDEX "idx_task_search_vector" ON "task" USING GIN ("search_vector");
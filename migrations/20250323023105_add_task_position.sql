@@ -0,0 +1,9 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "position" bigint NOT NULL DEFAULT 0;
+-- Backfill "position" in the default order of the task listing, "created_at" then "id".
+-- Tasks created before "created_at" was added all share the timestamp of that
+-- migration, and no order was recorded for them, so they are ordered by "id",
+-- as the listing has shown them since.
+UPDATE "task" SET "position" = "ranked"."rank" * 1024 FROM (SELECT "id", ROW_NUMBER() OVER (PARTITION BY "user_id" ORDER BY "created_at", "id") AS "rank" FROM "task") AS "ranked" WHERE "task"."id" = "ranked"."id";
+-- Create index "idx_task_user_id_position" to table: "task"
+CREATE INDEX "idx_task_user_id_position" ON "task" ("user_id", "position");
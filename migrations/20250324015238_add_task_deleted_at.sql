@@ -0,0 +1,4 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "deleted_at" timestamptz NULL;
+-- Create index "idx_task_deleted_at" to table: "task"
+CREATE INDEX "idx_task_deleted_at" ON "task" ("deleted_at") WHERE (deleted_at IS NOT NULL);
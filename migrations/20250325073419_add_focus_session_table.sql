@@ -0,0 +1,26 @@
+-- Create "focus_session" table
+CREATE TABLE "focus_session" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "task_id" uuid NULL,
+  "planned_duration_in_minutes" smallint NOT NULL,
+  "status" character varying(16) NOT NULL DEFAULT 'running',
+  "focused_seconds" integer NOT NULL DEFAULT 0,
+  "paused_for_prayer" character varying(16) NULL,
+  "started_at" timestamptz NOT NULL,
+  "resumed_at" timestamptz NULL,
+  "ended_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_focus_session_task_id" FOREIGN KEY ("task_id") REFERENCES "task" ("id") ON UPDATE CASCADE ON DELETE SET NULL,
+  CONSTRAINT "fk_focus_session_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "chk_focus_session_ended_at" CHECK ((((status)::text = 'finished'::text)) = (ended_at IS NOT NULL)),
+  CONSTRAINT "chk_focus_session_resumed_at" CHECK ((((status)::text = 'running'::text)) = (resumed_at IS NOT NULL)),
+  CONSTRAINT "focus_session_focused_seconds_check" CHECK (focused_seconds >= 0),
+  CONSTRAINT "focus_session_paused_for_prayer_check" CHECK ((paused_for_prayer)::text = ANY ((ARRAY['subuh'::character varying, 'zuhur'::character varying, 'asar'::character varying, 'magrib'::character varying, 'isya'::character varying])::text[])),
+  CONSTRAINT "focus_session_planned_duration_in_minutes_check" CHECK ((planned_duration_in_minutes >= 1) AND (planned_duration_in_minutes <= 240)),
+  CONSTRAINT "focus_session_status_check" CHECK ((status)::text = ANY ((ARRAY['running'::character varying, 'paused'::character varying, 'finished'::character varying])::text[]))
+);
+-- Create index "idx_focus_session_user_id_active" to table: "focus_session"
+CREATE UNIQUE INDEX "idx_focus_session_user_id_active" ON "focus_session" ("user_id") WHERE ((status)::text <> 'finished'::text);
+-- Create index "idx_focus_session_user_id_started_at" to table: "focus_session"
+CREATE INDEX "idx_focus_session_user_id_started_at" ON "focus_session" ("user_id", "started_at");
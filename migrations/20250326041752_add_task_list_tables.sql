@@ -0,0 +1,26 @@
+-- Create "task_list" table
+CREATE TABLE "task_list" (
+  "id" uuid NOT NULL,
+  "owner_id" uuid NOT NULL,
+  "name" character varying(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_task_list_owner_id" FOREIGN KEY ("owner_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- Create "task_list_member" table
+CREATE TABLE "task_list_member" (
+  "task_list_id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "role" character varying(16) NOT NULL,
+  "joined_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("task_list_id", "user_id"),
+  CONSTRAINT "fk_task_list_member_task_list_id" FOREIGN KEY ("task_list_id") REFERENCES "task_list" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "fk_task_list_member_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "task_list_member_role_check" CHECK ((role)::text = ANY ((ARRAY['owner'::character varying, 'collaborator'::character varying])::text[]))
+);
+-- Create index "idx_task_list_member_user_id" to table: "task_list_member"
+CREATE INDEX "idx_task_list_member_user_id" ON "task_list_member" ("user_id");
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "list_id" uuid NULL, ADD COLUMN "assignee_id" uuid NULL, ADD COLUMN "completed_by" uuid NULL, ADD CONSTRAINT "fk_task_assignee_id" FOREIGN KEY ("assignee_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE SET NULL, ADD CONSTRAINT "fk_task_completed_by" FOREIGN KEY ("completed_by") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE SET NULL, ADD CONSTRAINT "fk_task_list_id" FOREIGN KEY ("list_id") REFERENCES "task_list" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+-- Create index "idx_task_list_id_position" to table: "task"
+CREATE INDEX "idx_task_list_id_position" ON "task" ("list_id", "position") WHERE (list_id IS NOT NULL);
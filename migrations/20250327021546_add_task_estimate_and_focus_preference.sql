@@ -0,0 +1,17 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "estimated_duration_in_minutes" smallint NULL, ADD CONSTRAINT "task_estimated_duration_in_minutes_check" CHECK ((estimated_duration_in_minutes >= 1) AND (estimated_duration_in_minutes <= 720));
+-- Create "focus_preference" table
+CREATE TABLE "focus_preference" (
+  "user_id" uuid NOT NULL,
+  "day_starts_at" time NOT NULL,
+  "day_ends_at" time NOT NULL,
+  "prayer_duration_in_minutes" smallint NOT NULL,
+  "break_in_minutes" smallint NOT NULL,
+  "default_task_duration_in_minutes" smallint NOT NULL,
+  PRIMARY KEY ("user_id"),
+  CONSTRAINT "fk_focus_preference_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT "chk_focus_preference_day" CHECK (day_starts_at < day_ends_at),
+  CONSTRAINT "focus_preference_break_in_minutes_check" CHECK ((break_in_minutes >= 0) AND (break_in_minutes <= 120)),
+  CONSTRAINT "focus_preference_default_task_duration_in_minutes_check" CHECK ((default_task_duration_in_minutes >= 5) AND (default_task_duration_in_minutes <= 480)),
+  CONSTRAINT "focus_preference_prayer_duration_in_minutes_check" CHECK ((prayer_duration_in_minutes >= 0) AND (prayer_duration_in_minutes <= 120))
+);
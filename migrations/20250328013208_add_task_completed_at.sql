@@ -0,0 +1,4 @@
+-- Modify "task" table
+ALTER TABLE "task" ADD COLUMN "completed_at" timestamptz NULL;
+-- Create index "idx_task_user_id_completed_at" to table: "task"
+CREATE INDEX "idx_task_user_id_completed_at" ON "task" ("user_id", "completed_at") WHERE (completed_at IS NOT NULL);
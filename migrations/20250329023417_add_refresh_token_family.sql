@@ -0,0 +1,8 @@
+-- Modify "refresh_token" table
+ALTER TABLE "refresh_token" ADD COLUMN "family_id" uuid NULL, ADD COLUMN "parent_id" uuid NULL, ADD CONSTRAINT "fk_refresh_token_parent_id" FOREIGN KEY ("parent_id") REFERENCES "refresh_token" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+-- Start a family for every existing token
+UPDATE "refresh_token" SET "family_id" = "id";
+-- Modify "refresh_token" table
+ALTER TABLE "refresh_token" ALTER COLUMN "family_id" SET NOT NULL;
+-- Create index "idx_refresh_token_family_id" to table: "refresh_token"
+CREATE INDEX "idx_refresh_token_family_id" ON "refresh_token" ("family_id");
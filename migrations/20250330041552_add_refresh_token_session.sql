@@ -0,0 +1,4 @@
+-- Modify "refresh_token" table
+ALTER TABLE "refresh_token" ADD COLUMN "device_name" character varying(255) NOT NULL DEFAULT '', ADD COLUMN "user_agent" text NOT NULL DEFAULT '', ADD COLUMN "ip_address" text NOT NULL DEFAULT '', ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT now(), ADD COLUMN "last_used_at" timestamptz NOT NULL DEFAULT now();
+-- Create index "idx_refresh_token_user_id" to table: "refresh_token"
+CREATE INDEX "idx_refresh_token_user_id" ON "refresh_token" ("user_id") WHERE (NOT revoked);
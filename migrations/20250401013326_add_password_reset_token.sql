@@ -0,0 +1,14 @@
+-- Create "password_reset_token" table
+CREATE TABLE "password_reset_token" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "token_hash" character(64) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NULL,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "password_reset_token_token_hash_key" UNIQUE ("token_hash"),
+  CONSTRAINT "fk_password_reset_token_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- Create index "idx_password_reset_token_user_id" to table: "password_reset_token"
+CREATE INDEX "idx_password_reset_token_user_id" ON "password_reset_token" ("user_id");
@@ -0,0 +1,12 @@
+-- Create "user_identity" table
+CREATE TABLE "user_identity" (
+  "provider" character varying(32) NOT NULL,
+  "subject" character varying(255) NOT NULL,
+  "user_id" uuid NOT NULL,
+  "email" character varying(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("provider", "subject"),
+  CONSTRAINT "fk_user_identity_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- Create index "idx_user_identity_user_id" to table: "user_identity"
+CREATE INDEX "idx_user_identity_user_id" ON "user_identity" ("user_id");
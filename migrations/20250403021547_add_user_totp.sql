@@ -0,0 +1,21 @@
+-- Create "user_totp" table
+CREATE TABLE "user_totp" (
+  "user_id" uuid NOT NULL,
+  "encrypted_secret" text NOT NULL,
+  "confirmed_at" timestamptz NULL,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("user_id"),
+  CONSTRAINT "fk_user_totp_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- Create "recovery_code" table
+CREATE TABLE "recovery_code" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "code_hash" character(64) NOT NULL,
+  "used_at" timestamptz NULL,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uq_recovery_code_user_id_code_hash" UNIQUE ("user_id", "code_hash"),
+  CONSTRAINT "fk_recovery_code_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+-- Create "login_throttle" table
+CREATE TABLE "login_throttle" (
+  "scope" character varying(16) NOT NULL,
+  "subject" character varying(255) NOT NULL,
+  "failed_attempts" integer NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL,
+  "locked_until" timestamptz NULL,
+  PRIMARY KEY ("scope", "subject")
+);
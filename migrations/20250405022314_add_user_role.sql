@@ -0,0 +1,2 @@
+-- Modify "user" table
+ALTER TABLE "user" ADD COLUMN "role" character varying(16) NOT NULL DEFAULT 'user', ADD CONSTRAINT "user_role_check" CHECK ((role)::text = ANY ((ARRAY['user'::character varying, 'admin'::character varying, 'support'::character varying, 'influencer'::character varying])::text[]));
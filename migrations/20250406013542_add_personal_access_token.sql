@@ -0,0 +1,17 @@
+-- Create "personal_access_token" table
+CREATE TABLE "personal_access_token" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "name" character varying(255) NOT NULL,
+  "token_prefix" character varying(16) NOT NULL,
+  "token_hash" character(64) NOT NULL,
+  "scopes" text[] NOT NULL,
+  "expires_at" timestamptz NULL,
+  "last_used_at" timestamptz NULL,
+  "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uq_personal_access_token_token_hash" UNIQUE ("token_hash"),
+  CONSTRAINT "fk_personal_access_token_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON UPDATE CASCADE ON DELETE CASCADE
+);
+-- Create index "idx_personal_access_token_user_id" to table: "personal_access_token"
+CREATE INDEX "idx_personal_access_token_user_id" ON "personal_access_token" ("user_id");
@@ -13,6 +13,20 @@ WHERE u.id = $1;
 -- name: SelectUserByEmail :one
 SELECT * FROM "user" WHERE email = $1;
 
+-- name: SelectUserByIdentity :one
+SELECT u.* FROM user_identity ui JOIN "user" u ON ui.user_id = u.id
+WHERE ui.provider = $1 AND ui.subject = $2;
+
+-- name: InsertUserIdentity :one
+INSERT INTO user_identity (provider, subject, user_id, email)
+VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: SelectUserRole :one
+SELECT role FROM "user" WHERE id = $1;
+
+-- name: UpdateUserRole :one
+UPDATE "user" SET role = $2 WHERE id = $1 RETURNING *;
+
 -- name: SelectUserByInvoiceId :one
 SELECT u.* FROM invoice i JOIN "user" u ON i.user_id = u.id WHERE i.id = $1;
 
@@ -20,13 +34,23 @@ SELECT u.* FROM invoice i JOIN "user" u ON i.user_id = u.id WHERE i.id = $1;
 UPDATE "user"
 SET
   email = COALESCE(sqlc.narg(email), email),
-  password = COALESCE(sqlc.narg(password), password),
+  email_verified_at = CASE
+    WHEN sqlc.narg(email)::text IS NULL OR sqlc.narg(email)::text = email THEN email_verified_at
+    ELSE NULL
+  END,
   name = COALESCE(sqlc.narg(name), name),
   coordinates = COALESCE(sqlc.narg(coordinates), coordinates),
   city = COALESCE(sqlc.narg(city), city),
   timezone = COALESCE(sqlc.narg(timezone), timezone)
 WHERE id = $1 RETURNING *;
 
+-- name: UpdateUserPassword :one
+UPDATE "user" SET password = $2 WHERE id = $1 RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE "user" SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+WHERE id = $1 AND email = $2 RETURNING *;
+
 -- name: DeleteUser :exec
 DELETE FROM "user" WHERE id = $1;
 
@@ -35,19 +59,115 @@ INSERT INTO subscription (id, user_id, plan_id, payment_id, start_date, end_date
 VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
 
 -- name: SelectUserActiveSubscription :one
-SELECT * FROM subscription WHERE user_id = $1 AND end_date > NOW();
+SELECT * FROM subscription WHERE user_id = $1 AND end_date > NOW() ORDER BY end_date DESC LIMIT 1;
 
 -- name: InsertUserRefreshToken :one
-INSERT INTO refresh_token (id, user_id, expires_at)
-VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO refresh_token (id, user_id, expires_at, family_id, parent_id, device_name, user_agent, ip_address, created_at, last_used_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
 
 -- name: SelectUserRefreshToken :one
-SELECT * FROM refresh_token WHERE id = $1 AND user_id = $2;
+SELECT * FROM refresh_token WHERE id = $1 AND user_id = $2 FOR UPDATE;
 
 -- name: RevokeUserRefreshToken :one
 UPDATE refresh_token SET revoked = TRUE
 WHERE id = $1 AND user_id = $2 RETURNING *;
 
+-- name: RevokeRefreshTokenFamily :execrows
+UPDATE refresh_token SET revoked = TRUE
+WHERE family_id = $1 AND user_id = $2 AND NOT revoked;
+
+-- name: SelectUserSessions :many
+SELECT * FROM refresh_token
+WHERE user_id = $1 AND NOT revoked AND expires_at > NOW()
+ORDER BY last_used_at DESC;
+
+-- name: RevokeOtherRefreshTokenFamilies :execrows
+UPDATE refresh_token SET revoked = TRUE
+WHERE user_id = $1 AND family_id IS DISTINCT FROM $2 AND NOT revoked;
+
+-- name: RevokeUserRefreshTokens :execrows
+UPDATE refresh_token SET revoked = TRUE WHERE user_id = $1 AND NOT revoked;
+
+-- name: DeleteUserPasswordResetTokens :exec
+DELETE FROM password_reset_token WHERE user_id = $1;
+
+-- name: InsertUserPasswordResetToken :one
+INSERT INTO password_reset_token (id, user_id, token_hash, expires_at)
+VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: UsePasswordResetToken :one
+UPDATE password_reset_token SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW() RETURNING *;
+
+-- name: SelectUserTOTP :one
+SELECT * FROM user_totp WHERE user_id = $1;
+
+-- name: UpsertUserTOTP :one
+INSERT INTO user_totp (user_id, encrypted_secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  encrypted_secret = EXCLUDED.encrypted_secret,
+  last_used_step = 0,
+  created_at = CURRENT_TIMESTAMP
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: ConfirmUserTOTP :one
+UPDATE user_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $2
+WHERE user_id = $1 AND confirmed_at IS NULL RETURNING *;
+
+-- name: UseUserTOTPStep :execrows
+UPDATE user_totp SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserTOTP :execrows
+DELETE FROM user_totp WHERE user_id = $1;
+
+-- name: InsertUserRecoveryCodes :copyfrom
+INSERT INTO recovery_code (id, user_id, code_hash) VALUES ($1, $2, $3);
+
+-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM recovery_code WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_code SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUserRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_code WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: InsertLoginThrottle :exec
+INSERT INTO login_throttle (scope, subject, last_failed_at)
+VALUES ($1, $2, '-infinity')
+ON CONFLICT (scope, subject) DO NOTHING;
+
+-- name: SelectLoginThrottle :one
+SELECT * FROM login_throttle WHERE scope = $1 AND subject = $2 FOR UPDATE;
+
+-- name: IncrementLoginThrottle :one
+INSERT INTO login_throttle (scope, subject, failed_attempts, last_failed_at)
+VALUES ($1, $2, 1, $3)
+ON CONFLICT (scope, subject) DO UPDATE
+SET
+  failed_attempts = CASE
+    WHEN login_throttle.last_failed_at < sqlc.arg(window_starts_at) THEN 1
+    ELSE login_throttle.failed_attempts + 1
+  END,
+  last_failed_at = EXCLUDED.last_failed_at
+RETURNING *;
+
+-- name: LockLoginThrottle :one
+UPDATE login_throttle SET failed_attempts = 0, locked_until = $3
+WHERE scope = $1 AND subject = $2 RETURNING *;
+
+-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttle WHERE scope = $1 AND subject = $2;
+
+-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM login_throttle
+WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < $1);
+
 -- name: InsertUserPrayers :copyfrom
 INSERT INTO prayer (id, user_id, name, year, month, day)
 VALUES ($1, $2, $3, $4, $5, $6);
@@ -80,7 +200,20 @@ INSERT INTO coupon (code, influencer_username, quota)
 VALUES ($1, $2, $3) RETURNING *;
 
 -- name: SelectCoupon :one
-SELECT * FROM coupon WHERE code = $1;
+SELECT * FROM coupon WHERE code = $1 AND deleted_at IS NULL;
+
+-- name: SelectCoupons :many
+SELECT * FROM coupon WHERE deleted_at IS NULL ORDER BY created_at DESC;
+
+-- name: UpdateCoupon :one
+UPDATE coupon
+SET
+  influencer_username = COALESCE(sqlc.narg(influencer_username), influencer_username),
+  quota = COALESCE(sqlc.narg(quota), quota)
+WHERE code = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: DeleteCoupon :execrows
+UPDATE coupon SET deleted_at = NOW() WHERE code = $1 AND deleted_at IS NULL;
 
 -- name: DecrementCouponQuota :execrows
 UPDATE coupon SET quota = quota - 1
@@ -109,20 +242,408 @@ SELECT * FROM plan WHERE deleted_at IS NULL;
 -- name: SelectPlan :one
 SELECT * FROM plan WHERE id = $1 AND deleted_at IS NULL;
 
+-- name: SelectSubscribedPlan :one
+SELECT * FROM plan WHERE id = $1;
+
+-- name: UpdatePlan :one
+UPDATE plan
+SET
+  name = COALESCE(sqlc.narg(name), name),
+  price = COALESCE(sqlc.narg(price), price),
+  duration_in_months = COALESCE(sqlc.narg(duration_in_months), duration_in_months)
+WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: DeletePlan :execrows
+UPDATE plan SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL;
+
 -- name: SelectUserTasks :many
-SELECT * FROM task WHERE user_id = $1;
+SELECT sqlc.embed(t), k.sort_key
+FROM task t
+CROSS JOIN LATERAL (
+  SELECT (
+    CASE sqlc.arg(sort_by)::text
+      WHEN 'due_at' THEN COALESCE(EXTRACT(EPOCH FROM t.due_at)::float8, 'Infinity'::float8)
+      WHEN 'priority' THEN t.priority::float8
+      WHEN 'position' THEN t.position::float8
+      ELSE EXTRACT(EPOCH FROM t.created_at)::float8
+    END
+  )::float8 AS sort_key
+) k
+WHERE
+  t.user_id = $1
+  AND t.list_id IS NULL
+  AND t.deleted_at IS NULL
+  AND (sqlc.narg(label_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM task_label tl WHERE tl.task_id = t.id AND tl.label_id = sqlc.narg(label_id)::uuid
+  ))
+  AND (sqlc.narg(checked)::boolean IS NULL OR t.checked = sqlc.narg(checked)::boolean)
+  AND (sqlc.narg(due_before)::timestamptz IS NULL OR t.due_at < sqlc.narg(due_before)::timestamptz)
+  AND (sqlc.narg(due_after)::timestamptz IS NULL OR t.due_at > sqlc.narg(due_after)::timestamptz)
+  AND (sqlc.narg(priority)::smallint IS NULL OR t.priority = sqlc.narg(priority)::smallint)
+  AND (
+    sqlc.narg(cursor_id)::uuid IS NULL
+    OR (sqlc.arg(descending)::boolean AND (k.sort_key, t.id) < (sqlc.narg(cursor_sort_key)::float8, sqlc.narg(cursor_id)::uuid))
+    OR (NOT sqlc.arg(descending)::boolean AND (k.sort_key, t.id) > (sqlc.narg(cursor_sort_key)::float8, sqlc.narg(cursor_id)::uuid))
+  )
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::boolean THEN k.sort_key END DESC,
+  CASE WHEN sqlc.arg(descending)::boolean THEN t.id END DESC,
+  k.sort_key,
+  t.id
+LIMIT sqlc.arg(row_limit)::int;
+
+-- name: SelectUserTask :one
+SELECT * FROM task WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL;
 
 -- name: InsertUserTask :one
-INSERT INTO task (id, user_id, name, description)
-VALUES ($1, $2, $3, $4) RETURNING *;
+INSERT INTO task (id, user_id, name, description, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, estimated_duration_in_minutes, position)
+VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+  (SELECT COALESCE(MAX(position), 0) + 1024 FROM task WHERE user_id = $2 AND list_id IS NULL)
+)
+RETURNING *;
 
 -- name: UpdateUserTask :one
 UPDATE task
 SET
   name = COALESCE(sqlc.narg(name), name),
   description = COALESCE(sqlc.narg(description), description),
-  checked = COALESCE(sqlc.narg(checked), checked)
-WHERE id = $1 AND user_id = $2 RETURNING *;
+  checked = COALESCE(sqlc.narg(checked), checked),
+  completed_at = CASE
+    WHEN sqlc.narg(checked)::boolean IS NULL OR sqlc.narg(checked)::boolean = checked THEN completed_at
+    WHEN sqlc.narg(checked)::boolean THEN CURRENT_TIMESTAMP
+    ELSE NULL
+  END,
+  anchor_prayer = CASE WHEN sqlc.arg(remove_anchor)::boolean THEN NULL ELSE COALESCE(sqlc.narg(anchor_prayer), anchor_prayer) END,
+  anchor_relation = CASE WHEN sqlc.arg(remove_anchor)::boolean THEN NULL ELSE COALESCE(sqlc.narg(anchor_relation), anchor_relation) END,
+  anchor_offset_in_minutes = CASE WHEN sqlc.arg(remove_anchor)::boolean THEN 0 ELSE COALESCE(sqlc.narg(anchor_offset_in_minutes), anchor_offset_in_minutes) END,
+  recurrence_rule = CASE WHEN sqlc.arg(remove_recurrence)::boolean THEN NULL ELSE COALESCE(sqlc.narg(recurrence_rule), recurrence_rule) END,
+  recurrence_start = CASE WHEN sqlc.arg(remove_recurrence)::boolean THEN NULL ELSE COALESCE(sqlc.narg(recurrence_start), recurrence_start) END,
+  due_at = CASE WHEN sqlc.arg(remove_due_at)::boolean THEN NULL ELSE COALESCE(sqlc.narg(due_at), due_at) END,
+  priority = COALESCE(sqlc.narg(priority), priority),
+  estimated_duration_in_minutes = CASE WHEN sqlc.arg(remove_estimated_duration)::boolean THEN NULL ELSE COALESCE(sqlc.narg(estimated_duration_in_minutes), estimated_duration_in_minutes) END
+WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL RETURNING *;
+
+-- name: SelectPreviousTaskPosition :one
+SELECT position FROM task
+WHERE user_id = $1 AND list_id IS NULL AND id <> sqlc.arg(excluded_id) AND position < sqlc.arg(position) AND deleted_at IS NULL
+ORDER BY position DESC
+LIMIT 1;
+
+-- name: SelectNextTaskPosition :one
+SELECT position FROM task
+WHERE user_id = $1 AND list_id IS NULL AND id <> sqlc.arg(excluded_id) AND position > sqlc.arg(position) AND deleted_at IS NULL
+ORDER BY position
+LIMIT 1;
+
+-- name: UpdateUserTaskPosition :one
+UPDATE task SET position = $3 WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL RETURNING *;
+
+-- name: RebalanceUserTaskPositions :exec
+UPDATE task
+SET position = ranked.rank * 1024
+FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS rank
+  FROM task
+  WHERE user_id = $1 AND list_id IS NULL AND deleted_at IS NULL
+) ranked
+WHERE task.id = ranked.id;
 
 -- name: DeleteUserTask :execrows
-DELETE FROM task WHERE id = $1 AND user_id = $2;
\ No newline at end of file
+UPDATE task SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL;
+
+-- name: SelectUserTrashedTasks :many
+SELECT * FROM task WHERE user_id = $1 AND list_id IS NULL AND deleted_at IS NOT NULL ORDER BY deleted_at DESC;
+
+-- name: RestoreUserTask :one
+UPDATE task SET deleted_at = NULL WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NOT NULL RETURNING *;
+
+-- name: PurgeTrashedTasks :execrows
+DELETE FROM task WHERE deleted_at < sqlc.arg(deleted_before)::timestamptz;
+
+-- name: SelectTaskOccurrences :many
+SELECT * FROM task_occurrence
+WHERE task_id = $1 AND occurrence_date BETWEEN sqlc.arg(from_date)::date AND sqlc.arg(to_date)::date;
+
+-- name: UpsertTaskOccurrence :one
+INSERT INTO task_occurrence (task_id, occurrence_date, name, description, checked, skipped)
+VALUES ($1, $2, $3, $4, COALESCE(sqlc.narg(checked), FALSE), COALESCE(sqlc.narg(skipped), FALSE))
+ON CONFLICT (task_id, occurrence_date) DO UPDATE
+SET
+  name = COALESCE(EXCLUDED.name, task_occurrence.name),
+  description = COALESCE(EXCLUDED.description, task_occurrence.description),
+  checked = COALESCE(sqlc.narg(checked), task_occurrence.checked),
+  skipped = COALESCE(sqlc.narg(skipped), task_occurrence.skipped)
+RETURNING *;
+
+-- name: SelectTaskItems :many
+SELECT * FROM task_item WHERE task_id = $1 ORDER BY position;
+
+-- name: SelectTasksItemProgress :many
+SELECT
+  task_id,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE checked) AS checked
+FROM task_item
+WHERE task_id = ANY(sqlc.arg(task_ids)::uuid[])
+GROUP BY task_id;
+
+-- name: InsertTaskItem :one
+INSERT INTO task_item (id, task_id, name, position)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position) + 1, 0) FROM task_item WHERE task_id = $2))
+RETURNING *;
+
+-- name: UpdateUserTaskItem :one
+UPDATE task_item
+SET
+  name = COALESCE(sqlc.narg(name), task_item.name),
+  checked = COALESCE(sqlc.narg(checked), task_item.checked)
+FROM task
+WHERE task_item.id = $1 AND task_item.task_id = $2 AND task.id = task_item.task_id AND task.user_id = $3 AND task.list_id IS NULL AND task.deleted_at IS NULL
+RETURNING task_item.*;
+
+-- name: UpdateTaskItemPositions :exec
+UPDATE task_item
+SET position = array_position(sqlc.arg(item_ids)::uuid[], id) - 1
+WHERE task_id = $1;
+
+-- name: DeleteUserTaskItem :execrows
+DELETE FROM task_item
+USING task
+WHERE task_item.id = $1 AND task_item.task_id = $2 AND task.id = task_item.task_id AND task.user_id = $3 AND task.list_id IS NULL AND task.deleted_at IS NULL;
+
+-- name: SelectUserLabels :many
+SELECT * FROM label WHERE user_id = $1 ORDER BY name;
+
+-- name: InsertUserLabel :one
+INSERT INTO label (id, user_id, name, color) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: UpdateUserLabel :one
+UPDATE label
+SET
+  name = COALESCE(sqlc.narg(name), name),
+  color = COALESCE(sqlc.narg(color), color)
+WHERE id = $1 AND user_id = $2 RETURNING *;
+
+-- name: DeleteUserLabel :execrows
+DELETE FROM label WHERE id = $1 AND user_id = $2;
+
+-- name: SelectTasksLabels :many
+SELECT tl.task_id, sqlc.embed(l)
+FROM task_label tl
+JOIN label l ON l.id = tl.label_id
+WHERE tl.task_id = ANY(sqlc.arg(task_ids)::uuid[])
+ORDER BY l.name;
+
+-- name: InsertUserTaskLabel :execrows
+INSERT INTO task_label (task_id, label_id)
+SELECT t.id, l.id
+FROM task t
+JOIN label l ON l.user_id = t.user_id
+WHERE t.id = sqlc.arg(task_id) AND l.id = sqlc.arg(label_id) AND t.user_id = sqlc.arg(user_id) AND t.list_id IS NULL AND t.deleted_at IS NULL
+ON CONFLICT (task_id, label_id) DO UPDATE SET label_id = EXCLUDED.label_id;
+
+-- name: DeleteUserTaskLabel :execrows
+DELETE FROM task_label
+USING task
+WHERE task_label.task_id = $1 AND task_label.label_id = $2 AND task.id = task_label.task_id AND task.user_id = $3 AND task.list_id IS NULL AND task.deleted_at IS NULL;
+
+-- name: SearchUserTasks :many
+SELECT
+  t.id,
+  ts_rank(t.search_vector, q.query)::float4 AS rank,
+  ts_headline(c.config, replace(replace(replace(t.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>')::text AS name_highlight,
+  ts_headline(c.config, replace(replace(replace(t.description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query, 'MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<mark>, StopSel=</mark>')::text AS description_highlight
+FROM task t
+CROSS JOIN LATERAL (
+  SELECT
+    websearch_to_tsquery('indonesian', sqlc.arg(query)::text) AS indonesian_query,
+    websearch_to_tsquery('indonesian', sqlc.arg(query)::text) || websearch_to_tsquery('english', sqlc.arg(query)::text) AS query
+) q
+CROSS JOIN LATERAL (
+  SELECT (
+    CASE WHEN t.search_vector @@ q.indonesian_query
+      THEN 'indonesian'
+      ELSE 'english'
+    END
+  )::regconfig AS config
+) c
+WHERE t.user_id = $1 AND t.list_id IS NULL AND t.deleted_at IS NULL AND t.search_vector @@ q.query
+ORDER BY rank DESC, t.created_at DESC
+LIMIT sqlc.arg(row_limit)::int;
+
+-- name: InsertUserFocusSession :one
+INSERT INTO focus_session (id, user_id, task_id, planned_duration_in_minutes, started_at, resumed_at)
+VALUES ($1, $2, $3, $4, sqlc.arg(started_at), sqlc.arg(started_at))
+RETURNING *;
+
+-- name: SelectUserActiveFocusSession :one
+SELECT * FROM focus_session WHERE user_id = $1 AND status <> 'finished' FOR UPDATE;
+
+-- name: SelectUserFocusSession :one
+SELECT * FROM focus_session WHERE id = $1 AND user_id = $2 FOR UPDATE;
+
+-- name: UpdateFocusSession :one
+UPDATE focus_session
+SET
+  status = $2,
+  focused_seconds = $3,
+  paused_for_prayer = $4,
+  resumed_at = $5,
+  ended_at = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: SelectUserFocusedSeconds :one
+SELECT COALESCE(SUM(
+  focused_seconds + CASE WHEN status = 'running' THEN date_part('epoch', sqlc.arg(now)::timestamptz - resumed_at)::int ELSE 0 END
+), 0)::bigint AS focused_seconds
+FROM focus_session
+WHERE user_id = $1 AND started_at >= sqlc.arg(started_from)::timestamptz AND started_at < sqlc.arg(started_until)::timestamptz;
+
+-- name: InsertTaskList :one
+INSERT INTO task_list (id, owner_id, name) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: SelectUserTaskLists :many
+SELECT tl.* FROM task_list tl
+JOIN task_list_member m ON m.task_list_id = tl.id
+WHERE m.user_id = $1
+ORDER BY tl.created_at;
+
+-- name: DeleteTaskList :execrows
+DELETE FROM task_list WHERE id = $1 AND owner_id = $2;
+
+-- name: InsertTaskListMember :one
+INSERT INTO task_list_member (task_list_id, user_id, role) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: SelectTaskListMember :one
+SELECT * FROM task_list_member WHERE task_list_id = $1 AND user_id = $2;
+
+-- name: SelectTaskListMembers :many
+SELECT sqlc.embed(m), u.email, u.name
+FROM task_list_member m
+JOIN "user" u ON u.id = m.user_id
+WHERE m.task_list_id = $1
+ORDER BY m.joined_at;
+
+-- name: DeleteTaskListMember :execrows
+DELETE FROM task_list_member WHERE task_list_id = $1 AND user_id = $2 AND role <> 'owner';
+
+-- name: UnassignTaskListMemberTasks :exec
+UPDATE task SET assignee_id = NULL WHERE list_id = $1 AND assignee_id = $2;
+
+-- name: SelectTaskListTasks :many
+SELECT * FROM task WHERE list_id = $1 AND deleted_at IS NULL ORDER BY position, id;
+
+-- name: SelectTaskListTask :one
+SELECT * FROM task WHERE id = $1 AND list_id = $2 AND deleted_at IS NULL;
+
+-- name: InsertTaskListTask :one
+INSERT INTO task (id, user_id, list_id, name, description, assignee_id, due_at, priority, position)
+VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8,
+  (SELECT COALESCE(MAX(position), 0) + 1024 FROM task WHERE list_id = $3)
+)
+RETURNING *;
+
+-- name: UpdateTaskListTask :one
+UPDATE task
+SET
+  name = COALESCE(sqlc.narg(name), name),
+  description = COALESCE(sqlc.narg(description), description),
+  checked = COALESCE(sqlc.narg(checked), checked),
+  completed_by = CASE
+    WHEN sqlc.narg(checked)::boolean IS NULL OR sqlc.narg(checked)::boolean = checked THEN completed_by
+    WHEN sqlc.narg(checked)::boolean THEN sqlc.arg(updated_by)::uuid
+    ELSE NULL
+  END,
+  completed_at = CASE
+    WHEN sqlc.narg(checked)::boolean IS NULL OR sqlc.narg(checked)::boolean = checked THEN completed_at
+    WHEN sqlc.narg(checked)::boolean THEN CURRENT_TIMESTAMP
+    ELSE NULL
+  END,
+  assignee_id = CASE WHEN sqlc.arg(remove_assignee)::boolean THEN NULL ELSE COALESCE(sqlc.narg(assignee_id), assignee_id) END,
+  due_at = CASE WHEN sqlc.arg(remove_due_at)::boolean THEN NULL ELSE COALESCE(sqlc.narg(due_at), due_at) END,
+  priority = COALESCE(sqlc.narg(priority), priority)
+WHERE id = $1 AND list_id = $2 AND deleted_at IS NULL RETURNING *;
+
+-- name: DeleteTaskListTask :execrows
+UPDATE task SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND list_id = $2 AND deleted_at IS NULL;
+
+-- name: SelectUserPlannableTasks :many
+SELECT * FROM task
+WHERE
+  user_id = $1
+  AND list_id IS NULL
+  AND deleted_at IS NULL
+  AND (
+    (recurrence_rule IS NOT NULL AND recurrence_start <= sqlc.arg(date)::date)
+    OR (
+      recurrence_rule IS NULL
+      AND NOT checked
+      AND (
+        (due_at >= sqlc.arg(due_from)::timestamptz AND due_at < sqlc.arg(due_until)::timestamptz)
+        OR (due_at IS NULL AND anchor_prayer IS NOT NULL)
+      )
+    )
+  )
+ORDER BY priority DESC, due_at, position;
+
+-- name: SelectTasksOccurrencesOn :many
+SELECT * FROM task_occurrence
+WHERE task_id = ANY(sqlc.arg(task_ids)::uuid[]) AND occurrence_date = sqlc.arg(occurrence_date)::date;
+
+-- name: LockUserTasks :exec
+SELECT id FROM "user" WHERE id = $1 FOR NO KEY UPDATE;
+
+-- name: CountUserActiveTasks :one
+SELECT COUNT(*) FROM task WHERE user_id = $1 AND deleted_at IS NULL AND NOT checked;
+
+-- name: SelectUserCompletedTasks :many
+SELECT id, created_at, due_at, completed_at FROM task
+WHERE
+  ((user_id = $1 AND list_id IS NULL) OR completed_by = $1)
+  AND deleted_at IS NULL
+  AND checked
+  AND completed_at >= sqlc.arg(completed_from)::timestamptz
+  AND completed_at < sqlc.arg(completed_until)::timestamptz
+ORDER BY completed_at;
+
+-- name: SelectUserFocusPreference :one
+SELECT * FROM focus_preference WHERE user_id = $1;
+
+-- name: UpsertUserFocusPreference :one
+INSERT INTO focus_preference (user_id, day_starts_at, day_ends_at, prayer_duration_in_minutes, break_in_minutes, default_task_duration_in_minutes)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  day_starts_at = EXCLUDED.day_starts_at,
+  day_ends_at = EXCLUDED.day_ends_at,
+  prayer_duration_in_minutes = EXCLUDED.prayer_duration_in_minutes,
+  break_in_minutes = EXCLUDED.break_in_minutes,
+  default_task_duration_in_minutes = EXCLUDED.default_task_duration_in_minutes
+RETURNING *;
+
+-- name: InsertPersonalAccessToken :one
+INSERT INTO personal_access_token (id, user_id, name, token_prefix, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: SelectUserPersonalAccessTokens :many
+SELECT * FROM personal_access_token WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: SelectUserPersonalAccessToken :one
+SELECT * FROM personal_access_token WHERE id = $1 AND user_id = $2;
+
+-- name: CountUserPersonalAccessTokens :one
+SELECT COUNT(*) FROM personal_access_token WHERE user_id = $1;
+
+-- name: UsePersonalAccessToken :one
+UPDATE personal_access_token SET last_used_at = NOW()
+WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
+
+-- name: DeleteUserPersonalAccessToken :execrows
+DELETE FROM personal_access_token WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteUserPersonalAccessTokens :execrows
+DELETE FROM personal_access_token WHERE user_id = $1;
\ No newline at end of file
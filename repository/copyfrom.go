@@ -45,3 +45,37 @@ func (r iteratorForInsertUserPrayers) Err() error {
 func (q *Queries) InsertUserPrayers(ctx context.Context, arg []InsertUserPrayersParams) (int64, error) {
 	return q.db.CopyFrom(ctx, []string{"prayer"}, []string{"id", "user_id", "name", "year", "month", "day"}, &iteratorForInsertUserPrayers{rows: arg})
 }
+
+// iteratorForInsertUserRecoveryCodes implements pgx.CopyFromSource.
+type iteratorForInsertUserRecoveryCodes struct {
+	rows                 []InsertUserRecoveryCodesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertUserRecoveryCodes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertUserRecoveryCodes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].UserID,
+		r.rows[0].CodeHash,
+	}, nil
+}
+
+func (r iteratorForInsertUserRecoveryCodes) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertUserRecoveryCodes(ctx context.Context, arg []InsertUserRecoveryCodesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"recovery_code"}, []string{"id", "user_id", "code_hash"}, &iteratorForInsertUserRecoveryCodes{rows: arg})
+}
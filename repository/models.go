@@ -16,6 +16,28 @@ type Coupon struct {
 	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
 }
 
+type FocusPreference struct {
+	UserID                       pgtype.UUID `json:"user_id"`
+	DayStartsAt                  pgtype.Time `json:"day_starts_at"`
+	DayEndsAt                    pgtype.Time `json:"day_ends_at"`
+	PrayerDurationInMinutes      int16       `json:"prayer_duration_in_minutes"`
+	BreakInMinutes               int16       `json:"break_in_minutes"`
+	DefaultTaskDurationInMinutes int16       `json:"default_task_duration_in_minutes"`
+}
+
+type FocusSession struct {
+	ID                       pgtype.UUID        `json:"id"`
+	UserID                   pgtype.UUID        `json:"user_id"`
+	TaskID                   pgtype.UUID        `json:"task_id"`
+	PlannedDurationInMinutes int16              `json:"planned_duration_in_minutes"`
+	Status                   string             `json:"status"`
+	FocusedSeconds           int32              `json:"focused_seconds"`
+	PausedForPrayer          pgtype.Text        `json:"paused_for_prayer"`
+	StartedAt                pgtype.Timestamptz `json:"started_at"`
+	ResumedAt                pgtype.Timestamptz `json:"resumed_at"`
+	EndedAt                  pgtype.Timestamptz `json:"ended_at"`
+}
+
 type Invoice struct {
 	ID          pgtype.UUID        `json:"id"`
 	UserID      pgtype.UUID        `json:"user_id"`
@@ -28,6 +50,31 @@ type Invoice struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type Label struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Name      string             `json:"name"`
+	Color     string             `json:"color"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type LoginThrottle struct {
+	Scope          string             `json:"scope"`
+	Subject        string             `json:"subject"`
+	FailedAttempts int32              `json:"failed_attempts"`
+	LastFailedAt   pgtype.Timestamptz `json:"last_failed_at"`
+	LockedUntil    pgtype.Timestamptz `json:"locked_until"`
+}
+
+type PasswordResetToken struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Payment struct {
 	ID         pgtype.UUID        `json:"id"`
 	UserID     pgtype.UUID        `json:"user_id"`
@@ -37,6 +84,18 @@ type Payment struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
+type PersonalAccessToken struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	Name        string             `json:"name"`
+	TokenPrefix string             `json:"token_prefix"`
+	TokenHash   string             `json:"token_hash"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type Plan struct {
 	ID               pgtype.UUID        `json:"id"`
 	Type             string             `json:"type"`
@@ -57,11 +116,26 @@ type Prayer struct {
 	Day    int16       `json:"day"`
 }
 
-type RefreshToken struct {
+type RecoveryCode struct {
 	ID        pgtype.UUID        `json:"id"`
 	UserID    pgtype.UUID        `json:"user_id"`
-	Revoked   bool               `json:"revoked"`
-	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Revoked    bool               `json:"revoked"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	FamilyID   pgtype.UUID        `json:"family_id"`
+	ParentID   pgtype.UUID        `json:"parent_id"`
+	DeviceName string             `json:"device_name"`
+	UserAgent  string             `json:"user_agent"`
+	IpAddress  string             `json:"ip_address"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
 }
 
 type Subscription struct {
@@ -74,20 +148,90 @@ type Subscription struct {
 }
 
 type Task struct {
-	ID          pgtype.UUID `json:"id"`
-	UserID      pgtype.UUID `json:"user_id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Checked     bool        `json:"checked"`
+	ID                         pgtype.UUID        `json:"id"`
+	UserID                     pgtype.UUID        `json:"user_id"`
+	Name                       string             `json:"name"`
+	Description                string             `json:"description"`
+	Checked                    bool               `json:"checked"`
+	AnchorPrayer               pgtype.Text        `json:"anchor_prayer"`
+	AnchorRelation             pgtype.Text        `json:"anchor_relation"`
+	AnchorOffsetInMinutes      int16              `json:"anchor_offset_in_minutes"`
+	RecurrenceRule             pgtype.Text        `json:"recurrence_rule"`
+	RecurrenceStart            pgtype.Date        `json:"recurrence_start"`
+	DueAt                      pgtype.Timestamptz `json:"due_at"`
+	Priority                   int16              `json:"priority"`
+	Position                   int64              `json:"position"`
+	CreatedAt                  pgtype.Timestamptz `json:"created_at"`
+	DeletedAt                  pgtype.Timestamptz `json:"deleted_at"`
+	ListID                     pgtype.UUID        `json:"list_id"`
+	AssigneeID                 pgtype.UUID        `json:"assignee_id"`
+	CompletedBy                pgtype.UUID        `json:"completed_by"`
+	EstimatedDurationInMinutes pgtype.Int2        `json:"estimated_duration_in_minutes"`
+	CompletedAt                pgtype.Timestamptz `json:"completed_at"`
+	SearchVector               interface{}        `json:"search_vector"`
+}
+
+type TaskItem struct {
+	ID       pgtype.UUID `json:"id"`
+	TaskID   pgtype.UUID `json:"task_id"`
+	Name     string      `json:"name"`
+	Checked  bool        `json:"checked"`
+	Position int32       `json:"position"`
+}
+
+type TaskLabel struct {
+	TaskID  pgtype.UUID `json:"task_id"`
+	LabelID pgtype.UUID `json:"label_id"`
+}
+
+type TaskList struct {
+	ID        pgtype.UUID        `json:"id"`
+	OwnerID   pgtype.UUID        `json:"owner_id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TaskListMember struct {
+	TaskListID pgtype.UUID        `json:"task_list_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Role       string             `json:"role"`
+	JoinedAt   pgtype.Timestamptz `json:"joined_at"`
+}
+
+type TaskOccurrence struct {
+	TaskID         pgtype.UUID `json:"task_id"`
+	OccurrenceDate pgtype.Date `json:"occurrence_date"`
+	Name           pgtype.Text `json:"name"`
+	Description    pgtype.Text `json:"description"`
+	Checked        bool        `json:"checked"`
+	Skipped        bool        `json:"skipped"`
 }
 
 type User struct {
-	ID          pgtype.UUID        `json:"id"`
-	Email       string             `json:"email"`
-	Password    string             `json:"password"`
-	Name        string             `json:"name"`
-	Coordinates pgtype.Point       `json:"coordinates"`
-	City        string             `json:"city"`
-	Timezone    string             `json:"timezone"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ID              pgtype.UUID        `json:"id"`
+	Email           string             `json:"email"`
+	Password        string             `json:"password"`
+	Name            string             `json:"name"`
+	Coordinates     pgtype.Point       `json:"coordinates"`
+	City            string             `json:"city"`
+	Timezone        string             `json:"timezone"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	Role            string             `json:"role"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserIdentity struct {
+	Provider  string             `json:"provider"`
+	Subject   string             `json:"subject"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Email     string             `json:"email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID          pgtype.UUID        `json:"user_id"`
+	EncryptedSecret string             `json:"encrypted_secret"`
+	ConfirmedAt     pgtype.Timestamptz `json:"confirmed_at"`
+	LastUsedStep    int64              `json:"last_used_step"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
 }
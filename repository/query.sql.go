@@ -11,6 +11,62 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const confirmUserTOTP = `-- name: ConfirmUserTOTP :one
+UPDATE user_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $2
+WHERE user_id = $1 AND confirmed_at IS NULL RETURNING user_id, encrypted_secret, confirmed_at, last_used_step, created_at
+`
+
+type ConfirmUserTOTPParams struct {
+	UserID       pgtype.UUID `json:"user_id"`
+	LastUsedStep int64       `json:"last_used_step"`
+}
+
+func (q *Queries) ConfirmUserTOTP(ctx context.Context, arg ConfirmUserTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRow(ctx, confirmUserTOTP, arg.UserID, arg.LastUsedStep)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.EncryptedSecret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countUserActiveTasks = `-- name: CountUserActiveTasks :one
+SELECT COUNT(*) FROM task WHERE user_id = $1 AND deleted_at IS NULL AND NOT checked
+`
+
+func (q *Queries) CountUserActiveTasks(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserActiveTasks, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserPersonalAccessTokens = `-- name: CountUserPersonalAccessTokens :one
+SELECT COUNT(*) FROM personal_access_token WHERE user_id = $1
+`
+
+func (q *Queries) CountUserPersonalAccessTokens(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserPersonalAccessTokens, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserRecoveryCodes = `-- name: CountUserRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_code WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) CountUserRecoveryCodes(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const decrementCouponQuota = `-- name: DecrementCouponQuota :execrows
 UPDATE coupon SET quota = quota - 1
 WHERE code = $1 AND quota > 0 AND deleted_at IS NULL
@@ -24,6 +80,108 @@ func (q *Queries) DecrementCouponQuota(ctx context.Context, code string) (int64,
 	return result.RowsAffected(), nil
 }
 
+const deleteCoupon = `-- name: DeleteCoupon :execrows
+UPDATE coupon SET deleted_at = NOW() WHERE code = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteCoupon(ctx context.Context, code string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCoupon, code)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLoginThrottle = `-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttle WHERE scope = $1 AND subject = $2
+`
+
+type DeleteLoginThrottleParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) error {
+	_, err := q.db.Exec(ctx, deleteLoginThrottle, arg.Scope, arg.Subject)
+	return err
+}
+
+const deletePlan = `-- name: DeletePlan :execrows
+UPDATE plan SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) DeletePlan(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePlan, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteStaleLoginThrottles = `-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM login_throttle
+WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < $1)
+`
+
+func (q *Queries) DeleteStaleLoginThrottles(ctx context.Context, lastFailedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleLoginThrottles, lastFailedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTaskList = `-- name: DeleteTaskList :execrows
+DELETE FROM task_list WHERE id = $1 AND owner_id = $2
+`
+
+type DeleteTaskListParams struct {
+	ID      pgtype.UUID `json:"id"`
+	OwnerID pgtype.UUID `json:"owner_id"`
+}
+
+func (q *Queries) DeleteTaskList(ctx context.Context, arg DeleteTaskListParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTaskList, arg.ID, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTaskListMember = `-- name: DeleteTaskListMember :execrows
+DELETE FROM task_list_member WHERE task_list_id = $1 AND user_id = $2 AND role <> 'owner'
+`
+
+type DeleteTaskListMemberParams struct {
+	TaskListID pgtype.UUID `json:"task_list_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteTaskListMember(ctx context.Context, arg DeleteTaskListMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTaskListMember, arg.TaskListID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTaskListTask = `-- name: DeleteTaskListTask :execrows
+UPDATE task SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND list_id = $2 AND deleted_at IS NULL
+`
+
+type DeleteTaskListTaskParams struct {
+	ID     pgtype.UUID `json:"id"`
+	ListID pgtype.UUID `json:"list_id"`
+}
+
+func (q *Queries) DeleteTaskListTask(ctx context.Context, arg DeleteTaskListTaskParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTaskListTask, arg.ID, arg.ListID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM "user" WHERE id = $1
 `
@@ -33,8 +191,84 @@ func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteUserLabel = `-- name: DeleteUserLabel :execrows
+DELETE FROM label WHERE id = $1 AND user_id = $2
+`
+
+type DeleteUserLabelParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserLabel(ctx context.Context, arg DeleteUserLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserLabel, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserPasswordResetTokens = `-- name: DeleteUserPasswordResetTokens :exec
+DELETE FROM password_reset_token WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserPasswordResetTokens(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserPasswordResetTokens, userID)
+	return err
+}
+
+const deleteUserPersonalAccessToken = `-- name: DeleteUserPersonalAccessToken :execrows
+DELETE FROM personal_access_token WHERE id = $1 AND user_id = $2
+`
+
+type DeleteUserPersonalAccessTokenParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserPersonalAccessToken(ctx context.Context, arg DeleteUserPersonalAccessTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserPersonalAccessToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserPersonalAccessTokens = `-- name: DeleteUserPersonalAccessTokens :execrows
+DELETE FROM personal_access_token WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserPersonalAccessTokens(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserPersonalAccessTokens, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserRecoveryCodes = `-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM recovery_code WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserRecoveryCodes(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserRecoveryCodes, userID)
+	return err
+}
+
+const deleteUserTOTP = `-- name: DeleteUserTOTP :execrows
+DELETE FROM user_totp WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserTOTP, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteUserTask = `-- name: DeleteUserTask :execrows
-DELETE FROM task WHERE id = $1 AND user_id = $2
+UPDATE task SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL
 `
 
 type DeleteUserTaskParams struct {
@@ -50,6 +284,46 @@ func (q *Queries) DeleteUserTask(ctx context.Context, arg DeleteUserTaskParams)
 	return result.RowsAffected(), nil
 }
 
+const deleteUserTaskItem = `-- name: DeleteUserTaskItem :execrows
+DELETE FROM task_item
+USING task
+WHERE task_item.id = $1 AND task_item.task_id = $2 AND task.id = task_item.task_id AND task.user_id = $3 AND task.list_id IS NULL AND task.deleted_at IS NULL
+`
+
+type DeleteUserTaskItemParams struct {
+	ID     pgtype.UUID `json:"id"`
+	TaskID pgtype.UUID `json:"task_id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserTaskItem(ctx context.Context, arg DeleteUserTaskItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserTaskItem, arg.ID, arg.TaskID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserTaskLabel = `-- name: DeleteUserTaskLabel :execrows
+DELETE FROM task_label
+USING task
+WHERE task_label.task_id = $1 AND task_label.label_id = $2 AND task.id = task_label.task_id AND task.user_id = $3 AND task.list_id IS NULL AND task.deleted_at IS NULL
+`
+
+type DeleteUserTaskLabelParams struct {
+	TaskID  pgtype.UUID `json:"task_id"`
+	LabelID pgtype.UUID `json:"label_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserTaskLabel(ctx context.Context, arg DeleteUserTaskLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserTaskLabel, arg.TaskID, arg.LabelID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const incrementCouponQuota = `-- name: IncrementCouponQuota :exec
 UPDATE coupon SET quota = quota + 1 WHERE code = $1
 `
@@ -59,6 +333,44 @@ func (q *Queries) IncrementCouponQuota(ctx context.Context, code string) error {
 	return err
 }
 
+const incrementLoginThrottle = `-- name: IncrementLoginThrottle :one
+INSERT INTO login_throttle (scope, subject, failed_attempts, last_failed_at)
+VALUES ($1, $2, 1, $3)
+ON CONFLICT (scope, subject) DO UPDATE
+SET
+  failed_attempts = CASE
+    WHEN login_throttle.last_failed_at < $4 THEN 1
+    ELSE login_throttle.failed_attempts + 1
+  END,
+  last_failed_at = EXCLUDED.last_failed_at
+RETURNING scope, subject, failed_attempts, last_failed_at, locked_until
+`
+
+type IncrementLoginThrottleParams struct {
+	Scope          string             `json:"scope"`
+	Subject        string             `json:"subject"`
+	LastFailedAt   pgtype.Timestamptz `json:"last_failed_at"`
+	WindowStartsAt pgtype.Timestamptz `json:"window_starts_at"`
+}
+
+func (q *Queries) IncrementLoginThrottle(ctx context.Context, arg IncrementLoginThrottleParams) (LoginThrottle, error) {
+	row := q.db.QueryRow(ctx, incrementLoginThrottle,
+		arg.Scope,
+		arg.Subject,
+		arg.LastFailedAt,
+		arg.WindowStartsAt,
+	)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Scope,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
 const insertCoupon = `-- name: InsertCoupon :one
 INSERT INTO coupon (code, influencer_username, quota)
 VALUES ($1, $2, $3) RETURNING code, influencer_username, quota, created_at, deleted_at
@@ -83,6 +395,62 @@ func (q *Queries) InsertCoupon(ctx context.Context, arg InsertCouponParams) (Cou
 	return i, err
 }
 
+const insertLoginThrottle = `-- name: InsertLoginThrottle :exec
+INSERT INTO login_throttle (scope, subject, last_failed_at)
+VALUES ($1, $2, '-infinity')
+ON CONFLICT (scope, subject) DO NOTHING
+`
+
+type InsertLoginThrottleParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) InsertLoginThrottle(ctx context.Context, arg InsertLoginThrottleParams) error {
+	_, err := q.db.Exec(ctx, insertLoginThrottle, arg.Scope, arg.Subject)
+	return err
+}
+
+const insertPersonalAccessToken = `-- name: InsertPersonalAccessToken :one
+INSERT INTO personal_access_token (id, user_id, name, token_prefix, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at
+`
+
+type InsertPersonalAccessTokenParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	Name        string             `json:"name"`
+	TokenPrefix string             `json:"token_prefix"`
+	TokenHash   string             `json:"token_hash"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) InsertPersonalAccessToken(ctx context.Context, arg InsertPersonalAccessTokenParams) (PersonalAccessToken, error) {
+	row := q.db.QueryRow(ctx, insertPersonalAccessToken,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.TokenPrefix,
+		arg.TokenHash,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const insertPlan = `-- name: InsertPlan :one
 INSERT INTO plan (id, type, name, price, duration_in_months)
 VALUES ($1, $2, $3, $4, $5) RETURNING id, type, name, price, duration_in_months, created_at, deleted_at
@@ -117,79 +485,276 @@ func (q *Queries) InsertPlan(ctx context.Context, arg InsertPlanParams) (Plan, e
 	return i, err
 }
 
-const insertUser = `-- name: InsertUser :one
-INSERT INTO "user" (id, email, password, name, coordinates, city, timezone)
-VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, email, password, name, coordinates, city, timezone, created_at
+const insertTaskItem = `-- name: InsertTaskItem :one
+INSERT INTO task_item (id, task_id, name, position)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position) + 1, 0) FROM task_item WHERE task_id = $2))
+RETURNING id, task_id, name, checked, position
 `
 
-type InsertUserParams struct {
-	ID          pgtype.UUID  `json:"id"`
-	Email       string       `json:"email"`
-	Password    string       `json:"password"`
-	Name        string       `json:"name"`
-	Coordinates pgtype.Point `json:"coordinates"`
-	City        string       `json:"city"`
-	Timezone    string       `json:"timezone"`
+type InsertTaskItemParams struct {
+	ID     pgtype.UUID `json:"id"`
+	TaskID pgtype.UUID `json:"task_id"`
+	Name   string      `json:"name"`
 }
 
-func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, insertUser,
-		arg.ID,
-		arg.Email,
-		arg.Password,
-		arg.Name,
-		arg.Coordinates,
-		arg.City,
-		arg.Timezone,
-	)
-	var i User
+func (q *Queries) InsertTaskItem(ctx context.Context, arg InsertTaskItemParams) (TaskItem, error) {
+	row := q.db.QueryRow(ctx, insertTaskItem, arg.ID, arg.TaskID, arg.Name)
+	var i TaskItem
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Password,
+		&i.TaskID,
 		&i.Name,
-		&i.Coordinates,
-		&i.City,
-		&i.Timezone,
-		&i.CreatedAt,
+		&i.Checked,
+		&i.Position,
 	)
 	return i, err
 }
 
-const insertUserInvoice = `-- name: InsertUserInvoice :one
-INSERT INTO invoice (id, user_id, plan_id, ref_id, coupon_code, total_amount, qr_url, expires_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, user_id, plan_id, ref_id, coupon_code, total_amount, qr_url, expires_at, created_at
+const insertTaskList = `-- name: InsertTaskList :one
+INSERT INTO task_list (id, owner_id, name) VALUES ($1, $2, $3) RETURNING id, owner_id, name, created_at
 `
 
-type InsertUserInvoiceParams struct {
-	ID          pgtype.UUID        `json:"id"`
-	UserID      pgtype.UUID        `json:"user_id"`
-	PlanID      pgtype.UUID        `json:"plan_id"`
-	RefID       string             `json:"ref_id"`
-	CouponCode  pgtype.Text        `json:"coupon_code"`
-	TotalAmount int32              `json:"total_amount"`
-	QrUrl       string             `json:"qr_url"`
-	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+type InsertTaskListParams struct {
+	ID      pgtype.UUID `json:"id"`
+	OwnerID pgtype.UUID `json:"owner_id"`
+	Name    string      `json:"name"`
 }
 
-func (q *Queries) InsertUserInvoice(ctx context.Context, arg InsertUserInvoiceParams) (Invoice, error) {
-	row := q.db.QueryRow(ctx, insertUserInvoice,
-		arg.ID,
-		arg.UserID,
-		arg.PlanID,
-		arg.RefID,
-		arg.CouponCode,
-		arg.TotalAmount,
-		arg.QrUrl,
-		arg.ExpiresAt,
-	)
-	var i Invoice
+func (q *Queries) InsertTaskList(ctx context.Context, arg InsertTaskListParams) (TaskList, error) {
+	row := q.db.QueryRow(ctx, insertTaskList, arg.ID, arg.OwnerID, arg.Name)
+	var i TaskList
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
-		&i.PlanID,
-		&i.RefID,
-		&i.CouponCode,
+		&i.OwnerID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertTaskListMember = `-- name: InsertTaskListMember :one
+INSERT INTO task_list_member (task_list_id, user_id, role) VALUES ($1, $2, $3) RETURNING task_list_id, user_id, role, joined_at
+`
+
+type InsertTaskListMemberParams struct {
+	TaskListID pgtype.UUID `json:"task_list_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+	Role       string      `json:"role"`
+}
+
+func (q *Queries) InsertTaskListMember(ctx context.Context, arg InsertTaskListMemberParams) (TaskListMember, error) {
+	row := q.db.QueryRow(ctx, insertTaskListMember, arg.TaskListID, arg.UserID, arg.Role)
+	var i TaskListMember
+	err := row.Scan(
+		&i.TaskListID,
+		&i.UserID,
+		&i.Role,
+		&i.JoinedAt,
+	)
+	return i, err
+}
+
+const insertTaskListTask = `-- name: InsertTaskListTask :one
+INSERT INTO task (id, user_id, list_id, name, description, assignee_id, due_at, priority, position)
+VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8,
+  (SELECT COALESCE(MAX(position), 0) + 1024 FROM task WHERE list_id = $3)
+)
+RETURNING id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector
+`
+
+type InsertTaskListTaskParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	ListID      pgtype.UUID        `json:"list_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	AssigneeID  pgtype.UUID        `json:"assignee_id"`
+	DueAt       pgtype.Timestamptz `json:"due_at"`
+	Priority    int16              `json:"priority"`
+}
+
+func (q *Queries) InsertTaskListTask(ctx context.Context, arg InsertTaskListTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, insertTaskListTask,
+		arg.ID,
+		arg.UserID,
+		arg.ListID,
+		arg.Name,
+		arg.Description,
+		arg.AssigneeID,
+		arg.DueAt,
+		arg.Priority,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const insertUser = `-- name: InsertUser :one
+INSERT INTO "user" (id, email, password, name, coordinates, city, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, email, password, name, coordinates, city, timezone, email_verified_at, role, created_at
+`
+
+type InsertUserParams struct {
+	ID          pgtype.UUID  `json:"id"`
+	Email       string       `json:"email"`
+	Password    string       `json:"password"`
+	Name        string       `json:"name"`
+	Coordinates pgtype.Point `json:"coordinates"`
+	City        string       `json:"city"`
+	Timezone    string       `json:"timezone"`
+}
+
+func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, insertUser,
+		arg.ID,
+		arg.Email,
+		arg.Password,
+		arg.Name,
+		arg.Coordinates,
+		arg.City,
+		arg.Timezone,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertUserFocusSession = `-- name: InsertUserFocusSession :one
+INSERT INTO focus_session (id, user_id, task_id, planned_duration_in_minutes, started_at, resumed_at)
+VALUES ($1, $2, $3, $4, $5, $5)
+RETURNING id, user_id, task_id, planned_duration_in_minutes, status, focused_seconds, paused_for_prayer, started_at, resumed_at, ended_at
+`
+
+type InsertUserFocusSessionParams struct {
+	ID                       pgtype.UUID        `json:"id"`
+	UserID                   pgtype.UUID        `json:"user_id"`
+	TaskID                   pgtype.UUID        `json:"task_id"`
+	PlannedDurationInMinutes int16              `json:"planned_duration_in_minutes"`
+	StartedAt                pgtype.Timestamptz `json:"started_at"`
+}
+
+func (q *Queries) InsertUserFocusSession(ctx context.Context, arg InsertUserFocusSessionParams) (FocusSession, error) {
+	row := q.db.QueryRow(ctx, insertUserFocusSession,
+		arg.ID,
+		arg.UserID,
+		arg.TaskID,
+		arg.PlannedDurationInMinutes,
+		arg.StartedAt,
+	)
+	var i FocusSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.PlannedDurationInMinutes,
+		&i.Status,
+		&i.FocusedSeconds,
+		&i.PausedForPrayer,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const insertUserIdentity = `-- name: InsertUserIdentity :one
+INSERT INTO user_identity (provider, subject, user_id, email)
+VALUES ($1, $2, $3, $4) RETURNING provider, subject, user_id, email, created_at
+`
+
+type InsertUserIdentityParams struct {
+	Provider string      `json:"provider"`
+	Subject  string      `json:"subject"`
+	UserID   pgtype.UUID `json:"user_id"`
+	Email    string      `json:"email"`
+}
+
+func (q *Queries) InsertUserIdentity(ctx context.Context, arg InsertUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRow(ctx, insertUserIdentity,
+		arg.Provider,
+		arg.Subject,
+		arg.UserID,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.Provider,
+		&i.Subject,
+		&i.UserID,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertUserInvoice = `-- name: InsertUserInvoice :one
+INSERT INTO invoice (id, user_id, plan_id, ref_id, coupon_code, total_amount, qr_url, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, user_id, plan_id, ref_id, coupon_code, total_amount, qr_url, expires_at, created_at
+`
+
+type InsertUserInvoiceParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	PlanID      pgtype.UUID        `json:"plan_id"`
+	RefID       string             `json:"ref_id"`
+	CouponCode  pgtype.Text        `json:"coupon_code"`
+	TotalAmount int32              `json:"total_amount"`
+	QrUrl       string             `json:"qr_url"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) InsertUserInvoice(ctx context.Context, arg InsertUserInvoiceParams) (Invoice, error) {
+	row := q.db.QueryRow(ctx, insertUserInvoice,
+		arg.ID,
+		arg.UserID,
+		arg.PlanID,
+		arg.RefID,
+		arg.CouponCode,
+		arg.TotalAmount,
+		arg.QrUrl,
+		arg.ExpiresAt,
+	)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.RefID,
+		&i.CouponCode,
 		&i.TotalAmount,
 		&i.QrUrl,
 		&i.ExpiresAt,
@@ -198,6 +763,66 @@ func (q *Queries) InsertUserInvoice(ctx context.Context, arg InsertUserInvoicePa
 	return i, err
 }
 
+const insertUserLabel = `-- name: InsertUserLabel :one
+INSERT INTO label (id, user_id, name, color) VALUES ($1, $2, $3, $4) RETURNING id, user_id, name, color, created_at
+`
+
+type InsertUserLabelParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+	Name   string      `json:"name"`
+	Color  string      `json:"color"`
+}
+
+func (q *Queries) InsertUserLabel(ctx context.Context, arg InsertUserLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, insertUserLabel,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Color,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertUserPasswordResetToken = `-- name: InsertUserPasswordResetToken :one
+INSERT INTO password_reset_token (id, user_id, token_hash, expires_at)
+VALUES ($1, $2, $3, $4) RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+`
+
+type InsertUserPasswordResetTokenParams struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) InsertUserPasswordResetToken(ctx context.Context, arg InsertUserPasswordResetTokenParams) (PasswordResetToken, error) {
+	row := q.db.QueryRow(ctx, insertUserPasswordResetToken,
+		arg.ID,
+		arg.UserID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const insertUserPayment = `-- name: InsertUserPayment :one
 INSERT INTO payment (id, user_id, invoice_id, amount_paid, status)
 VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, invoice_id, amount_paid, status, created_at
@@ -240,25 +865,56 @@ type InsertUserPrayersParams struct {
 	Day    int16       `json:"day"`
 }
 
+type InsertUserRecoveryCodesParams struct {
+	ID       pgtype.UUID `json:"id"`
+	UserID   pgtype.UUID `json:"user_id"`
+	CodeHash string      `json:"code_hash"`
+}
+
 const insertUserRefreshToken = `-- name: InsertUserRefreshToken :one
-INSERT INTO refresh_token (id, user_id, expires_at)
-VALUES ($1, $2, $3) RETURNING id, user_id, revoked, expires_at
+INSERT INTO refresh_token (id, user_id, expires_at, family_id, parent_id, device_name, user_agent, ip_address, created_at, last_used_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, user_id, revoked, expires_at, family_id, parent_id, device_name, user_agent, ip_address, created_at, last_used_at
 `
 
 type InsertUserRefreshTokenParams struct {
-	ID        pgtype.UUID        `json:"id"`
-	UserID    pgtype.UUID        `json:"user_id"`
-	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	FamilyID   pgtype.UUID        `json:"family_id"`
+	ParentID   pgtype.UUID        `json:"parent_id"`
+	DeviceName string             `json:"device_name"`
+	UserAgent  string             `json:"user_agent"`
+	IpAddress  string             `json:"ip_address"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
 }
 
 func (q *Queries) InsertUserRefreshToken(ctx context.Context, arg InsertUserRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRow(ctx, insertUserRefreshToken, arg.ID, arg.UserID, arg.ExpiresAt)
+	row := q.db.QueryRow(ctx, insertUserRefreshToken,
+		arg.ID,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.ParentID,
+		arg.DeviceName,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.CreatedAt,
+		arg.LastUsedAt,
+	)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.Revoked,
 		&i.ExpiresAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.DeviceName,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.CreatedAt,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
@@ -299,15 +955,27 @@ func (q *Queries) InsertUserSubscription(ctx context.Context, arg InsertUserSubs
 }
 
 const insertUserTask = `-- name: InsertUserTask :one
-INSERT INTO task (id, user_id, name, description)
-VALUES ($1, $2, $3, $4) RETURNING id, user_id, name, description, checked
+INSERT INTO task (id, user_id, name, description, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, estimated_duration_in_minutes, position)
+VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+  (SELECT COALESCE(MAX(position), 0) + 1024 FROM task WHERE user_id = $2 AND list_id IS NULL)
+)
+RETURNING id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector
 `
 
 type InsertUserTaskParams struct {
-	ID          pgtype.UUID `json:"id"`
-	UserID      pgtype.UUID `json:"user_id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
+	ID                         pgtype.UUID        `json:"id"`
+	UserID                     pgtype.UUID        `json:"user_id"`
+	Name                       string             `json:"name"`
+	Description                string             `json:"description"`
+	AnchorPrayer               pgtype.Text        `json:"anchor_prayer"`
+	AnchorRelation             pgtype.Text        `json:"anchor_relation"`
+	AnchorOffsetInMinutes      int16              `json:"anchor_offset_in_minutes"`
+	RecurrenceRule             pgtype.Text        `json:"recurrence_rule"`
+	RecurrenceStart            pgtype.Date        `json:"recurrence_start"`
+	DueAt                      pgtype.Timestamptz `json:"due_at"`
+	Priority                   int16              `json:"priority"`
+	EstimatedDurationInMinutes pgtype.Int2        `json:"estimated_duration_in_minutes"`
 }
 
 func (q *Queries) InsertUserTask(ctx context.Context, arg InsertUserTaskParams) (Task, error) {
@@ -316,6 +984,14 @@ func (q *Queries) InsertUserTask(ctx context.Context, arg InsertUserTaskParams)
 		arg.UserID,
 		arg.Name,
 		arg.Description,
+		arg.AnchorPrayer,
+		arg.AnchorRelation,
+		arg.AnchorOffsetInMinutes,
+		arg.RecurrenceRule,
+		arg.RecurrenceStart,
+		arg.DueAt,
+		arg.Priority,
+		arg.EstimatedDurationInMinutes,
 	)
 	var i Task
 	err := row.Scan(
@@ -324,83 +1000,411 @@ func (q *Queries) InsertUserTask(ctx context.Context, arg InsertUserTaskParams)
 		&i.Name,
 		&i.Description,
 		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
 	)
 	return i, err
 }
 
-const revokeUserRefreshToken = `-- name: RevokeUserRefreshToken :one
-UPDATE refresh_token SET revoked = TRUE
-WHERE id = $1 AND user_id = $2 RETURNING id, user_id, revoked, expires_at
+const insertUserTaskLabel = `-- name: InsertUserTaskLabel :execrows
+INSERT INTO task_label (task_id, label_id)
+SELECT t.id, l.id
+FROM task t
+JOIN label l ON l.user_id = t.user_id
+WHERE t.id = $1 AND l.id = $2 AND t.user_id = $3 AND t.list_id IS NULL AND t.deleted_at IS NULL
+ON CONFLICT (task_id, label_id) DO UPDATE SET label_id = EXCLUDED.label_id
 `
 
-type RevokeUserRefreshTokenParams struct {
-	ID     pgtype.UUID `json:"id"`
-	UserID pgtype.UUID `json:"user_id"`
+type InsertUserTaskLabelParams struct {
+	TaskID  pgtype.UUID `json:"task_id"`
+	LabelID pgtype.UUID `json:"label_id"`
+	UserID  pgtype.UUID `json:"user_id"`
 }
 
-func (q *Queries) RevokeUserRefreshToken(ctx context.Context, arg RevokeUserRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRow(ctx, revokeUserRefreshToken, arg.ID, arg.UserID)
-	var i RefreshToken
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Revoked,
-		&i.ExpiresAt,
-	)
-	return i, err
+func (q *Queries) InsertUserTaskLabel(ctx context.Context, arg InsertUserTaskLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertUserTaskLabel, arg.TaskID, arg.LabelID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const selectCoupon = `-- name: SelectCoupon :one
-SELECT code, influencer_username, quota, created_at, deleted_at FROM coupon WHERE code = $1
+const lockLoginThrottle = `-- name: LockLoginThrottle :one
+UPDATE login_throttle SET failed_attempts = 0, locked_until = $3
+WHERE scope = $1 AND subject = $2 RETURNING scope, subject, failed_attempts, last_failed_at, locked_until
 `
 
-func (q *Queries) SelectCoupon(ctx context.Context, code string) (Coupon, error) {
-	row := q.db.QueryRow(ctx, selectCoupon, code)
-	var i Coupon
+type LockLoginThrottleParams struct {
+	Scope       string             `json:"scope"`
+	Subject     string             `json:"subject"`
+	LockedUntil pgtype.Timestamptz `json:"locked_until"`
+}
+
+func (q *Queries) LockLoginThrottle(ctx context.Context, arg LockLoginThrottleParams) (LoginThrottle, error) {
+	row := q.db.QueryRow(ctx, lockLoginThrottle, arg.Scope, arg.Subject, arg.LockedUntil)
+	var i LoginThrottle
 	err := row.Scan(
-		&i.Code,
-		&i.InfluencerUsername,
-		&i.Quota,
-		&i.CreatedAt,
-		&i.DeletedAt,
+		&i.Scope,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
 	)
 	return i, err
 }
 
-const selectPlan = `-- name: SelectPlan :one
-SELECT id, type, name, price, duration_in_months, created_at, deleted_at FROM plan WHERE id = $1 AND deleted_at IS NULL
+const lockUserTasks = `-- name: LockUserTasks :exec
+SELECT id FROM "user" WHERE id = $1 FOR NO KEY UPDATE
 `
 
-func (q *Queries) SelectPlan(ctx context.Context, id pgtype.UUID) (Plan, error) {
-	row := q.db.QueryRow(ctx, selectPlan, id)
-	var i Plan
-	err := row.Scan(
-		&i.ID,
-		&i.Type,
-		&i.Name,
-		&i.Price,
-		&i.DurationInMonths,
-		&i.CreatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+func (q *Queries) LockUserTasks(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockUserTasks, id)
+	return err
 }
 
-const selectPlanByInvoiceId = `-- name: SelectPlanByInvoiceId :one
-SELECT p.id, p.type, p.name, p.price, p.duration_in_months, p.created_at, p.deleted_at FROM invoice i JOIN plan p ON i.plan_id = p.id WHERE i.id = $1
+const purgeTrashedTasks = `-- name: PurgeTrashedTasks :execrows
+DELETE FROM task WHERE deleted_at < $1::timestamptz
 `
 
-func (q *Queries) SelectPlanByInvoiceId(ctx context.Context, id pgtype.UUID) (Plan, error) {
-	row := q.db.QueryRow(ctx, selectPlanByInvoiceId, id)
-	var i Plan
-	err := row.Scan(
-		&i.ID,
-		&i.Type,
-		&i.Name,
-		&i.Price,
-		&i.DurationInMonths,
-		&i.CreatedAt,
-		&i.DeletedAt,
+func (q *Queries) PurgeTrashedTasks(ctx context.Context, deletedBefore pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeTrashedTasks, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rebalanceUserTaskPositions = `-- name: RebalanceUserTaskPositions :exec
+UPDATE task
+SET position = ranked.rank * 1024
+FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS rank
+  FROM task
+  WHERE user_id = $1 AND list_id IS NULL AND deleted_at IS NULL
+) ranked
+WHERE task.id = ranked.id
+`
+
+func (q *Queries) RebalanceUserTaskPositions(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, rebalanceUserTaskPositions, userID)
+	return err
+}
+
+const restoreUserTask = `-- name: RestoreUserTask :one
+UPDATE task SET deleted_at = NULL WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NOT NULL RETURNING id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector
+`
+
+type RestoreUserTaskParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) RestoreUserTask(ctx context.Context, arg RestoreUserTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, restoreUserTask, arg.ID, arg.UserID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const revokeOtherRefreshTokenFamilies = `-- name: RevokeOtherRefreshTokenFamilies :execrows
+UPDATE refresh_token SET revoked = TRUE
+WHERE user_id = $1 AND family_id IS DISTINCT FROM $2 AND NOT revoked
+`
+
+type RevokeOtherRefreshTokenFamiliesParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	FamilyID pgtype.UUID `json:"family_id"`
+}
+
+func (q *Queries) RevokeOtherRefreshTokenFamilies(ctx context.Context, arg RevokeOtherRefreshTokenFamiliesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeOtherRefreshTokenFamilies, arg.UserID, arg.FamilyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :execrows
+UPDATE refresh_token SET revoked = TRUE
+WHERE family_id = $1 AND user_id = $2 AND NOT revoked
+`
+
+type RevokeRefreshTokenFamilyParams struct {
+	FamilyID pgtype.UUID `json:"family_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeRefreshTokenFamily, arg.FamilyID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeUserRefreshToken = `-- name: RevokeUserRefreshToken :one
+UPDATE refresh_token SET revoked = TRUE
+WHERE id = $1 AND user_id = $2 RETURNING id, user_id, revoked, expires_at, family_id, parent_id, device_name, user_agent, ip_address, created_at, last_used_at
+`
+
+type RevokeUserRefreshTokenParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) RevokeUserRefreshToken(ctx context.Context, arg RevokeUserRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, revokeUserRefreshToken, arg.ID, arg.UserID)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Revoked,
+		&i.ExpiresAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.DeviceName,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const revokeUserRefreshTokens = `-- name: RevokeUserRefreshTokens :execrows
+UPDATE refresh_token SET revoked = TRUE WHERE user_id = $1 AND NOT revoked
+`
+
+func (q *Queries) RevokeUserRefreshTokens(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeUserRefreshTokens, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchUserTasks = `-- name: SearchUserTasks :many
+SELECT
+  t.id,
+  ts_rank(t.search_vector, q.query)::float4 AS rank,
+  ts_headline(c.config, replace(replace(replace(t.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>')::text AS name_highlight,
+  ts_headline(c.config, replace(replace(replace(t.description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query, 'MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<mark>, StopSel=</mark>')::text AS description_highlight
+FROM task t
+CROSS JOIN LATERAL (
+  SELECT
+    websearch_to_tsquery('indonesian', $2::text) AS indonesian_query,
+    websearch_to_tsquery('indonesian', $2::text) || websearch_to_tsquery('english', $2::text) AS query
+) q
+CROSS JOIN LATERAL (
+  SELECT (
+    CASE WHEN t.search_vector @@ q.indonesian_query
+      THEN 'indonesian'
+      ELSE 'english'
+    END
+  )::regconfig AS config
+) c
+WHERE t.user_id = $1 AND t.list_id IS NULL AND t.deleted_at IS NULL AND t.search_vector @@ q.query
+ORDER BY rank DESC, t.created_at DESC
+LIMIT $3::int
+`
+
+type SearchUserTasksParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	Query    string      `json:"query"`
+	RowLimit int32       `json:"row_limit"`
+}
+
+type SearchUserTasksRow struct {
+	ID                   pgtype.UUID `json:"id"`
+	Rank                 float32     `json:"rank"`
+	NameHighlight        string      `json:"name_highlight"`
+	DescriptionHighlight string      `json:"description_highlight"`
+}
+
+func (q *Queries) SearchUserTasks(ctx context.Context, arg SearchUserTasksParams) ([]SearchUserTasksRow, error) {
+	rows, err := q.db.Query(ctx, searchUserTasks, arg.UserID, arg.Query, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUserTasksRow
+	for rows.Next() {
+		var i SearchUserTasksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Rank,
+			&i.NameHighlight,
+			&i.DescriptionHighlight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectCoupon = `-- name: SelectCoupon :one
+SELECT code, influencer_username, quota, created_at, deleted_at FROM coupon WHERE code = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SelectCoupon(ctx context.Context, code string) (Coupon, error) {
+	row := q.db.QueryRow(ctx, selectCoupon, code)
+	var i Coupon
+	err := row.Scan(
+		&i.Code,
+		&i.InfluencerUsername,
+		&i.Quota,
+		&i.CreatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectCoupons = `-- name: SelectCoupons :many
+SELECT code, influencer_username, quota, created_at, deleted_at FROM coupon WHERE deleted_at IS NULL ORDER BY created_at DESC
+`
+
+func (q *Queries) SelectCoupons(ctx context.Context) ([]Coupon, error) {
+	rows, err := q.db.Query(ctx, selectCoupons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Coupon
+	for rows.Next() {
+		var i Coupon
+		if err := rows.Scan(
+			&i.Code,
+			&i.InfluencerUsername,
+			&i.Quota,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectLoginThrottle = `-- name: SelectLoginThrottle :one
+SELECT scope, subject, failed_attempts, last_failed_at, locked_until FROM login_throttle WHERE scope = $1 AND subject = $2 FOR UPDATE
+`
+
+type SelectLoginThrottleParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) SelectLoginThrottle(ctx context.Context, arg SelectLoginThrottleParams) (LoginThrottle, error) {
+	row := q.db.QueryRow(ctx, selectLoginThrottle, arg.Scope, arg.Subject)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Scope,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const selectNextTaskPosition = `-- name: SelectNextTaskPosition :one
+SELECT position FROM task
+WHERE user_id = $1 AND list_id IS NULL AND id <> $2 AND position > $3 AND deleted_at IS NULL
+ORDER BY position
+LIMIT 1
+`
+
+type SelectNextTaskPositionParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	ExcludedID pgtype.UUID `json:"excluded_id"`
+	Position   int64       `json:"position"`
+}
+
+func (q *Queries) SelectNextTaskPosition(ctx context.Context, arg SelectNextTaskPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, selectNextTaskPosition, arg.UserID, arg.ExcludedID, arg.Position)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
+const selectPlan = `-- name: SelectPlan :one
+SELECT id, type, name, price, duration_in_months, created_at, deleted_at FROM plan WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SelectPlan(ctx context.Context, id pgtype.UUID) (Plan, error) {
+	row := q.db.QueryRow(ctx, selectPlan, id)
+	var i Plan
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Name,
+		&i.Price,
+		&i.DurationInMonths,
+		&i.CreatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectPlanByInvoiceId = `-- name: SelectPlanByInvoiceId :one
+SELECT p.id, p.type, p.name, p.price, p.duration_in_months, p.created_at, p.deleted_at FROM invoice i JOIN plan p ON i.plan_id = p.id WHERE i.id = $1
+`
+
+func (q *Queries) SelectPlanByInvoiceId(ctx context.Context, id pgtype.UUID) (Plan, error) {
+	row := q.db.QueryRow(ctx, selectPlanByInvoiceId, id)
+	var i Plan
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Name,
+		&i.Price,
+		&i.DurationInMonths,
+		&i.CreatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -415,17 +1419,930 @@ func (q *Queries) SelectPlans(ctx context.Context) ([]Plan, error) {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Plan
+	var items []Plan
+	for rows.Next() {
+		var i Plan
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Name,
+			&i.Price,
+			&i.DurationInMonths,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectPreviousTaskPosition = `-- name: SelectPreviousTaskPosition :one
+SELECT position FROM task
+WHERE user_id = $1 AND list_id IS NULL AND id <> $2 AND position < $3 AND deleted_at IS NULL
+ORDER BY position DESC
+LIMIT 1
+`
+
+type SelectPreviousTaskPositionParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	ExcludedID pgtype.UUID `json:"excluded_id"`
+	Position   int64       `json:"position"`
+}
+
+func (q *Queries) SelectPreviousTaskPosition(ctx context.Context, arg SelectPreviousTaskPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, selectPreviousTaskPosition, arg.UserID, arg.ExcludedID, arg.Position)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
+const selectSubscribedPlan = `-- name: SelectSubscribedPlan :one
+SELECT id, type, name, price, duration_in_months, created_at, deleted_at FROM plan WHERE id = $1
+`
+
+func (q *Queries) SelectSubscribedPlan(ctx context.Context, id pgtype.UUID) (Plan, error) {
+	row := q.db.QueryRow(ctx, selectSubscribedPlan, id)
+	var i Plan
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Name,
+		&i.Price,
+		&i.DurationInMonths,
+		&i.CreatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectTaskItems = `-- name: SelectTaskItems :many
+SELECT id, task_id, name, checked, position FROM task_item WHERE task_id = $1 ORDER BY position
+`
+
+func (q *Queries) SelectTaskItems(ctx context.Context, taskID pgtype.UUID) ([]TaskItem, error) {
+	rows, err := q.db.Query(ctx, selectTaskItems, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskItem
+	for rows.Next() {
+		var i TaskItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.Name,
+			&i.Checked,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTaskListMember = `-- name: SelectTaskListMember :one
+SELECT task_list_id, user_id, role, joined_at FROM task_list_member WHERE task_list_id = $1 AND user_id = $2
+`
+
+type SelectTaskListMemberParams struct {
+	TaskListID pgtype.UUID `json:"task_list_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) SelectTaskListMember(ctx context.Context, arg SelectTaskListMemberParams) (TaskListMember, error) {
+	row := q.db.QueryRow(ctx, selectTaskListMember, arg.TaskListID, arg.UserID)
+	var i TaskListMember
+	err := row.Scan(
+		&i.TaskListID,
+		&i.UserID,
+		&i.Role,
+		&i.JoinedAt,
+	)
+	return i, err
+}
+
+const selectTaskListMembers = `-- name: SelectTaskListMembers :many
+SELECT m.task_list_id, m.user_id, m.role, m.joined_at, u.email, u.name
+FROM task_list_member m
+JOIN "user" u ON u.id = m.user_id
+WHERE m.task_list_id = $1
+ORDER BY m.joined_at
+`
+
+type SelectTaskListMembersRow struct {
+	TaskListMember TaskListMember `json:"task_list_member"`
+	Email          string         `json:"email"`
+	Name           string         `json:"name"`
+}
+
+func (q *Queries) SelectTaskListMembers(ctx context.Context, taskListID pgtype.UUID) ([]SelectTaskListMembersRow, error) {
+	rows, err := q.db.Query(ctx, selectTaskListMembers, taskListID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectTaskListMembersRow
+	for rows.Next() {
+		var i SelectTaskListMembersRow
+		if err := rows.Scan(
+			&i.TaskListMember.TaskListID,
+			&i.TaskListMember.UserID,
+			&i.TaskListMember.Role,
+			&i.TaskListMember.JoinedAt,
+			&i.Email,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTaskListTask = `-- name: SelectTaskListTask :one
+SELECT id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector FROM task WHERE id = $1 AND list_id = $2 AND deleted_at IS NULL
+`
+
+type SelectTaskListTaskParams struct {
+	ID     pgtype.UUID `json:"id"`
+	ListID pgtype.UUID `json:"list_id"`
+}
+
+func (q *Queries) SelectTaskListTask(ctx context.Context, arg SelectTaskListTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, selectTaskListTask, arg.ID, arg.ListID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const selectTaskListTasks = `-- name: SelectTaskListTasks :many
+SELECT id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector FROM task WHERE list_id = $1 AND deleted_at IS NULL ORDER BY position, id
+`
+
+func (q *Queries) SelectTaskListTasks(ctx context.Context, listID pgtype.UUID) ([]Task, error) {
+	rows, err := q.db.Query(ctx, selectTaskListTasks, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Checked,
+			&i.AnchorPrayer,
+			&i.AnchorRelation,
+			&i.AnchorOffsetInMinutes,
+			&i.RecurrenceRule,
+			&i.RecurrenceStart,
+			&i.DueAt,
+			&i.Priority,
+			&i.Position,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ListID,
+			&i.AssigneeID,
+			&i.CompletedBy,
+			&i.EstimatedDurationInMinutes,
+			&i.CompletedAt,
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTaskOccurrences = `-- name: SelectTaskOccurrences :many
+SELECT task_id, occurrence_date, name, description, checked, skipped FROM task_occurrence
+WHERE task_id = $1 AND occurrence_date BETWEEN $2::date AND $3::date
+`
+
+type SelectTaskOccurrencesParams struct {
+	TaskID   pgtype.UUID `json:"task_id"`
+	FromDate pgtype.Date `json:"from_date"`
+	ToDate   pgtype.Date `json:"to_date"`
+}
+
+func (q *Queries) SelectTaskOccurrences(ctx context.Context, arg SelectTaskOccurrencesParams) ([]TaskOccurrence, error) {
+	rows, err := q.db.Query(ctx, selectTaskOccurrences, arg.TaskID, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskOccurrence
+	for rows.Next() {
+		var i TaskOccurrence
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.OccurrenceDate,
+			&i.Name,
+			&i.Description,
+			&i.Checked,
+			&i.Skipped,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTasksItemProgress = `-- name: SelectTasksItemProgress :many
+SELECT
+  task_id,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE checked) AS checked
+FROM task_item
+WHERE task_id = ANY($1::uuid[])
+GROUP BY task_id
+`
+
+type SelectTasksItemProgressRow struct {
+	TaskID  pgtype.UUID `json:"task_id"`
+	Total   int64       `json:"total"`
+	Checked int64       `json:"checked"`
+}
+
+func (q *Queries) SelectTasksItemProgress(ctx context.Context, taskIds []pgtype.UUID) ([]SelectTasksItemProgressRow, error) {
+	rows, err := q.db.Query(ctx, selectTasksItemProgress, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectTasksItemProgressRow
+	for rows.Next() {
+		var i SelectTasksItemProgressRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.Total,
+			&i.Checked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTasksLabels = `-- name: SelectTasksLabels :many
+SELECT tl.task_id, l.id, l.user_id, l.name, l.color, l.created_at
+FROM task_label tl
+JOIN label l ON l.id = tl.label_id
+WHERE tl.task_id = ANY($1::uuid[])
+ORDER BY l.name
+`
+
+type SelectTasksLabelsRow struct {
+	TaskID pgtype.UUID `json:"task_id"`
+	Label  Label       `json:"label"`
+}
+
+func (q *Queries) SelectTasksLabels(ctx context.Context, taskIds []pgtype.UUID) ([]SelectTasksLabelsRow, error) {
+	rows, err := q.db.Query(ctx, selectTasksLabels, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectTasksLabelsRow
+	for rows.Next() {
+		var i SelectTasksLabelsRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.Label.ID,
+			&i.Label.UserID,
+			&i.Label.Name,
+			&i.Label.Color,
+			&i.Label.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTasksOccurrencesOn = `-- name: SelectTasksOccurrencesOn :many
+SELECT task_id, occurrence_date, name, description, checked, skipped FROM task_occurrence
+WHERE task_id = ANY($1::uuid[]) AND occurrence_date = $2::date
+`
+
+type SelectTasksOccurrencesOnParams struct {
+	TaskIds        []pgtype.UUID `json:"task_ids"`
+	OccurrenceDate pgtype.Date   `json:"occurrence_date"`
+}
+
+func (q *Queries) SelectTasksOccurrencesOn(ctx context.Context, arg SelectTasksOccurrencesOnParams) ([]TaskOccurrence, error) {
+	rows, err := q.db.Query(ctx, selectTasksOccurrencesOn, arg.TaskIds, arg.OccurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskOccurrence
+	for rows.Next() {
+		var i TaskOccurrence
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.OccurrenceDate,
+			&i.Name,
+			&i.Description,
+			&i.Checked,
+			&i.Skipped,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUser = `-- name: SelectUser :one
+SELECT 
+  u.id, u.email, u.password, u.name, u.coordinates, u.city, u.timezone, u.email_verified_at, u.role, u.created_at, 
+  to_jsonb(s) AS subscription
+FROM "user" u
+LEFT JOIN subscription s ON s.user_id = u.id
+WHERE u.id = $1
+`
+
+type SelectUserRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Email           string             `json:"email"`
+	Password        string             `json:"password"`
+	Name            string             `json:"name"`
+	Coordinates     pgtype.Point       `json:"coordinates"`
+	City            string             `json:"city"`
+	Timezone        string             `json:"timezone"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	Role            string             `json:"role"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	Subscription    []byte             `json:"subscription"`
+}
+
+func (q *Queries) SelectUser(ctx context.Context, id pgtype.UUID) (SelectUserRow, error) {
+	row := q.db.QueryRow(ctx, selectUser, id)
+	var i SelectUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+		&i.Subscription,
+	)
+	return i, err
+}
+
+const selectUserActiveFocusSession = `-- name: SelectUserActiveFocusSession :one
+SELECT id, user_id, task_id, planned_duration_in_minutes, status, focused_seconds, paused_for_prayer, started_at, resumed_at, ended_at FROM focus_session WHERE user_id = $1 AND status <> 'finished' FOR UPDATE
+`
+
+func (q *Queries) SelectUserActiveFocusSession(ctx context.Context, userID pgtype.UUID) (FocusSession, error) {
+	row := q.db.QueryRow(ctx, selectUserActiveFocusSession, userID)
+	var i FocusSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.PlannedDurationInMinutes,
+		&i.Status,
+		&i.FocusedSeconds,
+		&i.PausedForPrayer,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const selectUserActiveInvoice = `-- name: SelectUserActiveInvoice :one
+SELECT i.id, i.user_id, i.plan_id, i.ref_id, i.coupon_code, i.total_amount, i.qr_url, i.expires_at, i.created_at FROM invoice i
+WHERE i.user_id = $1 AND i.expires_at > NOW()
+AND NOT EXISTS (
+    SELECT 1 
+    FROM payment p 
+    WHERE p.invoice_id = i.id
+)
+`
+
+func (q *Queries) SelectUserActiveInvoice(ctx context.Context, userID pgtype.UUID) (Invoice, error) {
+	row := q.db.QueryRow(ctx, selectUserActiveInvoice, userID)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.RefID,
+		&i.CouponCode,
+		&i.TotalAmount,
+		&i.QrUrl,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectUserActiveSubscription = `-- name: SelectUserActiveSubscription :one
+SELECT id, user_id, plan_id, payment_id, start_date, end_date FROM subscription WHERE user_id = $1 AND end_date > NOW() ORDER BY end_date DESC LIMIT 1
+`
+
+func (q *Queries) SelectUserActiveSubscription(ctx context.Context, userID pgtype.UUID) (Subscription, error) {
+	row := q.db.QueryRow(ctx, selectUserActiveSubscription, userID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.PaymentID,
+		&i.StartDate,
+		&i.EndDate,
+	)
+	return i, err
+}
+
+const selectUserByEmail = `-- name: SelectUserByEmail :one
+SELECT id, email, password, name, coordinates, city, timezone, email_verified_at, role, created_at FROM "user" WHERE email = $1
+`
+
+func (q *Queries) SelectUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, selectUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectUserByIdentity = `-- name: SelectUserByIdentity :one
+SELECT u.id, u.email, u.password, u.name, u.coordinates, u.city, u.timezone, u.email_verified_at, u.role, u.created_at FROM user_identity ui JOIN "user" u ON ui.user_id = u.id
+WHERE ui.provider = $1 AND ui.subject = $2
+`
+
+type SelectUserByIdentityParams struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+func (q *Queries) SelectUserByIdentity(ctx context.Context, arg SelectUserByIdentityParams) (User, error) {
+	row := q.db.QueryRow(ctx, selectUserByIdentity, arg.Provider, arg.Subject)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectUserByInvoiceId = `-- name: SelectUserByInvoiceId :one
+SELECT u.id, u.email, u.password, u.name, u.coordinates, u.city, u.timezone, u.email_verified_at, u.role, u.created_at FROM invoice i JOIN "user" u ON i.user_id = u.id WHERE i.id = $1
+`
+
+func (q *Queries) SelectUserByInvoiceId(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, selectUserByInvoiceId, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectUserCompletedTasks = `-- name: SelectUserCompletedTasks :many
+SELECT id, created_at, due_at, completed_at FROM task
+WHERE
+  ((user_id = $1 AND list_id IS NULL) OR completed_by = $1)
+  AND deleted_at IS NULL
+  AND checked
+  AND completed_at >= $2::timestamptz
+  AND completed_at < $3::timestamptz
+ORDER BY completed_at
+`
+
+type SelectUserCompletedTasksParams struct {
+	UserID         pgtype.UUID        `json:"user_id"`
+	CompletedFrom  pgtype.Timestamptz `json:"completed_from"`
+	CompletedUntil pgtype.Timestamptz `json:"completed_until"`
+}
+
+type SelectUserCompletedTasksRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	DueAt       pgtype.Timestamptz `json:"due_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+func (q *Queries) SelectUserCompletedTasks(ctx context.Context, arg SelectUserCompletedTasksParams) ([]SelectUserCompletedTasksRow, error) {
+	rows, err := q.db.Query(ctx, selectUserCompletedTasks, arg.UserID, arg.CompletedFrom, arg.CompletedUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserCompletedTasksRow
+	for rows.Next() {
+		var i SelectUserCompletedTasksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.DueAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserFocusPreference = `-- name: SelectUserFocusPreference :one
+SELECT user_id, day_starts_at, day_ends_at, prayer_duration_in_minutes, break_in_minutes, default_task_duration_in_minutes FROM focus_preference WHERE user_id = $1
+`
+
+func (q *Queries) SelectUserFocusPreference(ctx context.Context, userID pgtype.UUID) (FocusPreference, error) {
+	row := q.db.QueryRow(ctx, selectUserFocusPreference, userID)
+	var i FocusPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.DayStartsAt,
+		&i.DayEndsAt,
+		&i.PrayerDurationInMinutes,
+		&i.BreakInMinutes,
+		&i.DefaultTaskDurationInMinutes,
+	)
+	return i, err
+}
+
+const selectUserFocusSession = `-- name: SelectUserFocusSession :one
+SELECT id, user_id, task_id, planned_duration_in_minutes, status, focused_seconds, paused_for_prayer, started_at, resumed_at, ended_at FROM focus_session WHERE id = $1 AND user_id = $2 FOR UPDATE
+`
+
+type SelectUserFocusSessionParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) SelectUserFocusSession(ctx context.Context, arg SelectUserFocusSessionParams) (FocusSession, error) {
+	row := q.db.QueryRow(ctx, selectUserFocusSession, arg.ID, arg.UserID)
+	var i FocusSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.PlannedDurationInMinutes,
+		&i.Status,
+		&i.FocusedSeconds,
+		&i.PausedForPrayer,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const selectUserFocusedSeconds = `-- name: SelectUserFocusedSeconds :one
+SELECT COALESCE(SUM(
+  focused_seconds + CASE WHEN status = 'running' THEN date_part('epoch', $2::timestamptz - resumed_at)::int ELSE 0 END
+), 0)::bigint AS focused_seconds
+FROM focus_session
+WHERE user_id = $1 AND started_at >= $3::timestamptz AND started_at < $4::timestamptz
+`
+
+type SelectUserFocusedSecondsParams struct {
+	UserID       pgtype.UUID        `json:"user_id"`
+	Now          pgtype.Timestamptz `json:"now"`
+	StartedFrom  pgtype.Timestamptz `json:"started_from"`
+	StartedUntil pgtype.Timestamptz `json:"started_until"`
+}
+
+func (q *Queries) SelectUserFocusedSeconds(ctx context.Context, arg SelectUserFocusedSecondsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, selectUserFocusedSeconds,
+		arg.UserID,
+		arg.Now,
+		arg.StartedFrom,
+		arg.StartedUntil,
+	)
+	var focusedSeconds int64
+	err := row.Scan(&focusedSeconds)
+	return focusedSeconds, err
+}
+
+const selectUserLabels = `-- name: SelectUserLabels :many
+SELECT id, user_id, name, color, created_at FROM label WHERE user_id = $1 ORDER BY name
+`
+
+func (q *Queries) SelectUserLabels(ctx context.Context, userID pgtype.UUID) ([]Label, error) {
+	rows, err := q.db.Query(ctx, selectUserLabels, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Label
+	for rows.Next() {
+		var i Label
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserPayments = `-- name: SelectUserPayments :many
+SELECT id, user_id, invoice_id, amount_paid, status, created_at FROM payment WHERE user_id = $1
+`
+
+func (q *Queries) SelectUserPayments(ctx context.Context, userID pgtype.UUID) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, selectUserPayments, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.InvoiceID,
+			&i.AmountPaid,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserPersonalAccessToken = `-- name: SelectUserPersonalAccessToken :one
+SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at FROM personal_access_token WHERE id = $1 AND user_id = $2
+`
+
+type SelectUserPersonalAccessTokenParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) SelectUserPersonalAccessToken(ctx context.Context, arg SelectUserPersonalAccessTokenParams) (PersonalAccessToken, error) {
+	row := q.db.QueryRow(ctx, selectUserPersonalAccessToken, arg.ID, arg.UserID)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectUserPersonalAccessTokens = `-- name: SelectUserPersonalAccessTokens :many
+SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at FROM personal_access_token WHERE user_id = $1 ORDER BY created_at DESC
+`
+
+func (q *Queries) SelectUserPersonalAccessTokens(ctx context.Context, userID pgtype.UUID) ([]PersonalAccessToken, error) {
+	rows, err := q.db.Query(ctx, selectUserPersonalAccessTokens, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PersonalAccessToken
+	for rows.Next() {
+		var i PersonalAccessToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenPrefix,
+			&i.TokenHash,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserPlannableTasks = `-- name: SelectUserPlannableTasks :many
+SELECT id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector FROM task
+WHERE
+  user_id = $1
+  AND list_id IS NULL
+  AND deleted_at IS NULL
+  AND (
+    (recurrence_rule IS NOT NULL AND recurrence_start <= $2::date)
+    OR (
+      recurrence_rule IS NULL
+      AND NOT checked
+      AND (
+        (due_at >= $3::timestamptz AND due_at < $4::timestamptz)
+        OR (due_at IS NULL AND anchor_prayer IS NOT NULL)
+      )
+    )
+  )
+ORDER BY priority DESC, due_at, position
+`
+
+type SelectUserPlannableTasksParams struct {
+	UserID   pgtype.UUID        `json:"user_id"`
+	Date     pgtype.Date        `json:"date"`
+	DueFrom  pgtype.Timestamptz `json:"due_from"`
+	DueUntil pgtype.Timestamptz `json:"due_until"`
+}
+
+func (q *Queries) SelectUserPlannableTasks(ctx context.Context, arg SelectUserPlannableTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, selectUserPlannableTasks,
+		arg.UserID,
+		arg.Date,
+		arg.DueFrom,
+		arg.DueUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Checked,
+			&i.AnchorPrayer,
+			&i.AnchorRelation,
+			&i.AnchorOffsetInMinutes,
+			&i.RecurrenceRule,
+			&i.RecurrenceStart,
+			&i.DueAt,
+			&i.Priority,
+			&i.Position,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ListID,
+			&i.AssigneeID,
+			&i.CompletedBy,
+			&i.EstimatedDurationInMinutes,
+			&i.CompletedAt,
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserPrayers = `-- name: SelectUserPrayers :many
+SELECT id, user_id, name, status, year, month, day FROM prayer
+WHERE user_id = $1 AND year = $2 AND month = $3
+AND (day = $4 OR $4 IS NULL)
+`
+
+type SelectUserPrayersParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Year   int16       `json:"year"`
+	Month  int16       `json:"month"`
+	Day    pgtype.Int2 `json:"day"`
+}
+
+func (q *Queries) SelectUserPrayers(ctx context.Context, arg SelectUserPrayersParams) ([]Prayer, error) {
+	rows, err := q.db.Query(ctx, selectUserPrayers,
+		arg.UserID,
+		arg.Year,
+		arg.Month,
+		arg.Day,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Prayer
 	for rows.Next() {
-		var i Plan
+		var i Prayer
 		if err := rows.Scan(
 			&i.ID,
-			&i.Type,
+			&i.UserID,
 			&i.Name,
-			&i.Price,
-			&i.DurationInMonths,
-			&i.CreatedAt,
-			&i.DeletedAt,
+			&i.Status,
+			&i.Year,
+			&i.Month,
+			&i.Day,
 		); err != nil {
 			return nil, err
 		}
@@ -437,148 +2354,158 @@ func (q *Queries) SelectPlans(ctx context.Context) ([]Plan, error) {
 	return items, nil
 }
 
-const selectUser = `-- name: SelectUser :one
-SELECT 
-  u.id, u.email, u.password, u.name, u.coordinates, u.city, u.timezone, u.created_at, 
-  to_jsonb(s) AS subscription
-FROM "user" u
-LEFT JOIN subscription s ON s.user_id = u.id
-WHERE u.id = $1
+const selectUserRefreshToken = `-- name: SelectUserRefreshToken :one
+SELECT id, user_id, revoked, expires_at, family_id, parent_id, device_name, user_agent, ip_address, created_at, last_used_at FROM refresh_token WHERE id = $1 AND user_id = $2 FOR UPDATE
 `
 
-type SelectUserRow struct {
-	ID           pgtype.UUID        `json:"id"`
-	Email        string             `json:"email"`
-	Password     string             `json:"password"`
-	Name         string             `json:"name"`
-	Coordinates  pgtype.Point       `json:"coordinates"`
-	City         string             `json:"city"`
-	Timezone     string             `json:"timezone"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	Subscription []byte             `json:"subscription"`
+type SelectUserRefreshTokenParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
 }
 
-func (q *Queries) SelectUser(ctx context.Context, id pgtype.UUID) (SelectUserRow, error) {
-	row := q.db.QueryRow(ctx, selectUser, id)
-	var i SelectUserRow
+func (q *Queries) SelectUserRefreshToken(ctx context.Context, arg SelectUserRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, selectUserRefreshToken, arg.ID, arg.UserID)
+	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Password,
-		&i.Name,
-		&i.Coordinates,
-		&i.City,
-		&i.Timezone,
+		&i.UserID,
+		&i.Revoked,
+		&i.ExpiresAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.DeviceName,
+		&i.UserAgent,
+		&i.IpAddress,
 		&i.CreatedAt,
-		&i.Subscription,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
 
-const selectUserActiveInvoice = `-- name: SelectUserActiveInvoice :one
-SELECT i.id, i.user_id, i.plan_id, i.ref_id, i.coupon_code, i.total_amount, i.qr_url, i.expires_at, i.created_at FROM invoice i
-WHERE i.user_id = $1 AND i.expires_at > NOW()
-AND NOT EXISTS (
-    SELECT 1 
-    FROM payment p 
-    WHERE p.invoice_id = i.id
-)
+const selectUserRole = `-- name: SelectUserRole :one
+SELECT role FROM "user" WHERE id = $1
 `
 
-func (q *Queries) SelectUserActiveInvoice(ctx context.Context, userID pgtype.UUID) (Invoice, error) {
-	row := q.db.QueryRow(ctx, selectUserActiveInvoice, userID)
-	var i Invoice
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.PlanID,
-		&i.RefID,
-		&i.CouponCode,
-		&i.TotalAmount,
-		&i.QrUrl,
-		&i.ExpiresAt,
-		&i.CreatedAt,
-	)
-	return i, err
+func (q *Queries) SelectUserRole(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, selectUserRole, id)
+	var role string
+	err := row.Scan(&role)
+	return role, err
 }
 
-const selectUserActiveSubscription = `-- name: SelectUserActiveSubscription :one
-SELECT id, user_id, plan_id, payment_id, start_date, end_date FROM subscription WHERE user_id = $1 AND end_date > NOW()
+const selectUserSessions = `-- name: SelectUserSessions :many
+SELECT id, user_id, revoked, expires_at, family_id, parent_id, device_name, user_agent, ip_address, created_at, last_used_at FROM refresh_token
+WHERE user_id = $1 AND NOT revoked AND expires_at > NOW()
+ORDER BY last_used_at DESC
 `
 
-func (q *Queries) SelectUserActiveSubscription(ctx context.Context, userID pgtype.UUID) (Subscription, error) {
-	row := q.db.QueryRow(ctx, selectUserActiveSubscription, userID)
-	var i Subscription
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.PlanID,
-		&i.PaymentID,
-		&i.StartDate,
-		&i.EndDate,
-	)
-	return i, err
+func (q *Queries) SelectUserSessions(ctx context.Context, userID pgtype.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.Query(ctx, selectUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Revoked,
+			&i.ExpiresAt,
+			&i.FamilyID,
+			&i.ParentID,
+			&i.DeviceName,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const selectUserByEmail = `-- name: SelectUserByEmail :one
-SELECT id, email, password, name, coordinates, city, timezone, created_at FROM "user" WHERE email = $1
+const selectUserTOTP = `-- name: SelectUserTOTP :one
+SELECT user_id, encrypted_secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id = $1
 `
 
-func (q *Queries) SelectUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, selectUserByEmail, email)
-	var i User
+func (q *Queries) SelectUserTOTP(ctx context.Context, userID pgtype.UUID) (UserTotp, error) {
+	row := q.db.QueryRow(ctx, selectUserTOTP, userID)
+	var i UserTotp
 	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.Password,
-		&i.Name,
-		&i.Coordinates,
-		&i.City,
-		&i.Timezone,
+		&i.UserID,
+		&i.EncryptedSecret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const selectUserByInvoiceId = `-- name: SelectUserByInvoiceId :one
-SELECT u.id, u.email, u.password, u.name, u.coordinates, u.city, u.timezone, u.created_at FROM invoice i JOIN "user" u ON i.user_id = u.id WHERE i.id = $1
+const selectUserTask = `-- name: SelectUserTask :one
+SELECT id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector FROM task WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL
 `
 
-func (q *Queries) SelectUserByInvoiceId(ctx context.Context, id pgtype.UUID) (User, error) {
-	row := q.db.QueryRow(ctx, selectUserByInvoiceId, id)
-	var i User
+type SelectUserTaskParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) SelectUserTask(ctx context.Context, arg SelectUserTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, selectUserTask, arg.ID, arg.UserID)
+	var i Task
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Password,
+		&i.UserID,
 		&i.Name,
-		&i.Coordinates,
-		&i.City,
-		&i.Timezone,
+		&i.Description,
+		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
 	)
 	return i, err
 }
 
-const selectUserPayments = `-- name: SelectUserPayments :many
-SELECT id, user_id, invoice_id, amount_paid, status, created_at FROM payment WHERE user_id = $1
+const selectUserTaskLists = `-- name: SelectUserTaskLists :many
+SELECT tl.id, tl.owner_id, tl.name, tl.created_at FROM task_list tl
+JOIN task_list_member m ON m.task_list_id = tl.id
+WHERE m.user_id = $1
+ORDER BY tl.created_at
 `
 
-func (q *Queries) SelectUserPayments(ctx context.Context, userID pgtype.UUID) ([]Payment, error) {
-	rows, err := q.db.Query(ctx, selectUserPayments, userID)
+func (q *Queries) SelectUserTaskLists(ctx context.Context, userID pgtype.UUID) ([]TaskList, error) {
+	rows, err := q.db.Query(ctx, selectUserTaskLists, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Payment
+	var items []TaskList
 	for rows.Next() {
-		var i Payment
+		var i TaskList
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
-			&i.InvoiceID,
-			&i.AmountPaid,
-			&i.Status,
+			&i.OwnerID,
+			&i.Name,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -591,41 +2518,152 @@ func (q *Queries) SelectUserPayments(ctx context.Context, userID pgtype.UUID) ([
 	return items, nil
 }
 
-const selectUserPrayers = `-- name: SelectUserPrayers :many
-SELECT id, user_id, name, status, year, month, day FROM prayer
-WHERE user_id = $1 AND year = $2 AND month = $3
-AND (day = $4 OR $4 IS NULL)
+const selectUserTasks = `-- name: SelectUserTasks :many
+SELECT t.id, t.user_id, t.name, t.description, t.checked, t.anchor_prayer, t.anchor_relation, t.anchor_offset_in_minutes, t.recurrence_rule, t.recurrence_start, t.due_at, t.priority, t.position, t.created_at, t.deleted_at, t.list_id, t.assignee_id, t.completed_by, t.estimated_duration_in_minutes, t.completed_at, t.search_vector, k.sort_key
+FROM task t
+CROSS JOIN LATERAL (
+  SELECT (
+    CASE $2::text
+      WHEN 'due_at' THEN COALESCE(EXTRACT(EPOCH FROM t.due_at)::float8, 'Infinity'::float8)
+      WHEN 'priority' THEN t.priority::float8
+      WHEN 'position' THEN t.position::float8
+      ELSE EXTRACT(EPOCH FROM t.created_at)::float8
+    END
+  )::float8 AS sort_key
+) k
+WHERE
+  t.user_id = $1
+  AND t.list_id IS NULL
+  AND t.deleted_at IS NULL
+  AND ($3::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM task_label tl WHERE tl.task_id = t.id AND tl.label_id = $3::uuid
+  ))
+  AND ($4::boolean IS NULL OR t.checked = $4::boolean)
+  AND ($5::timestamptz IS NULL OR t.due_at < $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR t.due_at > $6::timestamptz)
+  AND ($7::smallint IS NULL OR t.priority = $7::smallint)
+  AND (
+    $8::uuid IS NULL
+    OR ($9::boolean AND (k.sort_key, t.id) < ($10::float8, $8::uuid))
+    OR (NOT $9::boolean AND (k.sort_key, t.id) > ($10::float8, $8::uuid))
+  )
+ORDER BY
+  CASE WHEN $9::boolean THEN k.sort_key END DESC,
+  CASE WHEN $9::boolean THEN t.id END DESC,
+  k.sort_key,
+  t.id
+LIMIT $11::int
 `
 
-type SelectUserPrayersParams struct {
-	UserID pgtype.UUID `json:"user_id"`
-	Year   int16       `json:"year"`
-	Month  int16       `json:"month"`
-	Day    pgtype.Int2 `json:"day"`
+type SelectUserTasksParams struct {
+	UserID        pgtype.UUID        `json:"user_id"`
+	SortBy        string             `json:"sort_by"`
+	LabelID       pgtype.UUID        `json:"label_id"`
+	Checked       pgtype.Bool        `json:"checked"`
+	DueBefore     pgtype.Timestamptz `json:"due_before"`
+	DueAfter      pgtype.Timestamptz `json:"due_after"`
+	Priority      pgtype.Int2        `json:"priority"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	Descending    bool               `json:"descending"`
+	CursorSortKey pgtype.Float8      `json:"cursor_sort_key"`
+	RowLimit      int32              `json:"row_limit"`
 }
 
-func (q *Queries) SelectUserPrayers(ctx context.Context, arg SelectUserPrayersParams) ([]Prayer, error) {
-	rows, err := q.db.Query(ctx, selectUserPrayers,
+type SelectUserTasksRow struct {
+	Task    Task    `json:"task"`
+	SortKey float64 `json:"sort_key"`
+}
+
+func (q *Queries) SelectUserTasks(ctx context.Context, arg SelectUserTasksParams) ([]SelectUserTasksRow, error) {
+	rows, err := q.db.Query(ctx, selectUserTasks,
 		arg.UserID,
-		arg.Year,
-		arg.Month,
-		arg.Day,
+		arg.SortBy,
+		arg.LabelID,
+		arg.Checked,
+		arg.DueBefore,
+		arg.DueAfter,
+		arg.Priority,
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorSortKey,
+		arg.RowLimit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Prayer
+	var items []SelectUserTasksRow
 	for rows.Next() {
-		var i Prayer
+		var i SelectUserTasksRow
+		if err := rows.Scan(
+			&i.Task.ID,
+			&i.Task.UserID,
+			&i.Task.Name,
+			&i.Task.Description,
+			&i.Task.Checked,
+			&i.Task.AnchorPrayer,
+			&i.Task.AnchorRelation,
+			&i.Task.AnchorOffsetInMinutes,
+			&i.Task.RecurrenceRule,
+			&i.Task.RecurrenceStart,
+			&i.Task.DueAt,
+			&i.Task.Priority,
+			&i.Task.Position,
+			&i.Task.CreatedAt,
+			&i.Task.DeletedAt,
+			&i.Task.ListID,
+			&i.Task.AssigneeID,
+			&i.Task.CompletedBy,
+			&i.Task.EstimatedDurationInMinutes,
+			&i.Task.CompletedAt,
+			&i.Task.SearchVector,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserTrashedTasks = `-- name: SelectUserTrashedTasks :many
+SELECT id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector FROM task WHERE user_id = $1 AND list_id IS NULL AND deleted_at IS NOT NULL ORDER BY deleted_at DESC
+`
+
+func (q *Queries) SelectUserTrashedTasks(ctx context.Context, userID pgtype.UUID) ([]Task, error) {
+	rows, err := q.db.Query(ctx, selectUserTrashedTasks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
 			&i.Name,
-			&i.Status,
-			&i.Year,
-			&i.Month,
-			&i.Day,
+			&i.Description,
+			&i.Checked,
+			&i.AnchorPrayer,
+			&i.AnchorRelation,
+			&i.AnchorOffsetInMinutes,
+			&i.RecurrenceRule,
+			&i.RecurrenceStart,
+			&i.DueAt,
+			&i.Priority,
+			&i.Position,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ListID,
+			&i.AssigneeID,
+			&i.CompletedBy,
+			&i.EstimatedDurationInMinutes,
+			&i.CompletedAt,
+			&i.SearchVector,
 		); err != nil {
 			return nil, err
 		}
@@ -637,73 +2675,240 @@ func (q *Queries) SelectUserPrayers(ctx context.Context, arg SelectUserPrayersPa
 	return items, nil
 }
 
-const selectUserRefreshToken = `-- name: SelectUserRefreshToken :one
-SELECT id, user_id, revoked, expires_at FROM refresh_token WHERE id = $1 AND user_id = $2
+const unassignTaskListMemberTasks = `-- name: UnassignTaskListMemberTasks :exec
+UPDATE task SET assignee_id = NULL WHERE list_id = $1 AND assignee_id = $2
+`
+
+type UnassignTaskListMemberTasksParams struct {
+	ListID     pgtype.UUID `json:"list_id"`
+	AssigneeID pgtype.UUID `json:"assignee_id"`
+}
+
+func (q *Queries) UnassignTaskListMemberTasks(ctx context.Context, arg UnassignTaskListMemberTasksParams) error {
+	_, err := q.db.Exec(ctx, unassignTaskListMemberTasks, arg.ListID, arg.AssigneeID)
+	return err
+}
+
+const updateCoupon = `-- name: UpdateCoupon :one
+UPDATE coupon
+SET
+  influencer_username = COALESCE($2, influencer_username),
+  quota = COALESCE($3, quota)
+WHERE code = $1 AND deleted_at IS NULL RETURNING code, influencer_username, quota, created_at, deleted_at
+`
+
+type UpdateCouponParams struct {
+	Code               string      `json:"code"`
+	InfluencerUsername pgtype.Text `json:"influencer_username"`
+	Quota              pgtype.Int2 `json:"quota"`
+}
+
+func (q *Queries) UpdateCoupon(ctx context.Context, arg UpdateCouponParams) (Coupon, error) {
+	row := q.db.QueryRow(ctx, updateCoupon, arg.Code, arg.InfluencerUsername, arg.Quota)
+	var i Coupon
+	err := row.Scan(
+		&i.Code,
+		&i.InfluencerUsername,
+		&i.Quota,
+		&i.CreatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateFocusSession = `-- name: UpdateFocusSession :one
+UPDATE focus_session
+SET
+  status = $2,
+  focused_seconds = $3,
+  paused_for_prayer = $4,
+  resumed_at = $5,
+  ended_at = $6
+WHERE id = $1
+RETURNING id, user_id, task_id, planned_duration_in_minutes, status, focused_seconds, paused_for_prayer, started_at, resumed_at, ended_at
+`
+
+type UpdateFocusSessionParams struct {
+	ID              pgtype.UUID        `json:"id"`
+	Status          string             `json:"status"`
+	FocusedSeconds  int32              `json:"focused_seconds"`
+	PausedForPrayer pgtype.Text        `json:"paused_for_prayer"`
+	ResumedAt       pgtype.Timestamptz `json:"resumed_at"`
+	EndedAt         pgtype.Timestamptz `json:"ended_at"`
+}
+
+func (q *Queries) UpdateFocusSession(ctx context.Context, arg UpdateFocusSessionParams) (FocusSession, error) {
+	row := q.db.QueryRow(ctx, updateFocusSession,
+		arg.ID,
+		arg.Status,
+		arg.FocusedSeconds,
+		arg.PausedForPrayer,
+		arg.ResumedAt,
+		arg.EndedAt,
+	)
+	var i FocusSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.PlannedDurationInMinutes,
+		&i.Status,
+		&i.FocusedSeconds,
+		&i.PausedForPrayer,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const updatePlan = `-- name: UpdatePlan :one
+UPDATE plan
+SET
+  name = COALESCE($2, name),
+  price = COALESCE($3, price),
+  duration_in_months = COALESCE($4, duration_in_months)
+WHERE id = $1 AND deleted_at IS NULL RETURNING id, type, name, price, duration_in_months, created_at, deleted_at
+`
+
+type UpdatePlanParams struct {
+	ID               pgtype.UUID `json:"id"`
+	Name             pgtype.Text `json:"name"`
+	Price            pgtype.Int4 `json:"price"`
+	DurationInMonths pgtype.Int2 `json:"duration_in_months"`
+}
+
+func (q *Queries) UpdatePlan(ctx context.Context, arg UpdatePlanParams) (Plan, error) {
+	row := q.db.QueryRow(ctx, updatePlan,
+		arg.ID,
+		arg.Name,
+		arg.Price,
+		arg.DurationInMonths,
+	)
+	var i Plan
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Name,
+		&i.Price,
+		&i.DurationInMonths,
+		&i.CreatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateTaskItemPositions = `-- name: UpdateTaskItemPositions :exec
+UPDATE task_item
+SET position = array_position($2::uuid[], id) - 1
+WHERE task_id = $1
+`
+
+type UpdateTaskItemPositionsParams struct {
+	TaskID  pgtype.UUID   `json:"task_id"`
+	ItemIds []pgtype.UUID `json:"item_ids"`
+}
+
+func (q *Queries) UpdateTaskItemPositions(ctx context.Context, arg UpdateTaskItemPositionsParams) error {
+	_, err := q.db.Exec(ctx, updateTaskItemPositions, arg.TaskID, arg.ItemIds)
+	return err
+}
+
+const updateTaskListTask = `-- name: UpdateTaskListTask :one
+UPDATE task
+SET
+  name = COALESCE($3, name),
+  description = COALESCE($4, description),
+  checked = COALESCE($5, checked),
+  completed_by = CASE
+    WHEN $5::boolean IS NULL OR $5::boolean = checked THEN completed_by
+    WHEN $5::boolean THEN $6::uuid
+    ELSE NULL
+  END,
+  completed_at = CASE
+    WHEN $5::boolean IS NULL OR $5::boolean = checked THEN completed_at
+    WHEN $5::boolean THEN CURRENT_TIMESTAMP
+    ELSE NULL
+  END,
+  assignee_id = CASE WHEN $7::boolean THEN NULL ELSE COALESCE($8, assignee_id) END,
+  due_at = CASE WHEN $9::boolean THEN NULL ELSE COALESCE($10, due_at) END,
+  priority = COALESCE($11, priority)
+WHERE id = $1 AND list_id = $2 AND deleted_at IS NULL RETURNING id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector
 `
 
-type SelectUserRefreshTokenParams struct {
-	ID     pgtype.UUID `json:"id"`
-	UserID pgtype.UUID `json:"user_id"`
+type UpdateTaskListTaskParams struct {
+	ID             pgtype.UUID        `json:"id"`
+	ListID         pgtype.UUID        `json:"list_id"`
+	Name           pgtype.Text        `json:"name"`
+	Description    pgtype.Text        `json:"description"`
+	Checked        pgtype.Bool        `json:"checked"`
+	UpdatedBy      pgtype.UUID        `json:"updated_by"`
+	RemoveAssignee bool               `json:"remove_assignee"`
+	AssigneeID     pgtype.UUID        `json:"assignee_id"`
+	RemoveDueAt    bool               `json:"remove_due_at"`
+	DueAt          pgtype.Timestamptz `json:"due_at"`
+	Priority       pgtype.Int2        `json:"priority"`
 }
 
-func (q *Queries) SelectUserRefreshToken(ctx context.Context, arg SelectUserRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRow(ctx, selectUserRefreshToken, arg.ID, arg.UserID)
-	var i RefreshToken
+func (q *Queries) UpdateTaskListTask(ctx context.Context, arg UpdateTaskListTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, updateTaskListTask,
+		arg.ID,
+		arg.ListID,
+		arg.Name,
+		arg.Description,
+		arg.Checked,
+		arg.UpdatedBy,
+		arg.RemoveAssignee,
+		arg.AssigneeID,
+		arg.RemoveDueAt,
+		arg.DueAt,
+		arg.Priority,
+	)
+	var i Task
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.Revoked,
-		&i.ExpiresAt,
+		&i.Name,
+		&i.Description,
+		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
 	)
 	return i, err
 }
 
-const selectUserTasks = `-- name: SelectUserTasks :many
-SELECT id, user_id, name, description, checked FROM task WHERE user_id = $1
-`
-
-func (q *Queries) SelectUserTasks(ctx context.Context, userID pgtype.UUID) ([]Task, error) {
-	rows, err := q.db.Query(ctx, selectUserTasks, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Task
-	for rows.Next() {
-		var i Task
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Name,
-			&i.Description,
-			&i.Checked,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const updateUser = `-- name: UpdateUser :one
 UPDATE "user"
 SET
   email = COALESCE($2, email),
-  password = COALESCE($3, password),
-  name = COALESCE($4, name),
-  coordinates = COALESCE($5, coordinates),
-  city = COALESCE($6, city),
-  timezone = COALESCE($7, timezone)
-WHERE id = $1 RETURNING id, email, password, name, coordinates, city, timezone, created_at
+  email_verified_at = CASE
+    WHEN $2::text IS NULL OR $2::text = email THEN email_verified_at
+    ELSE NULL
+  END,
+  name = COALESCE($3, name),
+  coordinates = COALESCE($4, coordinates),
+  city = COALESCE($5, city),
+  timezone = COALESCE($6, timezone)
+WHERE id = $1 RETURNING id, email, password, name, coordinates, city, timezone, email_verified_at, role, created_at
 `
 
 type UpdateUserParams struct {
 	ID          pgtype.UUID  `json:"id"`
 	Email       pgtype.Text  `json:"email"`
-	Password    pgtype.Text  `json:"password"`
 	Name        pgtype.Text  `json:"name"`
 	Coordinates pgtype.Point `json:"coordinates"`
 	City        pgtype.Text  `json:"city"`
@@ -714,7 +2919,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 	row := q.db.QueryRow(ctx, updateUser,
 		arg.ID,
 		arg.Email,
-		arg.Password,
 		arg.Name,
 		arg.Coordinates,
 		arg.City,
@@ -729,6 +2933,68 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Coordinates,
 		&i.City,
 		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateUserLabel = `-- name: UpdateUserLabel :one
+UPDATE label
+SET
+  name = COALESCE($3, name),
+  color = COALESCE($4, color)
+WHERE id = $1 AND user_id = $2 RETURNING id, user_id, name, color, created_at
+`
+
+type UpdateUserLabelParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+	Name   pgtype.Text `json:"name"`
+	Color  pgtype.Text `json:"color"`
+}
+
+func (q *Queries) UpdateUserLabel(ctx context.Context, arg UpdateUserLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, updateUserLabel,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Color,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE "user" SET password = $2 WHERE id = $1 RETURNING id, email, password, name, coordinates, city, timezone, email_verified_at, role, created_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID       pgtype.UUID `json:"id"`
+	Password string      `json:"password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.ID, arg.Password)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
 		&i.CreatedAt,
 	)
 	return i, err
@@ -761,21 +3027,73 @@ func (q *Queries) UpdateUserPrayer(ctx context.Context, arg UpdateUserPrayerPara
 	return i, err
 }
 
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE "user" SET role = $2 WHERE id = $1 RETURNING id, email, password, name, coordinates, city, timezone, email_verified_at, role, created_at
+`
+
+type UpdateUserRoleParams struct {
+	ID   pgtype.UUID `json:"id"`
+	Role string      `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const updateUserTask = `-- name: UpdateUserTask :one
 UPDATE task
 SET
   name = COALESCE($3, name),
   description = COALESCE($4, description),
-  checked = COALESCE($5, checked)
-WHERE id = $1 AND user_id = $2 RETURNING id, user_id, name, description, checked
+  checked = COALESCE($5, checked),
+  completed_at = CASE
+    WHEN $5::boolean IS NULL OR $5::boolean = checked THEN completed_at
+    WHEN $5::boolean THEN CURRENT_TIMESTAMP
+    ELSE NULL
+  END,
+  anchor_prayer = CASE WHEN $6::boolean THEN NULL ELSE COALESCE($7, anchor_prayer) END,
+  anchor_relation = CASE WHEN $6::boolean THEN NULL ELSE COALESCE($8, anchor_relation) END,
+  anchor_offset_in_minutes = CASE WHEN $6::boolean THEN 0 ELSE COALESCE($9, anchor_offset_in_minutes) END,
+  recurrence_rule = CASE WHEN $10::boolean THEN NULL ELSE COALESCE($11, recurrence_rule) END,
+  recurrence_start = CASE WHEN $10::boolean THEN NULL ELSE COALESCE($12, recurrence_start) END,
+  due_at = CASE WHEN $13::boolean THEN NULL ELSE COALESCE($14, due_at) END,
+  priority = COALESCE($15, priority),
+  estimated_duration_in_minutes = CASE WHEN $16::boolean THEN NULL ELSE COALESCE($17, estimated_duration_in_minutes) END
+WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL RETURNING id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector
 `
 
 type UpdateUserTaskParams struct {
-	ID          pgtype.UUID `json:"id"`
-	UserID      pgtype.UUID `json:"user_id"`
-	Name        pgtype.Text `json:"name"`
-	Description pgtype.Text `json:"description"`
-	Checked     pgtype.Bool `json:"checked"`
+	ID                         pgtype.UUID        `json:"id"`
+	UserID                     pgtype.UUID        `json:"user_id"`
+	Name                       pgtype.Text        `json:"name"`
+	Description                pgtype.Text        `json:"description"`
+	Checked                    pgtype.Bool        `json:"checked"`
+	RemoveAnchor               bool               `json:"remove_anchor"`
+	AnchorPrayer               pgtype.Text        `json:"anchor_prayer"`
+	AnchorRelation             pgtype.Text        `json:"anchor_relation"`
+	AnchorOffsetInMinutes      pgtype.Int2        `json:"anchor_offset_in_minutes"`
+	RemoveRecurrence           bool               `json:"remove_recurrence"`
+	RecurrenceRule             pgtype.Text        `json:"recurrence_rule"`
+	RecurrenceStart            pgtype.Date        `json:"recurrence_start"`
+	RemoveDueAt                bool               `json:"remove_due_at"`
+	DueAt                      pgtype.Timestamptz `json:"due_at"`
+	Priority                   pgtype.Int2        `json:"priority"`
+	RemoveEstimatedDuration    bool               `json:"remove_estimated_duration"`
+	EstimatedDurationInMinutes pgtype.Int2        `json:"estimated_duration_in_minutes"`
 }
 
 func (q *Queries) UpdateUserTask(ctx context.Context, arg UpdateUserTaskParams) (Task, error) {
@@ -785,7 +3103,95 @@ func (q *Queries) UpdateUserTask(ctx context.Context, arg UpdateUserTaskParams)
 		arg.Name,
 		arg.Description,
 		arg.Checked,
+		arg.RemoveAnchor,
+		arg.AnchorPrayer,
+		arg.AnchorRelation,
+		arg.AnchorOffsetInMinutes,
+		arg.RemoveRecurrence,
+		arg.RecurrenceRule,
+		arg.RecurrenceStart,
+		arg.RemoveDueAt,
+		arg.DueAt,
+		arg.Priority,
+		arg.RemoveEstimatedDuration,
+		arg.EstimatedDurationInMinutes,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const updateUserTaskItem = `-- name: UpdateUserTaskItem :one
+UPDATE task_item
+SET
+  name = COALESCE($4, task_item.name),
+  checked = COALESCE($5, task_item.checked)
+FROM task
+WHERE task_item.id = $1 AND task_item.task_id = $2 AND task.id = task_item.task_id AND task.user_id = $3 AND task.list_id IS NULL AND task.deleted_at IS NULL
+RETURNING task_item.id, task_item.task_id, task_item.name, task_item.checked, task_item.position
+`
+
+type UpdateUserTaskItemParams struct {
+	ID      pgtype.UUID `json:"id"`
+	TaskID  pgtype.UUID `json:"task_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+	Name    pgtype.Text `json:"name"`
+	Checked pgtype.Bool `json:"checked"`
+}
+
+func (q *Queries) UpdateUserTaskItem(ctx context.Context, arg UpdateUserTaskItemParams) (TaskItem, error) {
+	row := q.db.QueryRow(ctx, updateUserTaskItem,
+		arg.ID,
+		arg.TaskID,
+		arg.UserID,
+		arg.Name,
+		arg.Checked,
+	)
+	var i TaskItem
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.Name,
+		&i.Checked,
+		&i.Position,
 	)
+	return i, err
+}
+
+const updateUserTaskPosition = `-- name: UpdateUserTaskPosition :one
+UPDATE task SET position = $3 WHERE id = $1 AND user_id = $2 AND list_id IS NULL AND deleted_at IS NULL RETURNING id, user_id, name, description, checked, anchor_prayer, anchor_relation, anchor_offset_in_minutes, recurrence_rule, recurrence_start, due_at, priority, position, created_at, deleted_at, list_id, assignee_id, completed_by, estimated_duration_in_minutes, completed_at, search_vector
+`
+
+type UpdateUserTaskPositionParams struct {
+	ID       pgtype.UUID `json:"id"`
+	UserID   pgtype.UUID `json:"user_id"`
+	Position int64       `json:"position"`
+}
+
+func (q *Queries) UpdateUserTaskPosition(ctx context.Context, arg UpdateUserTaskPositionParams) (Task, error) {
+	row := q.db.QueryRow(ctx, updateUserTaskPosition, arg.ID, arg.UserID, arg.Position)
 	var i Task
 	err := row.Scan(
 		&i.ID,
@@ -793,6 +3199,243 @@ func (q *Queries) UpdateUserTask(ctx context.Context, arg UpdateUserTaskParams)
 		&i.Name,
 		&i.Description,
 		&i.Checked,
+		&i.AnchorPrayer,
+		&i.AnchorRelation,
+		&i.AnchorOffsetInMinutes,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.DueAt,
+		&i.Priority,
+		&i.Position,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ListID,
+		&i.AssigneeID,
+		&i.CompletedBy,
+		&i.EstimatedDurationInMinutes,
+		&i.CompletedAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const upsertTaskOccurrence = `-- name: UpsertTaskOccurrence :one
+INSERT INTO task_occurrence (task_id, occurrence_date, name, description, checked, skipped)
+VALUES ($1, $2, $3, $4, COALESCE($5, FALSE), COALESCE($6, FALSE))
+ON CONFLICT (task_id, occurrence_date) DO UPDATE
+SET
+  name = COALESCE(EXCLUDED.name, task_occurrence.name),
+  description = COALESCE(EXCLUDED.description, task_occurrence.description),
+  checked = COALESCE($5, task_occurrence.checked),
+  skipped = COALESCE($6, task_occurrence.skipped)
+RETURNING task_id, occurrence_date, name, description, checked, skipped
+`
+
+type UpsertTaskOccurrenceParams struct {
+	TaskID         pgtype.UUID `json:"task_id"`
+	OccurrenceDate pgtype.Date `json:"occurrence_date"`
+	Name           pgtype.Text `json:"name"`
+	Description    pgtype.Text `json:"description"`
+	Checked        pgtype.Bool `json:"checked"`
+	Skipped        pgtype.Bool `json:"skipped"`
+}
+
+func (q *Queries) UpsertTaskOccurrence(ctx context.Context, arg UpsertTaskOccurrenceParams) (TaskOccurrence, error) {
+	row := q.db.QueryRow(ctx, upsertTaskOccurrence,
+		arg.TaskID,
+		arg.OccurrenceDate,
+		arg.Name,
+		arg.Description,
+		arg.Checked,
+		arg.Skipped,
+	)
+	var i TaskOccurrence
+	err := row.Scan(
+		&i.TaskID,
+		&i.OccurrenceDate,
+		&i.Name,
+		&i.Description,
+		&i.Checked,
+		&i.Skipped,
+	)
+	return i, err
+}
+
+const upsertUserFocusPreference = `-- name: UpsertUserFocusPreference :one
+INSERT INTO focus_preference (user_id, day_starts_at, day_ends_at, prayer_duration_in_minutes, break_in_minutes, default_task_duration_in_minutes)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  day_starts_at = EXCLUDED.day_starts_at,
+  day_ends_at = EXCLUDED.day_ends_at,
+  prayer_duration_in_minutes = EXCLUDED.prayer_duration_in_minutes,
+  break_in_minutes = EXCLUDED.break_in_minutes,
+  default_task_duration_in_minutes = EXCLUDED.default_task_duration_in_minutes
+RETURNING user_id, day_starts_at, day_ends_at, prayer_duration_in_minutes, break_in_minutes, default_task_duration_in_minutes
+`
+
+type UpsertUserFocusPreferenceParams struct {
+	UserID                       pgtype.UUID `json:"user_id"`
+	DayStartsAt                  pgtype.Time `json:"day_starts_at"`
+	DayEndsAt                    pgtype.Time `json:"day_ends_at"`
+	PrayerDurationInMinutes      int16       `json:"prayer_duration_in_minutes"`
+	BreakInMinutes               int16       `json:"break_in_minutes"`
+	DefaultTaskDurationInMinutes int16       `json:"default_task_duration_in_minutes"`
+}
+
+func (q *Queries) UpsertUserFocusPreference(ctx context.Context, arg UpsertUserFocusPreferenceParams) (FocusPreference, error) {
+	row := q.db.QueryRow(ctx, upsertUserFocusPreference,
+		arg.UserID,
+		arg.DayStartsAt,
+		arg.DayEndsAt,
+		arg.PrayerDurationInMinutes,
+		arg.BreakInMinutes,
+		arg.DefaultTaskDurationInMinutes,
+	)
+	var i FocusPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.DayStartsAt,
+		&i.DayEndsAt,
+		&i.PrayerDurationInMinutes,
+		&i.BreakInMinutes,
+		&i.DefaultTaskDurationInMinutes,
+	)
+	return i, err
+}
+
+const upsertUserTOTP = `-- name: UpsertUserTOTP :one
+INSERT INTO user_totp (user_id, encrypted_secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  encrypted_secret = EXCLUDED.encrypted_secret,
+  last_used_step = 0,
+  created_at = CURRENT_TIMESTAMP
+WHERE user_totp.confirmed_at IS NULL
+RETURNING user_id, encrypted_secret, confirmed_at, last_used_step, created_at
+`
+
+type UpsertUserTOTPParams struct {
+	UserID          pgtype.UUID `json:"user_id"`
+	EncryptedSecret string      `json:"encrypted_secret"`
+}
+
+func (q *Queries) UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRow(ctx, upsertUserTOTP, arg.UserID, arg.EncryptedSecret)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.EncryptedSecret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const usePasswordResetToken = `-- name: UsePasswordResetToken :one
+UPDATE password_reset_token SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW() RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+`
+
+func (q *Queries) UsePasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error) {
+	row := q.db.QueryRow(ctx, usePasswordResetToken, tokenHash)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const usePersonalAccessToken = `-- name: UsePersonalAccessToken :one
+UPDATE personal_access_token SET last_used_at = NOW()
+WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at
+`
+
+func (q *Queries) UsePersonalAccessToken(ctx context.Context, tokenHash string) (PersonalAccessToken, error) {
+	row := q.db.QueryRow(ctx, usePersonalAccessToken, tokenHash)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE recovery_code SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	CodeHash string      `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useRecoveryCode, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const useUserTOTPStep = `-- name: UseUserTOTPStep :execrows
+UPDATE user_totp SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2
+`
+
+type UseUserTOTPStepParams struct {
+	UserID       pgtype.UUID `json:"user_id"`
+	LastUsedStep int64       `json:"last_used_step"`
+}
+
+func (q *Queries) UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useUserTOTPStep, arg.UserID, arg.LastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE "user" SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+WHERE id = $1 AND email = $2 RETURNING id, email, password, name, coordinates, city, timezone, email_verified_at, role, created_at
+`
+
+type VerifyUserEmailParams struct {
+	ID    pgtype.UUID `json:"id"`
+	Email string      `json:"email"`
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Name,
+		&i.Coordinates,
+		&i.City,
+		&i.Timezone,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -6,6 +6,8 @@ CREATE TABLE "user" (
   coordinates POINT NOT NULL,
   city VARCHAR(255) NOT NULL,
   timezone VARCHAR(255) NOT NULL,
+  email_verified_at TIMESTAMPTZ NULL,
+  role VARCHAR(16) DEFAULT 'user' NOT NULL CHECK (role IN ('user', 'admin', 'support', 'influencer')),
   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
 );
 
@@ -14,14 +16,30 @@ CREATE TABLE refresh_token (
   user_id UUID NOT NULL,
   revoked BOOLEAN DEFAULT FALSE NOT NULL,
   expires_at TIMESTAMPTZ NOT NULL,
+  family_id UUID NOT NULL,
+  parent_id UUID NULL,
+  device_name VARCHAR(255) DEFAULT '' NOT NULL,
+  user_agent TEXT DEFAULT '' NOT NULL,
+  ip_address TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+  last_used_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
 
   CONSTRAINT fk_refresh_token_user_id
     FOREIGN KEY (user_id)
     REFERENCES "user"(id)
     ON UPDATE CASCADE
-    ON DELETE CASCADE
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_refresh_token_parent_id
+    FOREIGN KEY (parent_id)
+    REFERENCES refresh_token(id)
+    ON UPDATE CASCADE
+    ON DELETE SET NULL
 );
 
+CREATE INDEX idx_refresh_token_family_id ON refresh_token (family_id);
+CREATE INDEX idx_refresh_token_user_id ON refresh_token (user_id) WHERE NOT revoked;
+
 CREATE TABLE prayer (
   id UUID PRIMARY KEY,
   user_id UUID NOT NULL,
@@ -136,16 +154,329 @@ CREATE TABLE subscription (
     ON DELETE CASCADE
 );
 
+CREATE TABLE task_list (
+  id UUID PRIMARY KEY,
+  owner_id UUID NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  CONSTRAINT fk_task_list_owner_id
+    FOREIGN KEY (owner_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE TABLE task_list_member (
+  task_list_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'collaborator')),
+  joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  PRIMARY KEY (task_list_id, user_id),
+
+  CONSTRAINT fk_task_list_member_task_list_id
+    FOREIGN KEY (task_list_id)
+    REFERENCES task_list(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_task_list_member_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_list_member_user_id ON task_list_member (user_id);
+
 CREATE TABLE task (
   id UUID PRIMARY KEY,
   user_id UUID NOT NULL,
   name VARCHAR(255) NOT NULL,
   description TEXT NOT NULL,
   checked BOOLEAN DEFAULT FALSE NOT NULL,
+  anchor_prayer VARCHAR(16) NULL CHECK (anchor_prayer IN ('subuh', 'zuhur', 'asar', 'magrib', 'isya')),
+  anchor_relation VARCHAR(16) NULL CHECK (anchor_relation IN ('before', 'after', 'between')),
+  anchor_offset_in_minutes SMALLINT DEFAULT 0 NOT NULL CHECK (anchor_offset_in_minutes >= 0),
+  recurrence_rule VARCHAR(255) NULL,
+  recurrence_start DATE NULL,
+  due_at TIMESTAMPTZ NULL,
+  priority SMALLINT DEFAULT 0 NOT NULL CHECK (priority BETWEEN 0 AND 3),
+  position BIGINT DEFAULT 0 NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMPTZ NULL,
+  list_id UUID NULL,
+  assignee_id UUID NULL,
+  completed_by UUID NULL,
+  estimated_duration_in_minutes SMALLINT NULL CHECK (estimated_duration_in_minutes BETWEEN 1 AND 720),
+  completed_at TIMESTAMPTZ NULL,
+  search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', name), 'A') ||
+    setweight(to_tsvector('english', name), 'A') ||
+    setweight(to_tsvector('indonesian', description), 'B') ||
+    setweight(to_tsvector('english', description), 'B')
+  ) STORED,
+
+  CONSTRAINT chk_task_anchor
+    CHECK ((anchor_prayer IS NULL) = (anchor_relation IS NULL)),
+
+  CONSTRAINT chk_task_recurrence
+    CHECK ((recurrence_rule IS NULL) = (recurrence_start IS NULL)),
 
   CONSTRAINT fk_task_user_id
     FOREIGN KEY (user_id)
     REFERENCES "user"(id)
     ON UPDATE CASCADE
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_task_list_id
+    FOREIGN KEY (list_id)
+    REFERENCES task_list(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_task_assignee_id
+    FOREIGN KEY (assignee_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE SET NULL,
+
+  CONSTRAINT fk_task_completed_by
+    FOREIGN KEY (completed_by)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE SET NULL
+);
+
+CREATE INDEX idx_task_search_vector ON task USING GIN (search_vector);
+
+CREATE INDEX idx_task_user_id_position ON task (user_id, position);
+
+CREATE INDEX idx_task_deleted_at ON task (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX idx_task_list_id_position ON task (list_id, position) WHERE list_id IS NOT NULL;
+
+CREATE INDEX idx_task_user_id_completed_at ON task (user_id, completed_at) WHERE completed_at IS NOT NULL;
+
+CREATE TABLE task_occurrence (
+  task_id UUID NOT NULL,
+  occurrence_date DATE NOT NULL,
+  name VARCHAR(255) NULL,
+  description TEXT NULL,
+  checked BOOLEAN DEFAULT FALSE NOT NULL,
+  skipped BOOLEAN DEFAULT FALSE NOT NULL,
+
+  PRIMARY KEY (task_id, occurrence_date),
+
+  CONSTRAINT fk_task_occurrence_task_id
+    FOREIGN KEY (task_id)
+    REFERENCES task(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE TABLE task_item (
+  id UUID PRIMARY KEY,
+  task_id UUID NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  checked BOOLEAN DEFAULT FALSE NOT NULL,
+  position INT NOT NULL CHECK (position >= 0),
+
+  CONSTRAINT fk_task_item_task_id
+    FOREIGN KEY (task_id)
+    REFERENCES task(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_item_task_id ON task_item (task_id, position);
+
+CREATE TABLE label (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  color VARCHAR(7) NOT NULL CHECK (color ~ '^#[0-9a-fA-F]{6}$'),
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  UNIQUE (user_id, name),
+
+  CONSTRAINT fk_label_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE TABLE task_label (
+  task_id UUID NOT NULL,
+  label_id UUID NOT NULL,
+
+  PRIMARY KEY (task_id, label_id),
+
+  CONSTRAINT fk_task_label_task_id
+    FOREIGN KEY (task_id)
+    REFERENCES task(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_task_label_label_id
+    FOREIGN KEY (label_id)
+    REFERENCES label(id)
+    ON UPDATE CASCADE
     ON DELETE CASCADE
-);
\ No newline at end of file
+);
+
+CREATE INDEX idx_task_label_label_id ON task_label (label_id);
+
+CREATE TABLE focus_session (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  task_id UUID NULL,
+  planned_duration_in_minutes SMALLINT NOT NULL CHECK (planned_duration_in_minutes BETWEEN 1 AND 240),
+  status VARCHAR(16) DEFAULT 'running' NOT NULL CHECK (status IN ('running', 'paused', 'finished')),
+  focused_seconds INT DEFAULT 0 NOT NULL CHECK (focused_seconds >= 0),
+  paused_for_prayer VARCHAR(16) NULL CHECK (paused_for_prayer IN ('subuh', 'zuhur', 'asar', 'magrib', 'isya')),
+  started_at TIMESTAMPTZ NOT NULL,
+  resumed_at TIMESTAMPTZ NULL,
+  ended_at TIMESTAMPTZ NULL,
+
+  CONSTRAINT chk_focus_session_resumed_at
+    CHECK ((status = 'running') = (resumed_at IS NOT NULL)),
+
+  CONSTRAINT chk_focus_session_ended_at
+    CHECK ((status = 'finished') = (ended_at IS NOT NULL)),
+
+  CONSTRAINT fk_focus_session_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_focus_session_task_id
+    FOREIGN KEY (task_id)
+    REFERENCES task(id)
+    ON UPDATE CASCADE
+    ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_focus_session_user_id_active ON focus_session (user_id) WHERE status <> 'finished';
+
+CREATE INDEX idx_focus_session_user_id_started_at ON focus_session (user_id, started_at);
+
+CREATE TABLE focus_preference (
+  user_id UUID PRIMARY KEY,
+  day_starts_at TIME NOT NULL,
+  day_ends_at TIME NOT NULL,
+  prayer_duration_in_minutes SMALLINT NOT NULL CHECK (prayer_duration_in_minutes BETWEEN 0 AND 120),
+  break_in_minutes SMALLINT NOT NULL CHECK (break_in_minutes BETWEEN 0 AND 120),
+  default_task_duration_in_minutes SMALLINT NOT NULL CHECK (default_task_duration_in_minutes BETWEEN 5 AND 480),
+
+  CONSTRAINT chk_focus_preference_day
+    CHECK (day_starts_at < day_ends_at),
+
+  CONSTRAINT fk_focus_preference_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE TABLE password_reset_token (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  token_hash CHAR(64) UNIQUE NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  CONSTRAINT fk_password_reset_token_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE INDEX idx_password_reset_token_user_id ON password_reset_token (user_id);
+
+CREATE TABLE user_identity (
+  provider VARCHAR(32) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  user_id UUID NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  PRIMARY KEY (provider, subject),
+
+  CONSTRAINT fk_user_identity_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_identity_user_id ON user_identity (user_id);
+
+CREATE TABLE user_totp (
+  user_id UUID PRIMARY KEY,
+  encrypted_secret TEXT NOT NULL,
+  confirmed_at TIMESTAMPTZ NULL,
+  last_used_step BIGINT DEFAULT 0 NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  CONSTRAINT fk_user_totp_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE TABLE recovery_code (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  code_hash CHAR(64) NOT NULL,
+  used_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  CONSTRAINT uq_recovery_code_user_id_code_hash
+    UNIQUE (user_id, code_hash),
+
+  CONSTRAINT fk_recovery_code_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE TABLE login_throttle (
+  scope VARCHAR(16) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  failed_attempts INT DEFAULT 0 NOT NULL,
+  last_failed_at TIMESTAMPTZ NOT NULL,
+  locked_until TIMESTAMPTZ NULL,
+
+  PRIMARY KEY (scope, subject)
+);
+
+CREATE TABLE personal_access_token (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  token_prefix VARCHAR(16) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  scopes TEXT[] NOT NULL,
+  expires_at TIMESTAMPTZ NULL,
+  last_used_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+  CONSTRAINT uq_personal_access_token_token_hash
+    UNIQUE (token_hash),
+
+  CONSTRAINT fk_personal_access_token_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES "user"(id)
+    ON UPDATE CASCADE
+    ON DELETE CASCADE
+);
+
+CREATE INDEX idx_personal_access_token_user_id ON personal_access_token (user_id);
\ No newline at end of file